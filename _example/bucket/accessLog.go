@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	ctx := context.Background()
+	// 读取 test/ 目录下的所有日志文件
+	err := c.Bucket.Walk(ctx, &cos.BucketGetOptions{Prefix: "test/"}, func(o *cos.Object, _ string) error {
+		resp, err := c.Object.Get(ctx, o.Key, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		s := cos.NewAccessLogScanner(resp.Body)
+		for s.Scan() {
+			r := s.Record()
+			fmt.Printf("%s %s %s %d\n", r.EventTime, r.EventName, r.ReqPath, r.ResHTTPCode)
+		}
+		return s.Err()
+	})
+	if err != nil {
+		panic(err)
+	}
+}
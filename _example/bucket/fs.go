@@ -0,0 +1,68 @@
+//go:build go1.16
+// +build go1.16
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   false,
+			},
+		},
+	})
+
+	fsys := c.Bucket.FS(context.Background())
+	// 列出存储桶中 test 目录下的所有文件
+	err := fs.WalkDir(fsys, "test", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			fmt.Printf("%s/\n", path)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%d\t%s\n", path, info.Size(), info.ModTime())
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// 也可以用于 http.FileServer(http.FS(sub))
+	sub, err := fs.Sub(fsys, "test")
+	if err != nil {
+		panic(err)
+	}
+	content, err := fs.ReadFile(sub, "hello.txt")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", content)
+}
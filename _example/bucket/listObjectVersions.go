@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	opt := &cos.BucketListObjectVersionsOptions{
+		Prefix:  "test/",
+		MaxKeys: 100,
+	}
+	v, _, err := c.Bucket.ListObjectVersions(context.Background(), opt)
+	if err != nil {
+		panic(err)
+	}
+	for _, o := range v.Versions {
+		fmt.Printf("%s, %s, %v\n", o.Key, o.VersionID, o.IsLatest)
+	}
+	for _, o := range v.DeleteMarkers {
+		fmt.Printf("delete marker: %s, %s\n", o.Key, o.VersionID)
+	}
+
+	// 删除指定的版本
+	for _, o := range v.DeleteMarkers {
+		_, err := c.Object.DeleteWithOpt(context.Background(), o.Key, &cos.ObjectDeleteOptions{
+			VersionID: o.VersionID,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	b, _ := cos.NewBaseURL(os.Getenv("COS_BUCKET_URL"))
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   false,
+			},
+		},
+	})
+
+	// 自动翻页列出所有 Object 和 Common Prefix
+	opt := &cos.BucketGetOptions{
+		Prefix:       "test/",
+		Delimiter:    "/",
+		EncodingType: "url",
+		MaxKeys:      3,
+	}
+	p := c.Bucket.ListObjects(context.Background(), opt)
+	for p.Next() {
+		if o := p.Object(); o != nil {
+			fmt.Printf("%s, %d\n", o.Key, o.Size)
+		} else {
+			fmt.Printf("%s\n", p.CommonPrefix())
+		}
+	}
+	if err := p.Err(); err != nil {
+		panic(err)
+	}
+
+	// 只列出前 5 个 Object
+	n := 0
+	err := c.Bucket.Walk(context.Background(), &cos.BucketGetOptions{Prefix: "test/"}, func(o *cos.Object, commonPrefix string) error {
+		fmt.Printf("%s, %d\n", o.Key, o.Size)
+		n++
+		if n >= 5 {
+			return cos.ErrStopWalk
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	opt := &cos.BucketPutEncryptionOptions{
+		Rules: []cos.BucketEncryptionRule{
+			{
+				ApplyServerSideEncryptionByDefault: cos.BucketApplyServerSideEncryptionByDefault{
+					SSEAlgorithm: cos.ServerSideEncryptionAES256,
+				},
+			},
+		},
+	}
+	_, err := c.Bucket.PutEncryption(context.Background(), opt)
+	if err != nil {
+		panic(err)
+	}
+}
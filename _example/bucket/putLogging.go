@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	// 将访问日志保存到同一地域的 logs-1253846586 存储桶的 test/ 目录下
+	opt := &cos.BucketPutLoggingOptions{
+		LoggingEnabled: &cos.BucketLoggingEnabled{
+			TargetBucket: "logs-1253846586",
+			TargetPrefix: "test/",
+		},
+	}
+	_, err := c.Bucket.PutLogging(context.Background(), opt)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	opt := &cos.BucketPutPolicyOptions{
+		Statement: []cos.BucketPolicyStatement{
+			{
+				Principal: &cos.BucketPolicyPrincipal{
+					QCS: cos.PolicyStringList{"qcs::cam::anyone:anyone"},
+				},
+				Effect:   cos.BucketPolicyEffectAllow,
+				Action:   cos.PolicyStringList{"name/cos:GetObject", "name/cos:HeadObject"},
+				Resource: cos.PolicyStringList{"qcs::cos:ap-beijing-1:uid/1253846586:test-1253846586/public/*"},
+			},
+		},
+	}
+	_, err := c.Bucket.PutPolicy(context.Background(), opt)
+	if err != nil {
+		panic(err)
+	}
+}
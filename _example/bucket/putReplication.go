@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	// 源存储桶和目标存储桶都需要先启用版本控制
+	opt := &cos.BucketPutReplicationOptions{
+		Role: "qcs::cam::uin/100000000001:uin/100000000001",
+		Rules: []cos.BucketReplicationRule{
+			{
+				ID:     "backup",
+				Status: "Enabled",
+				Prefix: "backup/",
+				Destination: &cos.BucketReplicationDestination{
+					Bucket:       "qcs::cos:ap-guangzhou::backup-1253846586",
+					StorageClass: cos.StorageClassStandardTA,
+				},
+			},
+		},
+	}
+	_, err := c.Bucket.PutReplication(context.Background(), opt)
+	if err != nil {
+		panic(err)
+	}
+}
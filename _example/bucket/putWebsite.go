@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	opt := &cos.BucketPutWebsiteOptions{
+		IndexDocument: &cos.BucketWebsiteIndexDocument{Suffix: "index.html"},
+		ErrorDocument: &cos.BucketWebsiteErrorDocument{Key: "error.html"},
+		RoutingRules: []cos.BucketWebsiteRoutingRule{
+			// 单页应用：找不到的路径都返回 index.html
+			{
+				Condition: cos.BucketWebsiteRoutingCondition{HTTPErrorCodeReturnedEquals: 404},
+				Redirect:  cos.BucketWebsiteRoutingRedirect{ReplaceKeyWith: "index.html"},
+			},
+		},
+	}
+	_, err := c.Bucket.PutWebsite(context.Background(), opt)
+	if err != nil {
+		panic(err)
+	}
+}
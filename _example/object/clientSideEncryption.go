@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/coscrypto"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	// 主密钥需要妥善保存，丢失后无法解密已上传的对象
+	provider, err := coscrypto.NewMasterKeyProvider([]byte(os.Getenv("COS_MASTER_KEY")), "example-master-key-v1")
+	if err != nil {
+		panic(err)
+	}
+	cc := coscrypto.NewClient(c, provider, &coscrypto.Options{
+		Algorithm: coscrypto.AlgorithmAESCTR,
+	})
+
+	name := "test/clientSideEncryption.txt"
+	data := strings.Repeat("hello client-side encryption\n", 10)
+	_, err = cc.Object.Put(context.Background(), name, strings.NewReader(data), nil)
+	if err != nil {
+		panic(err)
+	}
+
+	resp, err := cc.Object.Get(context.Background(), name, &cos.ObjectGetOptions{
+		Range: "bytes=6-27",
+	})
+	if err != nil {
+		panic(err)
+	}
+	bs, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("%s\n", bs)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mozillazg/go-cos/costest"
+)
+
+func main() {
+	// 在单元测试中可以使用 costest 包提供的内存版 COS 服务代替真实的 COS 服务
+	srv := costest.NewServer()
+	defer srv.Close()
+	srv.CreateBucket("test-1250000000")
+
+	c := srv.NewClient("test-1250000000")
+	ctx := context.Background()
+
+	_, err := c.Object.Put(ctx, "test/hello.txt", strings.NewReader("hello"), nil)
+	if err != nil {
+		panic(err)
+	}
+
+	resp, err := c.Object.Get(ctx, "test/hello.txt", nil)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	fmt.Printf("%s\n", b)
+
+	res, _, err := c.Bucket.Get(ctx, nil)
+	if err != nil {
+		panic(err)
+	}
+	for _, o := range res.Contents {
+		fmt.Printf("%s %d\n", o.Key, o.Size)
+	}
+}
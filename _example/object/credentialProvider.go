@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	// 依次尝试从环境变量、密钥文件（~/.cos/credentials）和 CVM 元数据服务获取密钥，
+	// 获取到的临时密钥会被缓存，并在过期前自动刷新
+	provider := &cos.CachedCredentialProvider{
+		Provider: cos.ChainCredentialProvider{
+			&cos.EnvCredentialProvider{},
+			&cos.FileCredentialProvider{},
+			&cos.CVMCredentialProvider{},
+		},
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			CredentialProvider: provider,
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	_, err := c.Bucket.Head(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	credentials, _ := provider.Credentials(context.Background())
+	fmt.Println(credentials.SecretID)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	b, _ := cos.NewBaseURL(os.Getenv("COS_BUCKET_URL"))
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   false,
+			},
+		},
+	})
+
+	name := "test/upload.go"
+	f, err := os.Create(os.Args[0] + ".download")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	opt := &cos.ObjectDownloadOptions{
+		PartSize:    1024 * 1024,
+		Concurrency: 5,
+		// 启用断点续传
+		CheckpointFile: f.Name() + ".cp",
+	}
+	_, err = c.Object.Download(context.Background(), name, f, opt)
+	if err != nil {
+		panic(err)
+	}
+}
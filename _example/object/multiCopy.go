@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	ctx := context.Background()
+	source := "test/multiCopy.bin"
+	data := bytes.Repeat([]byte("0123456789"), 300*1024)
+	_, err := c.Object.Put(ctx, source, bytes.NewReader(data), nil)
+	if err != nil {
+		panic(err)
+	}
+
+	sourceURL := fmt.Sprintf("%s/%s", u.Host, source)
+	opt := &cos.ObjectMultiCopyOptions{
+		// 每个分块 1 MB，源文件大于 1 MB 时使用分块复制
+		PartSize:    1024 * 1024,
+		Concurrency: 2,
+	}
+	res, _, err := c.Object.MultiCopy(ctx, "test/multiCopy_dest.bin", sourceURL, opt)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", res)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	b, _ := cos.NewBaseURL(os.Getenv("COS_BUCKET_URL"))
+	auth := cos.Auth{
+		SecretID:  os.Getenv("COS_SECRETID"),
+		SecretKey: os.Getenv("COS_SECRETKEY"),
+		Expire:    time.Hour,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  auth.SecretID,
+			SecretKey: auth.SecretKey,
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+	ctx := context.Background()
+
+	// 通过生成签名 header 的方式使用表单上传文件
+	opt := &cos.ObjectPostOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: "text/plain",
+		},
+	}
+	_, err := c.Object.PostObject(ctx, "test/postObject.txt", strings.NewReader("test"), opt)
+	if err != nil {
+		panic(err)
+	}
+
+	// 生成表单上传所需的策略和签名，可以将 policy.URL 和 policy.Fields 发送给浏览器等客户端用于上传文件
+	policy, err := c.Object.PostPolicy(ctx, auth, &cos.ObjectPostPolicyOptions{
+		KeyPrefix:         "test/uploads/",
+		MaxContentLength:  10 << 20,
+		ContentTypePrefix: "text/",
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("URL: %s\nPolicy: %s\n", policy.URL, policy.Policy)
+	for k, v := range policy.Fields {
+		fmt.Printf("%s: %s\n", k, v)
+	}
+
+	// 不使用签名 header，通过表单中的策略和签名上传
+	c2 := cos.NewClient(b, &http.Client{
+		Transport: &debug.DebugRequestTransport{
+			RequestHeader:  true,
+			RequestBody:    false,
+			ResponseHeader: true,
+			ResponseBody:   true,
+		},
+	})
+	opt = &cos.ObjectPostOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: "text/plain",
+		},
+		Fields: policy.Fields,
+	}
+	_, err = c2.Object.PostObject(ctx, "test/uploads/hello.txt", strings.NewReader("hello"), opt)
+	if err != nil {
+		panic(err)
+	}
+}
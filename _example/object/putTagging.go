@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	name := "test/hello.txt"
+	opt := &cos.ObjectPutTaggingOptions{
+		TagSet: []cos.BucketTaggingTag{
+			{Key: "env", Value: "test"},
+			{Key: "team", Value: "storage"},
+		},
+	}
+	_, err := c.Object.PutTagging(context.Background(), name, opt)
+	if err != nil {
+		panic(err)
+	}
+}
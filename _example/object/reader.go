@@ -0,0 +1,71 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   false,
+			},
+		},
+	})
+
+	// 上传一个 zip 文件
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		f, _ := zw.Create(name)
+		fmt.Fprintf(f, "hello %s\n", name)
+	}
+	zw.Close()
+	name := "test/reader.zip"
+	_, err := c.Object.Put(context.Background(), name, &buf, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// 只读取需要的部分，不需要下载整个 zip 文件
+	r, err := c.Object.NewReader(context.Background(), name, &cos.ObjectReaderOptions{
+		BlockSize: 64 * 1024,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer r.Close()
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			panic(err)
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		fmt.Printf("%s: %s", f.Name, b)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	ctx := context.Background()
+	name := "test/archived.txt"
+	opt := &cos.ObjectPostRestoreOptions{
+		Days:             1,
+		CASJobParameters: &cos.ObjectRestoreJobParameters{Tier: cos.RestoreTierExpedited},
+	}
+	_, err := c.Object.PostRestore(ctx, name, opt)
+	if err != nil {
+		panic(err)
+	}
+
+	// 最多等待 30 分钟
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+	resp, err := c.Object.WaitRestored(ctx, name, &cos.ObjectWaitRestoredOptions{
+		MinInterval: 10 * time.Second,
+		MaxInterval: time.Minute,
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("restored, expiry date: %s\n", resp.Restore().ExpiryDate)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	b, _ := cos.NewBaseURL(os.Getenv("COS_BUCKET_URL"))
+	c := cos.NewClient(b, nil)
+	c.Sender = &cos.DefaultSender{
+		Client: &http.Client{
+			Transport: &cos.AuthorizationTransport{
+				SecretID:  os.Getenv("COS_SECRETID"),
+				SecretKey: os.Getenv("COS_SECRETKEY"),
+				Transport: &debug.DebugRequestTransport{
+					RequestHeader:  true,
+					RequestBody:    true,
+					ResponseHeader: true,
+					ResponseBody:   true,
+				},
+			},
+		},
+		// 失败的请求最多重试 4 次
+		RetryPolicy: &cos.DefaultRetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   200 * time.Millisecond,
+		},
+	}
+
+	name := "test/retry.txt"
+	_, err := c.Object.Put(context.Background(), name, strings.NewReader("test"), nil)
+	if err != nil {
+		panic(err)
+	}
+}
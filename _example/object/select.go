@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	name := "test/data.csv"
+	opt := &cos.ObjectSelectOptions{
+		Expression:     "Select s._1, s._3 from COSObject s where cast(s._2 as int) > 30",
+		ExpressionType: "SQL",
+		InputSerialization: &cos.SelectInputSerialization{
+			CSV: &cos.SelectCSVInput{FileHeaderInfo: "NONE"},
+		},
+		OutputSerialization: &cos.SelectOutputSerialization{
+			CSV: &cos.SelectCSVOutput{},
+		},
+	}
+	stream, _, err := c.Object.Select(context.Background(), name, opt)
+	if err != nil {
+		panic(err)
+	}
+	defer stream.Close()
+
+	stats, err := stream.ReadRecords(os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+	if stats != nil {
+		fmt.Printf("scanned: %d, returned: %d\n", stats.BytesScanned, stats.BytesReturned)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    true,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	// 使用用户自定义的 32 字节密钥进行服务端加密（SSE-C），只能通过 HTTPS 使用
+	key := cos.NewSSECustomerHeaderOptions([]byte(os.Getenv("COS_SSE_CUSTOMER_KEY")))
+
+	name := "test/sseCustomer.txt"
+	opt := &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			SSECustomerHeaderOptions: key,
+		},
+	}
+	_, err := c.Object.Put(context.Background(), name, strings.NewReader("hello SSE-C"), opt)
+	if err != nil {
+		panic(err)
+	}
+
+	// 下载时需要指定相同的密钥
+	resp, err := c.Object.Get(context.Background(), name, &cos.ObjectGetOptions{
+		SSECustomerHeaderOptions: key,
+	})
+	if err != nil {
+		panic(err)
+	}
+	bs, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("%s\n", bs)
+
+	// 使用 KMS 托管的密钥进行服务端加密
+	sseContext, _ := cos.EncodeSSEContext(map[string]string{"project": "go-cos"})
+	opt = &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			XCosServerSideEncryption: cos.ServerSideEncryptionKMS,
+			XCosSSEKMSKeyID:          os.Getenv("COS_KMS_KEY_ID"),
+			XCosSSEContext:           sseContext,
+		},
+	}
+	resp, err = c.Object.Put(context.Background(), "test/sseKMS.txt", strings.NewReader("hello SSE-KMS"), opt)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.ServerSideEncryption(), resp.SSEKMSKeyID())
+}
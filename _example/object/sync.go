@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/cossync"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	dir, err := ioutil.TempDir("", "cossync")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "js"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("<h1>hello</h1>"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "js", "app.js"), []byte("console.log('hello')"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "js", "app.js.map"), []byte("{}"), 0644)
+
+	s := cossync.NewSyncer(c, &cossync.Options{
+		// 删除 test/sync/ 下本地目录中不存在的文件
+		Delete:  true,
+		Exclude: []string{"*.map"},
+		Output:  os.Stdout,
+	})
+
+	// dry-run：只输出将要执行的操作
+	plan, err := s.Plan(context.Background(), cossync.Upload, dir, "test/sync/")
+	if err != nil {
+		panic(err)
+	}
+	plan.WriteTo(os.Stdout)
+
+	if err := s.Execute(context.Background(), plan); err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", plan.Count())
+
+	// 将 test/sync/ 同步到另一个本地目录
+	if _, err := s.Sync(context.Background(), cossync.Download, filepath.Join(dir, "download"), "test/sync/"); err != nil {
+		panic(err)
+	}
+}
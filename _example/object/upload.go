@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	b, _ := cos.NewBaseURL(os.Getenv("COS_BUCKET_URL"))
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	name := "test/upload.go"
+	f, err := os.Open(os.Args[0])
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	opt := &cos.ObjectUploadOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: "application/octet-stream",
+		},
+		PartSize:    1024 * 1024,
+		Concurrency: 5,
+		// 启用断点续传
+		CheckpointFile: os.Args[0] + ".cp",
+	}
+	res, _, err := c.Object.Upload(context.Background(), name, f, opt)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", res)
+}
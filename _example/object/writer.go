@@ -0,0 +1,56 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"net/http"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+func main() {
+	u, _ := url.Parse(os.Getenv("COS_BUCKET_URL"))
+	b := &cos.BaseURL{
+		BucketURL: u,
+	}
+	c := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  os.Getenv("COS_SECRETID"),
+			SecretKey: os.Getenv("COS_SECRETKEY"),
+			Transport: &debug.DebugRequestTransport{
+				RequestHeader:  true,
+				RequestBody:    false,
+				ResponseHeader: true,
+				ResponseBody:   true,
+			},
+		},
+	})
+
+	// 边压缩边上传，不需要知道数据的大小
+	w := c.Object.NewWriter(context.Background(), "test/writer.txt.gz", &cos.ObjectUploadOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType:     "text/plain",
+			ContentEncoding: "gzip",
+		},
+		PartSize: 1024 * 1024,
+	})
+	zw := gzip.NewWriter(w)
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(zw, "%d: %s\n", i, strings.Repeat("hello ", 10))
+	}
+	if err := zw.Close(); err != nil {
+		w.CloseWithError(err)
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	res, _ := w.Result()
+	fmt.Printf("%+v\n", res)
+}
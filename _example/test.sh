@@ -21,11 +21,26 @@ run ./bucket/putACL.go
 run ./bucket/putCORS.go
 run ./bucket/putLifecycle.go
 run ./bucket/putTagging.go
+run ./bucket/putVersioning.go
+run ./bucket/putPolicy.go
+run ./bucket/putReplication.go
+run ./bucket/putWebsite.go
+run ./bucket/putLogging.go
+run ./bucket/putEncryption.go
 run ./bucket/get.go
+run ./bucket/fs.go
+run ./bucket/listObjects.go
 run ./bucket/getACL.go
 run ./bucket/getCORS.go
 run ./bucket/getLifecycle.go
 run ./bucket/getTagging.go
+run ./bucket/getVersioning.go
+run ./bucket/listObjectVersions.go
+run ./bucket/getPolicy.go
+run ./bucket/getReplication.go
+run ./bucket/getWebsite.go
+run ./bucket/getLogging.go
+run ./bucket/getEncryption.go
 run ./bucket/getLocation.go
 run ./bucket/head.go
 run ./bucket/listMultipartUploads.go
@@ -33,6 +48,10 @@ run ./bucket/delete.go
 run ./bucket/deleteCORS.go
 run ./bucket/deleteLifecycle.go
 run ./bucket/deleteTagging.go
+run ./bucket/deletePolicy.go
+run ./bucket/deleteReplication.go
+run ./bucket/deleteWebsite.go
+run ./bucket/deleteEncryption.go
 
 
 echo '##### object ####'
@@ -40,13 +59,21 @@ echo '##### object ####'
 run ./bucket/putCORS.go
 run ./object/put.go
 run ./object/uploadFile.go
+run ./object/upload.go
+run ./object/writer.go
 run ./object/putACL.go
 run ./object/append.go
 run ./object/get.go
+run ./object/reader.go
+run ./object/download.go
 run ./object/sessionToken.go
+run ./object/credentialProvider.go
 run ./object/head.go
 run ./object/getAnonymous.go
 run ./object/getACL.go
+run ./object/putTagging.go
+run ./object/getTagging.go
+run ./object/deleteTagging.go
 run ./object/listParts.go
 run ./object/options.go
 run ./object/initiateMultipartUpload.go
@@ -56,6 +83,15 @@ run ./object/abortMultipartUpload.go
 run ./object/delete.go
 run ./object/deleteMultiple.go
 run ./object/copy.go
+run ./object/multiCopy.go
+run ./object/postObject.go
+run ./object/clientSideEncryption.go
+run ./object/sync.go
+run ./object/sseCustomer.go
+run ./object/restore.go
+run ./object/select.go
 run ./object/getWithPresignedURL.go
 run ./object/putWithPresignedURL.go
 run ./object/mock.go
+run ./object/costest.go
+run ./object/retry.go
@@ -0,0 +1,243 @@
+package cos
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessLogRecord 存储桶访问日志中的一条记录，字段的顺序和含义见：
+//
+// https://cloud.tencent.com/document/product/436/16920
+//
+// 日志中为 - 的字段对应的值为零值。
+type AccessLogRecord struct {
+	// 日志记录的版本号
+	EventVersion string
+	// 存储桶名称
+	BucketName string
+	// 请求所在地域，比如：ap-beijing
+	Region string
+	// 请求时间（UTC）
+	EventTime time.Time
+	// 请求的域名
+	EventSource string
+	// 请求的操作名，比如：UploadPart，GetObject
+	EventName string
+	// 请求来源 IP
+	RemoteIP string
+	// 请求者使用的 SecretID
+	UserSecretKeyID string
+	// 请求的字节数
+	ReqBytesSent int64
+	// 请求导致的存储量变化，单位是 Byte
+	DeltaDataSize int64
+	// 请求的对象路径
+	ReqPath string
+	// 请求方法
+	ReqMethod string
+	// 请求的 User-Agent
+	UserAgent string
+	// 响应的 HTTP 状态码
+	ResHTTPCode int
+	// 错误码，请求成功时为空
+	ResErrorCode string
+	// 错误信息，请求成功时为空
+	ResErrorMsg string
+	// 响应的字节数
+	ResBytesSent int64
+	// 请求的总耗时，单位是毫秒
+	ResTotalTime int64
+	// 日志来源类型，比如：USER（用户请求），CDN（CDN 回源请求）
+	LogSourceType string
+	// 对象的存储级别
+	StorageClass string
+	// 存储桶所属的账号 ID
+	AccountID string
+	// 服务端处理请求的耗时，单位是毫秒
+	ResTurnAroundTime int64
+	// 请求者的账号 ID
+	Requester string
+	// 请求 ID
+	RequestID string
+	// 对象大小，单位是 Byte
+	ObjectSize int64
+	// 对象的版本 ID
+	VersionID string
+	// 复制或者转换存储级别时的目标存储级别
+	TargetStorageClass string
+	// 请求的 Referer
+	Referer string
+	// 请求的 URI，包含查询参数
+	RequestURI string
+}
+
+// accessLogMinFields 一条日志记录至少要包含的字段数（到 ResHTTPCode 为止）
+const accessLogMinFields = 15
+
+// fields 按照日志中的顺序返回各字段对应的指针，nil 表示保留字段
+func (r *AccessLogRecord) fields() []interface{} {
+	return []interface{}{
+		&r.EventVersion, &r.BucketName, &r.Region, &r.EventTime, &r.EventSource,
+		&r.EventName, &r.RemoteIP, &r.UserSecretKeyID, nil, &r.ReqBytesSent,
+		&r.DeltaDataSize, &r.ReqPath, &r.ReqMethod, &r.UserAgent, &r.ResHTTPCode,
+		&r.ResErrorCode, &r.ResErrorMsg, &r.ResBytesSent, &r.ResTotalTime, &r.LogSourceType,
+		&r.StorageClass, &r.AccountID, &r.ResTurnAroundTime, &r.Requester, &r.RequestID,
+		&r.ObjectSize, &r.VersionID, &r.TargetStorageClass, &r.Referer, &r.RequestURI,
+	}
+}
+
+// ParseAccessLogLine 解析存储桶访问日志中的一行。
+//
+// 字段之间以制表符分隔（旧格式的日志使用空格），相邻的两个分隔符表示空字段，
+// 包含分隔符的字段（比如 UserAgent）使用双引号括起来。
+// 比已知字段多出来的字段会被忽略，以兼容日志格式以后新增的字段。
+func ParseAccessLogLine(line string) (*AccessLogRecord, error) {
+	values, err := splitAccessLogLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) < accessLogMinFields {
+		return nil, fmt.Errorf("cos: access log line has %d fields, want at least %d", len(values), accessLogMinFields)
+	}
+
+	var r AccessLogRecord
+	for i, field := range r.fields() {
+		if i >= len(values) {
+			break
+		}
+		v := values[i]
+		if field == nil || v == "-" || v == "" {
+			continue
+		}
+		switch p := field.(type) {
+		case *string:
+			*p = v
+		case *int64:
+			*p, err = strconv.ParseInt(v, 10, 64)
+		case *int:
+			*p, err = strconv.Atoi(v)
+		case *time.Time:
+			*p, err = parseAccessLogTime(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cos: invalid access log field %d %q: %v", i+1, v, err)
+		}
+	}
+	return &r, nil
+}
+
+// 访问日志中 eventTime 使用的 ISO 8601 基本格式（UTC 时间），比如：20190605T105520Z
+const accessLogTimeLayout = "20060102T150405Z"
+
+// parseAccessLogTime 支持 ISO 8601 基本格式（20060102T150405Z）、RFC 3339 格式以及 Unix 时间戳（秒）
+func parseAccessLogTime(v string) (time.Time, error) {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	if t, err := time.Parse(accessLogTimeLayout, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// splitAccessLogLine 按照分隔符切分日志中的一行：包含制表符时使用制表符分隔，否则使用空格分隔。
+// 每个分隔符都会分出一个字段，相邻的分隔符之间是空字段
+func splitAccessLogLine(line string) ([]string, error) {
+	line = strings.TrimRight(line, "\r\n")
+	sep := "\t"
+	if !strings.Contains(line, sep) {
+		sep = " "
+	}
+	var values []string
+	for {
+		var v string
+		if strings.HasPrefix(line, `"`) {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("cos: unterminated quoted field in access log line")
+			}
+			v, line = line[1:end+1], line[end+2:]
+			if line != "" && !strings.HasPrefix(line, sep) {
+				return nil, fmt.Errorf("cos: missing separator after quoted field in access log line")
+			}
+		} else if i := strings.Index(line, sep); i >= 0 {
+			v, line = line[:i], line[i:]
+		} else {
+			v, line = line, ""
+		}
+		values = append(values, v)
+		if line == "" {
+			return values, nil
+		}
+		line = line[len(sep):]
+	}
+}
+
+// AccessLogScanner 逐条读取存储桶访问日志文件中的记录：
+//
+//	resp, err := c.Object.Get(ctx, "logs/2019/08/18/10/...", nil)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer resp.Body.Close()
+//	s := cos.NewAccessLogScanner(resp.Body)
+//	for s.Scan() {
+//		r := s.Record()
+//		fmt.Println(r.EventTime, r.EventName, r.ReqPath, r.ResHTTPCode)
+//	}
+//	if err := s.Err(); err != nil {
+//		panic(err)
+//	}
+//
+// 空行会被跳过，遇到无法解析的行时停止读取，Err 返回的错误包含出错的行号。
+type AccessLogScanner struct {
+	s      *bufio.Scanner
+	line   int
+	record *AccessLogRecord
+	err    error
+}
+
+// NewAccessLogScanner 创建从 r 中读取访问日志的 AccessLogScanner
+func NewAccessLogScanner(r io.Reader) *AccessLogScanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024)
+	return &AccessLogScanner{s: s}
+}
+
+// Scan 读取下一条记录，没有更多记录或者出错时返回 false
+func (s *AccessLogScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.s.Scan() {
+		s.line++
+		line := s.s.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s.record, s.err = ParseAccessLogLine(line)
+		if s.err != nil {
+			s.record = nil
+			s.err = fmt.Errorf("line %d: %v", s.line, s.err)
+			return false
+		}
+		return true
+	}
+	s.record = nil
+	s.err = s.s.Err()
+	return false
+}
+
+// Record 返回 Scan 读取到的记录
+func (s *AccessLogScanner) Record() *AccessLogRecord {
+	return s.record
+}
+
+// Err 返回读取过程中遇到的错误
+func (s *AccessLogScanner) Err() error {
+	return s.err
+}
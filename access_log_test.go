@@ -0,0 +1,111 @@
+package cos
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAccessLogLine = `1.0	examplebucket-1250000000	ap-beijing	2019-08-18T07:21:51Z	examplebucket-1250000000.cos.ap-beijing.myqcloud.com	` +
+	`GetObject	10.1.1.1	AKIDexample	-	0	0	/test/hello.txt	GET	"Go-http-client/1.1 (linux)"	206	-	-	5	12	USER	` +
+	`STANDARD	100000000001	3	100000000011	NWQ1OGZkMTdfNjQ=	11	-	-	http://www.example.com	/test/hello.txt?versionId=1`
+
+func TestParseAccessLogLine(t *testing.T) {
+	r, err := ParseAccessLogLine(testAccessLogLine)
+	if err != nil {
+		t.Fatalf("ParseAccessLogLine returned error: %v", err)
+	}
+	want := &AccessLogRecord{
+		EventVersion:      "1.0",
+		BucketName:        "examplebucket-1250000000",
+		Region:            "ap-beijing",
+		EventTime:         time.Date(2019, 8, 18, 7, 21, 51, 0, time.UTC),
+		EventSource:       "examplebucket-1250000000.cos.ap-beijing.myqcloud.com",
+		EventName:         "GetObject",
+		RemoteIP:          "10.1.1.1",
+		UserSecretKeyID:   "AKIDexample",
+		ReqPath:           "/test/hello.txt",
+		ReqMethod:         "GET",
+		UserAgent:         "Go-http-client/1.1 (linux)",
+		ResHTTPCode:       206,
+		ResBytesSent:      5,
+		ResTotalTime:      12,
+		LogSourceType:     "USER",
+		StorageClass:      StorageClassStandard,
+		AccountID:         "100000000001",
+		ResTurnAroundTime: 3,
+		Requester:         "100000000011",
+		RequestID:         "NWQ1OGZkMTdfNjQ=",
+		ObjectSize:        11,
+		Referer:           "http://www.example.com",
+		RequestURI:        "/test/hello.txt?versionId=1",
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("ParseAccessLogLine returned %+v, want %+v", r, want)
+	}
+}
+
+func TestParseAccessLogLine_error(t *testing.T) {
+	lines := []string{
+		"1.0 examplebucket-1250000000 ap-beijing",
+		strings.Replace(testAccessLogLine, "\t206\t", "\tOK\t", 1),
+		strings.Replace(testAccessLogLine, "2019-08-18T07:21:51Z", "yesterday", 1),
+		strings.Replace(testAccessLogLine, `"Go-http-client/1.1 (linux)"`, `"Go-http-client/1.1`, 1),
+	}
+	for _, line := range lines {
+		if _, err := ParseAccessLogLine(line); err == nil {
+			t.Errorf("ParseAccessLogLine(%q) should return error", line)
+		}
+	}
+}
+
+func TestParseAccessLogLine_emptyField(t *testing.T) {
+	// UserSecretKeyID 和 UserAgent 为空，后面的字段不能前移
+	line := strings.Replace(testAccessLogLine, "\tAKIDexample\t", "\t\t", 1)
+	line = strings.Replace(line, `"Go-http-client/1.1 (linux)"`, "", 1)
+	r, err := ParseAccessLogLine(line)
+	if err != nil {
+		t.Fatalf("ParseAccessLogLine returned error: %v", err)
+	}
+	if r.UserSecretKeyID != "" || r.UserAgent != "" || r.ReqBytesSent != 0 || r.ResHTTPCode != 206 || r.RequestURI != "/test/hello.txt?versionId=1" {
+		t.Errorf("ParseAccessLogLine returned %+v", r)
+	}
+}
+
+func TestAccessLogScanner(t *testing.T) {
+	// 空格分隔、Unix 时间戳以及字段较少的旧格式
+	old := "1.0 examplebucket-1250000000 ap-beijing 1566112911 - PutObject 10.1.1.2 - - 11 11 /a.txt PUT - 200"
+	s := NewAccessLogScanner(strings.NewReader(testAccessLogLine + "\n\n" + old + "\nbad line\n" + old))
+
+	var names []string
+	for s.Scan() {
+		names = append(names, s.Record().EventName)
+	}
+	if want := []string{"GetObject", "PutObject"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("AccessLogScanner returned %v, want %v", names, want)
+	}
+	if err := s.Err(); err == nil || !strings.HasPrefix(err.Error(), "line 4:") {
+		t.Errorf("AccessLogScanner.Err returned %v", err)
+	}
+	if s.Record() != nil || s.Scan() {
+		t.Error("AccessLogScanner should stop after error")
+	}
+}
+
+func TestParseAccessLogLine_eventTime(t *testing.T) {
+	// 访问日志中 eventTime 的格式为 ISO 8601 基本格式
+	line := "1.0\texamplebucket-1250000000\tap-beijing\t20190605T105520Z\texamplebucket-1250000000.cos.ap-beijing.myqcloud.com\t" +
+		"PutObject\t10.1.1.3\tAKIDexample\t-\t1024\t-\t/exampleobject.txt\tPUT\t\"cos-go-sdk-v5.2.9\"\t200\t-\t-\t0\t35\tUSER\t" +
+		"STANDARD\t100000000001\t10\t100000000001\tNWNmNzc4MzhfYjAwNWU0MF8xNTQ5XzE=\t1024\t-\t-\t-\t/exampleobject.txt"
+	r, err := ParseAccessLogLine(line)
+	if err != nil {
+		t.Fatalf("ParseAccessLogLine returned error: %v", err)
+	}
+	if want := time.Date(2019, 6, 5, 10, 55, 20, 0, time.UTC); !r.EventTime.Equal(want) || r.EventTime.Location() != time.UTC {
+		t.Errorf("ParseAccessLogLine returned EventTime %v, want %v", r.EventTime, want)
+	}
+	if r.EventName != "PutObject" || r.ReqBytesSent != 1024 || r.ObjectSize != 1024 {
+		t.Errorf("ParseAccessLogLine returned %+v", r)
+	}
+}
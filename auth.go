@@ -274,6 +274,9 @@ type AuthorizationTransport struct {
 	SessionToken string
 	// 签名多久过期，默认是 time.Hour
 	Expire time.Duration
+	// 用于获取密钥的 CredentialProvider，不为 nil 时会忽略 SecretID、SecretKey 和 SessionToken，
+	// 每次发送请求前都会调用 CredentialProvider.Credentials 获取密钥
+	CredentialProvider CredentialProvider
 
 	// 实际发送 http 请求的 http.RoundTripper，默认使用 http.DefaultTransport
 	Transport http.RoundTripper
@@ -283,13 +286,20 @@ type AuthorizationTransport struct {
 func (t *AuthorizationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// 使用预签名授权 URL 时跳过添加 Authorization header 的步骤
 	if req.URL.Query().Get("sign") == "" {
+		c, err := t.credentials(req)
+		if err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
 		req = cloneRequest(req) // per RoundTrip contract
 
 		// 增加 Authorization header
 		authTime := NewAuthTime(t.Expire)
-		AddAuthorizationHeader(t.SecretID, t.SecretKey, req, authTime)
-		if t.SessionToken != "" {
-			req.Header.Set("x-cos-security-token", t.SessionToken)
+		AddAuthorizationHeader(c.SecretID, c.SecretKey, req, authTime)
+		if c.SessionToken != "" {
+			req.Header.Set("x-cos-security-token", c.SessionToken)
 		}
 	}
 
@@ -297,6 +307,17 @@ func (t *AuthorizationTransport) RoundTrip(req *http.Request) (*http.Response, e
 	return resp, err
 }
 
+func (t *AuthorizationTransport) credentials(req *http.Request) (*Credentials, error) {
+	if t.CredentialProvider == nil {
+		return &Credentials{
+			SecretID:     t.SecretID,
+			SecretKey:    t.SecretKey,
+			SessionToken: t.SessionToken,
+		}, nil
+	}
+	return t.CredentialProvider.Credentials(req.Context())
+}
+
 func (t *AuthorizationTransport) transport() http.RoundTripper {
 	if t.Transport != nil {
 		return t.Transport
@@ -0,0 +1,103 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+// BucketApplyServerSideEncryptionByDefault ...
+//
+// 存储桶的默认服务端加密方式，上传对象时没有指定服务端加密方式时使用该配置加密
+type BucketApplyServerSideEncryptionByDefault struct {
+	// 服务端加密算法，枚举值：AES256（ServerSideEncryptionAES256），cos/kms（ServerSideEncryptionKMS）
+	SSEAlgorithm string `xml:"SSEAlgorithm"`
+	// SSEAlgorithm 为 cos/kms 时使用的 KMS 主密钥 ID，为空时使用 COS 默认的 KMS 主密钥
+	KMSMasterKeyID string `xml:"KMSMasterKeyID,omitempty"`
+}
+
+// BucketEncryptionRule ...
+type BucketEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault BucketApplyServerSideEncryptionByDefault `xml:"ApplyServerSideEncryptionByDefault"`
+}
+
+// BucketPutEncryptionOptions ...
+//
+// https://cloud.tencent.com/document/product/436/40136
+type BucketPutEncryptionOptions struct {
+	XMLName xml.Name `xml:"ServerSideEncryptionConfiguration"`
+	// 默认加密规则，目前只支持一条规则
+	Rules []BucketEncryptionRule `xml:"Rule"`
+}
+
+// BucketGetEncryptionResult ...
+//
+// https://cloud.tencent.com/document/product/436/40137
+type BucketGetEncryptionResult BucketPutEncryptionOptions
+
+// MethodBucketPutEncryption method name of Bucket.PutEncryption
+const MethodBucketPutEncryption MethodName = "Bucket.PutEncryption"
+
+// PutEncryption ...
+//
+// Put Bucket encryption 接口用于设置存储桶的默认加密配置，
+// 设置后上传到存储桶中的对象在没有指定服务端加密方式时会使用该配置进行服务端加密。
+//
+// https://cloud.tencent.com/document/product/436/40136
+func (s *BucketService) PutEncryption(ctx context.Context, opt *BucketPutEncryptionOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?encryption",
+		method:  http.MethodPut,
+		body:    opt,
+		caller: Caller{
+			Method: MethodBucketPutEncryption,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// MethodBucketGetEncryption method name of Bucket.GetEncryption
+const MethodBucketGetEncryption MethodName = "Bucket.GetEncryption"
+
+// GetEncryption ...
+//
+// Get Bucket encryption 接口用于查询存储桶的默认加密配置，存储桶没有默认加密配置时返回 404（NoSuchEncryptionConfiguration）。
+//
+// https://cloud.tencent.com/document/product/436/40137
+func (s *BucketService) GetEncryption(ctx context.Context) (*BucketGetEncryptionResult, *Response, error) {
+	var res BucketGetEncryptionResult
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?encryption",
+		method:  http.MethodGet,
+		result:  &res,
+		caller: Caller{
+			Method: MethodBucketGetEncryption,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
+
+// MethodBucketDeleteEncryption method name of Bucket.DeleteEncryption
+const MethodBucketDeleteEncryption MethodName = "Bucket.DeleteEncryption"
+
+// DeleteEncryption ...
+//
+// Delete Bucket encryption 接口用于删除存储桶的默认加密配置，不会影响已经上传的对象。
+//
+// https://cloud.tencent.com/document/product/436/40138
+func (s *BucketService) DeleteEncryption(ctx context.Context) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?encryption",
+		method:  http.MethodDelete,
+		caller: Caller{
+			Method: MethodBucketDeleteEncryption,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
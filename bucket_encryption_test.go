@@ -0,0 +1,108 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBucketService_GetEncryption(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"encryption": "",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<ServerSideEncryptionConfiguration>
+	<Rule>
+		<ApplyServerSideEncryptionByDefault>
+			<SSEAlgorithm>cos/kms</SSEAlgorithm>
+			<KMSMasterKeyID>kms-key</KMSMasterKeyID>
+		</ApplyServerSideEncryptionByDefault>
+	</Rule>
+</ServerSideEncryptionConfiguration>`)
+	})
+
+	ref, _, err := client.Bucket.GetEncryption(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetEncryption returned error: %v", err)
+	}
+
+	want := &BucketGetEncryptionResult{
+		XMLName: xml.Name{Local: "ServerSideEncryptionConfiguration"},
+		Rules: []BucketEncryptionRule{
+			{
+				ApplyServerSideEncryptionByDefault: BucketApplyServerSideEncryptionByDefault{
+					SSEAlgorithm:   ServerSideEncryptionKMS,
+					KMSMasterKeyID: "kms-key",
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.GetEncryption returned %+v, want %+v", ref, want)
+	}
+}
+
+func TestBucketService_PutEncryption(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketPutEncryptionOptions{
+		Rules: []BucketEncryptionRule{
+			{
+				ApplyServerSideEncryptionByDefault: BucketApplyServerSideEncryptionByDefault{
+					SSEAlgorithm: ServerSideEncryptionAES256,
+				},
+			},
+		},
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		v := new(BucketPutEncryptionOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"encryption": "",
+		}
+		testFormValues(t, r, vs)
+
+		want := opt
+		want.XMLName = xml.Name{Local: "ServerSideEncryptionConfiguration"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Bucket.PutEncryption request body: %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Bucket.PutEncryption(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.PutEncryption returned error: %v", err)
+	}
+}
+
+func TestBucketService_DeleteEncryption(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		vs := values{
+			"encryption": "",
+		}
+		testFormValues(t, r, vs)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Bucket.DeleteEncryption(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.DeleteEncryption returned error: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+//go:build go1.16
+// +build go1.16
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BucketFS 通过 Bucket.FS 创建的只读文件系统，实现了 fs.FS、fs.ReadDirFS、fs.StatFS 和 fs.SubFS，
+// 可以用于 http.FileServer(http.FS(fsys))、template.ParseFS 和 fs.WalkDir 等。
+//
+// Object 的 Key 按照 "/" 划分为目录和文件：目录通过 Bucket.ListObjects（Delimiter 为 "/"）列出，
+// 文件通过 Object.NewReader 读取，支持 io.Seeker 和 io.ReaderAt。
+// 以 "/" 结尾的 Object（比如控制台创建的目录）会被当做目录，不会出现在目录的内容中。
+type BucketFS struct {
+	client *Client
+	ctx    context.Context
+	// Sub 之后的 Key 前缀，不以 "/" 结尾，为空表示存储桶的根目录
+	prefix string
+}
+
+// FS 返回一个访问存储桶中的 Object 的只读文件系统，ctx 用于之后所有的请求
+func (s *BucketService) FS(ctx context.Context) *BucketFS {
+	return &BucketFS{client: s.client, ctx: ctx}
+}
+
+// key 返回 name 对应的 Object Key
+func (fsys *BucketFS) key(name string) string {
+	if name == "." {
+		return fsys.prefix
+	}
+	if fsys.prefix == "" {
+		return name
+	}
+	return fsys.prefix + "/" + name
+}
+
+// Open implements the fs.FS interface.
+func (fsys *BucketFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name != "." {
+		r, err := fsys.client.Object.NewReader(fsys.ctx, fsys.key(name), nil)
+		if err == nil {
+			return &bucketFile{ObjectReader: r, info: newObjectFileInfo(name, r.Response(), r.Size())}, nil
+		}
+		if !isNotFound(err) {
+			return nil, fsError("open", name, err)
+		}
+	}
+	entries, err := fsys.readDir(name)
+	if err != nil {
+		return nil, fsError("open", name, err)
+	}
+	return &bucketDir{info: dirInfo(name), entries: entries}, nil
+}
+
+// Stat implements the fs.StatFS interface.
+func (fsys *BucketFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if name != "." {
+		resp, err := fsys.client.Object.Head(fsys.ctx, fsys.key(name), nil)
+		if err == nil {
+			size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+			return newObjectFileInfo(name, resp, size), nil
+		}
+		if !isNotFound(err) {
+			return nil, fsError("stat", name, err)
+		}
+	}
+	if _, err := fsys.readDir(name); err != nil {
+		return nil, fsError("stat", name, err)
+	}
+	return dirInfo(name), nil
+}
+
+// ReadDir implements the fs.ReadDirFS interface.
+func (fsys *BucketFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, err := fsys.readDir(name)
+	if err != nil {
+		return nil, fsError("readdir", name, err)
+	}
+	return entries, nil
+}
+
+// Sub implements the fs.SubFS interface.
+func (fsys *BucketFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	if dir == "." {
+		return fsys, nil
+	}
+	return &BucketFS{client: fsys.client, ctx: fsys.ctx, prefix: fsys.key(dir)}, nil
+}
+
+// readDir 列出目录 name 中的文件和子目录，按照名称排序。
+// 除根目录外，没有任何以 name + "/" 开头的 Object 时返回 fs.ErrNotExist
+func (fsys *BucketFS) readDir(name string) ([]fs.DirEntry, error) {
+	prefix := fsys.key(name)
+	if prefix != "" {
+		prefix += "/"
+	}
+	var entries []fs.DirEntry
+	exists := name == "."
+	pager := fsys.client.Bucket.ListObjects(fsys.ctx, &BucketGetOptions{
+		Prefix:    prefix,
+		Delimiter: "/",
+	})
+	for pager.Next() {
+		exists = true
+		if o := pager.Object(); o != nil {
+			// 跳过目录本身
+			if n := strings.TrimPrefix(o.Key, prefix); n != "" && fs.ValidPath(n) {
+				entries = append(entries, fs.FileInfoToDirEntry(listedFileInfo(n, *o)))
+			}
+		} else if n := strings.TrimSuffix(strings.TrimPrefix(pager.CommonPrefix(), prefix), "/"); fs.ValidPath(n) {
+			entries = append(entries, fs.FileInfoToDirEntry(dirInfo(n)))
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fs.ErrNotExist
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// isNotFound err 是否是 404 错误
+func isNotFound(err error) bool {
+	return errorStatusCode(err) == http.StatusNotFound
+}
+
+// fsError 将 COS 返回的错误转换为 fs.PathError，404 错误转换为 fs.ErrNotExist
+func fsError(op, name string, err error) error {
+	if isNotFound(err) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// objectFileInfo 实现了 fs.FileInfo，Sys 返回 Object.Head 的响应或者列出目录时的 Object
+type objectFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+	sys     interface{}
+}
+
+func newObjectFileInfo(name string, resp *Response, size int64) *objectFileInfo {
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &objectFileInfo{name: path.Base(name), size: size, modTime: modTime, mode: 0444, sys: resp}
+}
+
+func listedFileInfo(name string, o Object) *objectFileInfo {
+	modTime, _ := time.Parse(time.RFC3339, o.LastModified)
+	return &objectFileInfo{name: name, size: int64(o.Size), modTime: modTime, mode: 0444, sys: o}
+}
+
+func dirInfo(name string) *objectFileInfo {
+	return &objectFileInfo{name: path.Base(name), mode: fs.ModeDir | 0555}
+}
+
+func (fi *objectFileInfo) Name() string       { return fi.name }
+func (fi *objectFileInfo) Size() int64        { return fi.size }
+func (fi *objectFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *objectFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *objectFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *objectFileInfo) Sys() interface{}   { return fi.sys }
+
+// bucketFile 文件，通过 ObjectReader 读取
+type bucketFile struct {
+	*ObjectReader
+	info fs.FileInfo
+}
+
+func (f *bucketFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// bucketDir 目录，实现了 fs.ReadDirFile
+type bucketDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+}
+
+func (d *bucketDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *bucketDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (d *bucketDir) Close() error {
+	return nil
+}
+
+func (d *bucketDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,144 @@
+//go:build go1.16
+// +build go1.16
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// newFSServer 返回包含 objects 的 fakeServer，每次 Get Bucket 最多返回 2 个条目，用于测试分页
+func newFSServer(t *testing.T, objects map[string]string) *fakeServer {
+	s := newFakeServer(t)
+	s.maxKeys = 2
+	for k, v := range objects {
+		s.putObject(k, []byte(v), nil)
+	}
+	return s
+}
+
+func TestBucketFS(t *testing.T) {
+	setup()
+	defer teardown()
+
+	newFSServer(t, map[string]string{
+		"index.html":        "<h1>index</h1>",
+		"a.txt":             "hello",
+		"dir/":              "",
+		"dir/b.txt":         "world",
+		"dir/c.txt":         "!",
+		"dir/sub/d.txt":     "d",
+		"dir/sub/e/f.txt":   "f",
+		"other/x/y/z.txt":   "z",
+		"other/x/y/z2.txt":  "z2",
+		"other/x/y/z3.txt":  "z3",
+		"other/x/y2/z4.txt": "z4",
+	})
+
+	fsys := client.Bucket.FS(context.Background())
+	if err := fstest.TestFS(fsys, "index.html", "a.txt", "dir/b.txt", "dir/sub/e/f.txt", "other/x/y2/z4.txt"); err != nil {
+		t.Error(err)
+	}
+
+	sub, err := fs.Sub(fsys, "dir")
+	if err != nil {
+		t.Fatalf("fs.Sub returned error: %v", err)
+	}
+	if err := fstest.TestFS(sub, "b.txt", "c.txt", "sub/d.txt", "sub/e/f.txt"); err != nil {
+		t.Error(err)
+	}
+	b, err := fs.ReadFile(sub, "sub/d.txt")
+	if err != nil || string(b) != "d" {
+		t.Errorf("fs.ReadFile returned %q, %v", b, err)
+	}
+
+	info, err := fs.Stat(fsys, "a.txt")
+	if err != nil {
+		t.Fatalf("fs.Stat returned error: %v", err)
+	}
+	if info.Size() != 5 || info.IsDir() || !info.ModTime().Equal(fakeModTime) {
+		t.Errorf("fs.Stat returned size %d, mod time %v", info.Size(), info.ModTime())
+	}
+}
+
+func TestBucketFS_ReadDir_prefixOnlyPage(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// 第一页只有 Common Prefix 并且没有 NextMarker
+	s := newFSServer(t, map[string]string{
+		"d/a/1.txt": "1",
+		"d/b/2.txt": "2",
+		"d/c/3.txt": "3",
+		"d/x.txt":   "x",
+	})
+	s.noNextMarker = true
+
+	entries, err := fs.ReadDir(client.Bucket.FS(context.Background()), "d")
+	if err != nil {
+		t.Fatalf("fs.ReadDir returned error: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"a", "b", "c", "x.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("fs.ReadDir returned %v, want %v", names, want)
+	}
+	if n := s.count("GetBucket"); n != 2 {
+		t.Errorf("fs.ReadDir requested %d pages, want 2", n)
+	}
+}
+
+func TestBucketFS_notExist(t *testing.T) {
+	setup()
+	defer teardown()
+
+	newFSServer(t, map[string]string{"dir/a.txt": "a"})
+	fsys := client.Bucket.FS(context.Background())
+
+	for _, name := range []string{"a.txt", "dir/b.txt", "di"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Open(%s) returned error %v, want %v", name, err, fs.ErrNotExist)
+		}
+		if _, err := fsys.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) returned error %v, want %v", name, err, fs.ErrNotExist)
+		}
+	}
+	if _, err := fsys.ReadDir("dir/a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadDir returned error %v, want %v", err, fs.ErrNotExist)
+	}
+	for _, name := range []string{"/dir", "dir/", "../a.txt"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open(%s) returned error %v, want %v", name, err, fs.ErrInvalid)
+		}
+	}
+}
+
+func TestBucketFS_httpFileServer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	newFSServer(t, map[string]string{"static/hello.txt": "hello world"})
+	sub, _ := client.Bucket.FS(context.Background()).Sub("static")
+
+	f, err := http.FS(sub).Open("/hello.txt")
+	if err != nil {
+		t.Fatalf("http.FS.Open returned error: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("Seek returned error: %v", err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil || string(b) != "world" {
+		t.Errorf("Read returned %q, %v", b, err)
+	}
+}
@@ -9,8 +9,11 @@ import (
 // BucketLifecycleFilter ...
 type BucketLifecycleFilter struct {
 	// 指定规则所适用的前缀。匹配前缀的对象受该规则影响，Prefix 最多只能有一个
-	Prefix string                 `xml:"Prefix,omitempty"`
-	And    *BucketLifecycleFilter `xml:"And,omitempty"`
+	Prefix string `xml:"Prefix,omitempty"`
+	// 指定规则所适用的对象标签，匹配标签的对象受该规则影响。
+	// 不在 And 中时最多只能有一个，同时按照前缀和标签或者按照多个标签过滤时需要放在 And 中
+	Tag []BucketTaggingTag     `xml:"Tag,omitempty"`
+	And *BucketLifecycleFilter `xml:"And,omitempty"`
 }
 
 // BucketLifecycleExpiration ...
@@ -86,6 +86,17 @@ func TestBucketService_PutLifecycle(t *testing.T) {
 				Status:     "Disabled",
 				Expiration: &BucketLifecycleExpiration{Days: 10},
 			},
+			{
+				ID: "tagged",
+				Filter: &BucketLifecycleFilter{
+					And: &BucketLifecycleFilter{
+						Prefix: "logs/",
+						Tag:    []BucketTaggingTag{{Key: "type", Value: "tmp"}, {Key: "env", Value: "test"}},
+					},
+				},
+				Status:     "Enabled",
+				Expiration: &BucketLifecycleExpiration{Days: 1},
+			},
 		},
 	}
 
@@ -0,0 +1,189 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrStopWalk 在 WalkFunc 中返回 ErrStopWalk 表示提前结束遍历，此时 Bucket.Walk 返回 nil
+var ErrStopWalk = errors.New("cos: stop walk")
+
+// WalkFunc Bucket.Walk 遍历每个条目时调用的函数。
+//
+// 条目为 Object 时 object 不为 nil，条目为 Common Prefix（使用了 Delimiter）时 object 为 nil，commonPrefix 不为空。
+// 返回非 nil 的 error 时结束遍历。
+type WalkFunc func(object *Object, commonPrefix string) error
+
+// ObjectPager 分页列出 Bucket 中的 Object 的迭代器，通过 Bucket.ListObjects 创建：
+//
+//	p := c.Bucket.ListObjects(ctx, &cos.BucketGetOptions{Prefix: "test/", Delimiter: "/"})
+//	for p.Next() {
+//		if o := p.Object(); o != nil {
+//			fmt.Println(o.Key)
+//		} else {
+//			fmt.Println(p.CommonPrefix())
+//		}
+//	}
+//	if err := p.Err(); err != nil {
+//		panic(err)
+//	}
+//
+// 按照 Key 的字典序依次返回 Object 和 Common Prefix。
+// 当前页的条目遍历完后会自动使用上一页的 NextMarker（没有返回 NextMarker 时使用上一页的最后一个条目）请求下一页。
+// EncodingType 为 url 时返回的 Key 和 Common Prefix 都是解码后的值。
+type ObjectPager struct {
+	s   *BucketService
+	ctx context.Context
+	opt BucketGetOptions
+
+	objects  []Object
+	prefixes []string
+	// 是否还有下一页
+	more bool
+	err  error
+	resp *Response
+
+	object *Object
+	prefix string
+}
+
+// ListObjects 返回分页列出 Bucket 中的 Object 的迭代器，opt.MaxKeys 为每一页的条目数量。
+//
+// 需要调用 ObjectPager.Next 方法后才会发送请求。
+func (s *BucketService) ListObjects(ctx context.Context, opt *BucketGetOptions) *ObjectPager {
+	p := &ObjectPager{
+		s:    s,
+		ctx:  ctx,
+		more: true,
+	}
+	if opt != nil {
+		p.opt = *opt
+	}
+	return p
+}
+
+// Next 移动到下一个条目，没有更多的条目或者出错时返回 false
+func (p *ObjectPager) Next() bool {
+	p.object, p.prefix = nil, ""
+	if p.err != nil {
+		return false
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+	for len(p.objects) == 0 && len(p.prefixes) == 0 {
+		if !p.more {
+			return false
+		}
+		if err := p.fetch(); err != nil {
+			p.err = err
+			return false
+		}
+	}
+
+	// 合并两个有序的列表
+	if len(p.prefixes) == 0 || len(p.objects) > 0 && p.objects[0].Key < p.prefixes[0] {
+		p.object = &p.objects[0]
+		p.objects = p.objects[1:]
+	} else {
+		p.prefix = p.prefixes[0]
+		p.prefixes = p.prefixes[1:]
+	}
+	return true
+}
+
+// Object 返回当前的 Object，当前条目是 Common Prefix 时返回 nil
+func (p *ObjectPager) Object() *Object {
+	return p.object
+}
+
+// CommonPrefix 返回当前的 Common Prefix，当前条目是 Object 时返回空字符串
+func (p *ObjectPager) CommonPrefix() string {
+	return p.prefix
+}
+
+// Err 返回遍历过程中遇到的错误
+func (p *ObjectPager) Err() error {
+	return p.err
+}
+
+// Response 返回最后一次请求 Bucket.Get 的响应
+func (p *ObjectPager) Response() *Response {
+	return p.resp
+}
+
+// fetch 请求下一页并更新下一页的 Marker
+func (p *ObjectPager) fetch() error {
+	res, resp, err := p.s.Get(p.ctx, &p.opt)
+	p.resp = resp
+	if err != nil {
+		return err
+	}
+	if err := decodeBucketGetResult(res); err != nil {
+		return err
+	}
+	p.objects = res.Contents
+	p.prefixes = res.CommonPrefixes
+	p.more = res.IsTruncated
+	if !p.more {
+		return nil
+	}
+
+	marker := res.NextMarker
+	// 指定了 Delimiter 时 NextMarker 可能为空，此时使用本页的最后一个条目作为下一页的 Marker
+	if marker == "" {
+		if n := len(res.Contents); n > 0 {
+			marker = res.Contents[n-1].Key
+		}
+		if n := len(res.CommonPrefixes); n > 0 && res.CommonPrefixes[n-1] > marker {
+			marker = res.CommonPrefixes[n-1]
+		}
+	}
+	if marker == "" || marker <= p.opt.Marker {
+		return fmt.Errorf("cos: truncated list result of bucket without next marker (marker: %q)", p.opt.Marker)
+	}
+	p.opt.Marker = marker
+	return nil
+}
+
+// decodeBucketGetResult 解码 EncodingType 为 url 时返回的 Key、Common Prefix 和 NextMarker
+func decodeBucketGetResult(res *BucketGetResult) error {
+	if res.EncodingType != "url" {
+		return nil
+	}
+	var err error
+	decode := func(s string) string {
+		v, e := url.QueryUnescape(s)
+		if e != nil && err == nil {
+			err = fmt.Errorf("cos: invalid url encoded key %q: %v", s, e)
+		}
+		return v
+	}
+	res.NextMarker = decode(res.NextMarker)
+	for i := range res.Contents {
+		res.Contents[i].Key = decode(res.Contents[i].Key)
+	}
+	for i := range res.CommonPrefixes {
+		res.CommonPrefixes[i] = decode(res.CommonPrefixes[i])
+	}
+	return err
+}
+
+// Walk 按照字典序遍历 Bucket 中的 Object 和 Common Prefix，对每个条目调用 fn 。
+//
+// fn 返回 ErrStopWalk 时提前结束遍历并返回 nil，返回其他 error 时结束遍历并返回该 error。
+func (s *BucketService) Walk(ctx context.Context, opt *BucketGetOptions, fn WalkFunc) error {
+	p := s.ListObjects(ctx, opt)
+	for p.Next() {
+		if err := fn(p.Object(), p.CommonPrefix()); err != nil {
+			if err == ErrStopWalk {
+				return nil
+			}
+			return err
+		}
+	}
+	return p.Err()
+}
@@ -0,0 +1,139 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// listPages 按照 marker 返回预设的 Bucket.Get 结果
+func listPages(t *testing.T, pages map[string]string) *[]string {
+	var markers []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		marker := r.URL.Query().Get("marker")
+		markers = append(markers, marker)
+		page, ok := pages[marker]
+		if !ok {
+			t.Fatalf("unexpected marker %q", marker)
+		}
+		fmt.Fprintf(w, `<ListBucketResult>%s</ListBucketResult>`, page)
+	})
+	return &markers
+}
+
+func TestBucketService_ListObjects(t *testing.T) {
+	setup()
+	defer teardown()
+
+	markers := listPages(t, map[string]string{
+		// 指定了 Delimiter 时没有返回 NextMarker
+		"": `<IsTruncated>true</IsTruncated>
+<Contents><Key>a.txt</Key></Contents>
+<Contents><Key>c.txt</Key></Contents>
+<CommonPrefixes><Prefix>b/</Prefix></CommonPrefixes>
+<CommonPrefixes><Prefix>d/</Prefix></CommonPrefixes>`,
+		"d/": `<IsTruncated>true</IsTruncated>
+<NextMarker>e.txt</NextMarker>
+<Contents><Key>e.txt</Key></Contents>`,
+		"e.txt": `<IsTruncated>false</IsTruncated>
+<CommonPrefixes><Prefix>f/</Prefix></CommonPrefixes>`,
+	})
+
+	var got []string
+	p := client.Bucket.ListObjects(context.Background(), &BucketGetOptions{Delimiter: "/", MaxKeys: 4})
+	for p.Next() {
+		if o := p.Object(); o != nil {
+			got = append(got, o.Key)
+		} else {
+			got = append(got, "prefix:"+p.CommonPrefix())
+		}
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("ObjectPager returned error: %v", err)
+	}
+	want := []string{"a.txt", "prefix:b/", "c.txt", "prefix:d/", "e.txt", "prefix:f/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ObjectPager returned %v, want %v", got, want)
+	}
+	if want := []string{"", "d/", "e.txt"}; !reflect.DeepEqual(*markers, want) {
+		t.Errorf("ObjectPager requested markers %q, want %q", *markers, want)
+	}
+}
+
+func TestBucketService_ListObjects_encodingType(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listPages(t, map[string]string{
+		"": `<Encoding-Type>url</Encoding-Type><IsTruncated>true</IsTruncated>
+<NextMarker>a%2Bb+c.txt</NextMarker>
+<Contents><Key>a%2Bb+c.txt</Key></Contents>`,
+		"a+b c.txt": `<Encoding-Type>url</Encoding-Type><IsTruncated>false</IsTruncated>
+<Contents><Key>%E4%BD%A0%E5%A5%BD.txt</Key></Contents>`,
+	})
+
+	var got []string
+	err := client.Bucket.Walk(context.Background(), &BucketGetOptions{EncodingType: "url"}, func(o *Object, prefix string) error {
+		got = append(got, o.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Bucket.Walk returned error: %v", err)
+	}
+	if want := []string{"a+b c.txt", "你好.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Bucket.Walk returned %q, want %q", got, want)
+	}
+}
+
+func TestBucketService_Walk_stop(t *testing.T) {
+	setup()
+	defer teardown()
+
+	markers := listPages(t, map[string]string{
+		"": `<IsTruncated>true</IsTruncated><NextMarker>b</NextMarker>
+<Contents><Key>a</Key></Contents><Contents><Key>b</Key></Contents>`,
+	})
+
+	var got []string
+	err := client.Bucket.Walk(context.Background(), nil, func(o *Object, prefix string) error {
+		got = append(got, o.Key)
+		return ErrStopWalk
+	})
+	if err != nil || !reflect.DeepEqual(got, []string{"a"}) || len(*markers) != 1 {
+		t.Errorf("Bucket.Walk returned %v and visited %v", err, got)
+	}
+
+	e := fmt.Errorf("walk error")
+	err = client.Bucket.Walk(context.Background(), nil, func(o *Object, prefix string) error {
+		return e
+	})
+	if err != e {
+		t.Errorf("Bucket.Walk returned error %v, want %v", err, e)
+	}
+}
+
+func TestBucketService_ListObjects_contextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listPages(t, map[string]string{
+		"": `<IsTruncated>true</IsTruncated><NextMarker>b</NextMarker>
+<Contents><Key>a</Key></Contents><Contents><Key>b</Key></Contents>`,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := client.Bucket.ListObjects(ctx, nil)
+	if !p.Next() {
+		t.Fatalf("ObjectPager.Next returned false: %v", p.Err())
+	}
+	cancel()
+	if p.Next() {
+		t.Error("ObjectPager.Next should return false after context is canceled")
+	}
+	if p.Err() != context.Canceled {
+		t.Errorf("ObjectPager.Err returned %v, want %v", p.Err(), context.Canceled)
+	}
+}
@@ -0,0 +1,84 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+// BucketLoggingEnabled ...
+type BucketLoggingEnabled struct {
+	// 存放日志的目标存储桶，需要和源存储桶在同一个地域，可以是源存储桶本身
+	TargetBucket string `xml:"TargetBucket"`
+	// 日志文件的对象键前缀，比如：logs/
+	TargetPrefix string `xml:"TargetPrefix,omitempty"`
+}
+
+// BucketPutLoggingOptions ...
+//
+// https://cloud.tencent.com/document/product/436/17054
+type BucketPutLoggingOptions struct {
+	XMLName xml.Name `xml:"BucketLoggingStatus"`
+	// 日志存储配置，为 nil 时表示关闭日志存储
+	LoggingEnabled *BucketLoggingEnabled `xml:"LoggingEnabled,omitempty"`
+}
+
+// BucketGetLoggingResult ...
+//
+// https://cloud.tencent.com/document/product/436/17053
+type BucketGetLoggingResult struct {
+	XMLName xml.Name `xml:"BucketLoggingStatus"`
+	// 日志存储配置，没有开启日志存储时为 nil
+	LoggingEnabled *BucketLoggingEnabled `xml:"LoggingEnabled,omitempty"`
+}
+
+// MethodBucketPutLogging method name of Bucket.PutLogging
+const MethodBucketPutLogging MethodName = "Bucket.PutLogging"
+
+// PutLogging ...
+//
+// Put Bucket Logging 接口用于为源存储桶开启日志记录，将源存储桶的访问日志保存到指定的目标存储桶中。
+// opt.LoggingEnabled 为 nil 时关闭日志记录。
+//
+// 日志文件的格式见 AccessLogScanner 。
+//
+// https://cloud.tencent.com/document/product/436/17054
+func (s *BucketService) PutLogging(ctx context.Context, opt *BucketPutLoggingOptions) (*Response, error) {
+	if opt == nil {
+		opt = &BucketPutLoggingOptions{}
+	}
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?logging",
+		method:  http.MethodPut,
+		body:    opt,
+		caller: Caller{
+			Method: MethodBucketPutLogging,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// MethodBucketGetLogging method name of Bucket.GetLogging
+const MethodBucketGetLogging MethodName = "Bucket.GetLogging"
+
+// GetLogging ...
+//
+// Get Bucket Logging 接口用于查询指定存储桶的日志配置信息。
+//
+// https://cloud.tencent.com/document/product/436/17053
+func (s *BucketService) GetLogging(ctx context.Context) (*BucketGetLoggingResult, *Response, error) {
+	var res BucketGetLoggingResult
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?logging",
+		method:  http.MethodGet,
+		result:  &res,
+		caller: Caller{
+			Method: MethodBucketGetLogging,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
@@ -0,0 +1,98 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBucketService_PutLogging(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketPutLoggingOptions{
+		LoggingEnabled: &BucketLoggingEnabled{
+			TargetBucket: "logs-1250000000",
+			TargetPrefix: "test/",
+		},
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		v := new(BucketPutLoggingOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"logging": "",
+		}
+		testFormValues(t, r, vs)
+
+		want := opt
+		want.XMLName = xml.Name{Local: "BucketLoggingStatus"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Bucket.PutLogging request body: %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Bucket.PutLogging(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.PutLogging returned error: %v", err)
+	}
+}
+
+func TestBucketService_PutLogging_disable(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `<BucketLoggingStatus></BucketLoggingStatus>`; string(b) != want {
+			t.Errorf("Bucket.PutLogging request body: %s, want %s", b, want)
+		}
+	})
+
+	_, err := client.Bucket.PutLogging(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Bucket.PutLogging returned error: %v", err)
+	}
+}
+
+func TestBucketService_GetLogging(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"logging": "",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<BucketLoggingStatus>
+	<LoggingEnabled>
+		<TargetBucket>logs-1250000000</TargetBucket>
+		<TargetPrefix>test/</TargetPrefix>
+	</LoggingEnabled>
+</BucketLoggingStatus>`)
+	})
+
+	ref, _, err := client.Bucket.GetLogging(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetLogging returned error: %v", err)
+	}
+
+	want := &BucketGetLoggingResult{
+		XMLName: xml.Name{Local: "BucketLoggingStatus"},
+		LoggingEnabled: &BucketLoggingEnabled{
+			TargetBucket: "logs-1250000000",
+			TargetPrefix: "test/",
+		},
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.GetLogging returned %+v, want %+v", ref, want)
+	}
+}
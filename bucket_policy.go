@@ -0,0 +1,194 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// BucketPolicyVersion 存储桶策略语法的版本
+	BucketPolicyVersion string = "2.0"
+
+	// BucketPolicyEffectAllow 策略效力: 允许
+	BucketPolicyEffectAllow string = "allow"
+	// BucketPolicyEffectDeny 策略效力: 显式拒绝
+	BucketPolicyEffectDeny string = "deny"
+)
+
+// PolicyStringList 策略中可以是单个字符串也可以是字符串数组的元素，比如 Action 和 Resource。
+//
+// 反序列化时同时支持这两种格式，序列化时总是使用字符串数组的格式。
+type PolicyStringList []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *PolicyStringList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = PolicyStringList{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = PolicyStringList(list)
+	return nil
+}
+
+// PolicyValueList 策略中的条件值，可以是单个值也可以是数组，元素可以是字符串、数字或者布尔值
+// （比如 numeric_less_than、bool_equal 条件操作符）。
+//
+// 反序列化时数字会被解析为 json.Number，序列化时总是使用数组的格式，并保持元素原来的类型，
+// 因此 GetPolicy 获取的策略可以原样通过 PutPolicy 设置。
+type PolicyValueList []interface{}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *PolicyValueList) UnmarshalJSON(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		list = []interface{}{v}
+	}
+	for _, e := range list {
+		switch e.(type) {
+		case string, json.Number, bool:
+		default:
+			return fmt.Errorf("cos: invalid policy condition value %s, want string, number or boolean", data)
+		}
+	}
+	*l = PolicyValueList(list)
+	return nil
+}
+
+// BucketPolicyPrincipal 策略授权的主体
+type BucketPolicyPrincipal struct {
+	// 主体列表，比如：qcs::cam::uin/100000000001:uin/100000000011 表示子账号，
+	// qcs::cam::anyone:anyone 表示所有用户（包括匿名用户）
+	QCS PolicyStringList `json:"qcs"`
+}
+
+// BucketPolicyCondition 策略生效的条件，格式为：条件操作符 -> 条件键 -> 条件值，比如：
+//
+//	cos.BucketPolicyCondition{
+//		"ip_equal":          {"qcs:ip": {"10.121.2.10/24"}},
+//		"numeric_less_than": {"cos:x-cos-max-keys": {100}},
+//	}
+type BucketPolicyCondition map[string]map[string]PolicyValueList
+
+// BucketPolicyStatement 策略中的一条语句
+type BucketPolicyStatement struct {
+	// 语句的 ID，可选
+	Sid string `json:"Sid,omitempty"`
+	// 授权的主体
+	Principal *BucketPolicyPrincipal `json:"Principal,omitempty"`
+	// 效力，枚举值：allow，deny
+	Effect string `json:"Effect"`
+	// 允许或拒绝的操作，比如：name/cos:GetObject，name/cos:* 表示所有操作
+	Action PolicyStringList `json:"Action"`
+	// 授权的资源，格式为 qcs::cos:<Region>:uid/<APPID>:<BucketName-APPID>/<ObjectKey>，
+	// 比如：qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*
+	Resource PolicyStringList `json:"Resource"`
+	// 策略生效的条件，可选
+	Condition BucketPolicyCondition `json:"Condition,omitempty"`
+}
+
+// BucketPolicy 存储桶策略
+//
+// https://cloud.tencent.com/document/product/436/12469
+type BucketPolicy struct {
+	// 策略语法的版本，默认为 2.0
+	Version string `json:"version"`
+	// 策略语句列表
+	Statement []BucketPolicyStatement `json:"Statement"`
+}
+
+// BucketPutPolicyOptions ...
+type BucketPutPolicyOptions BucketPolicy
+
+// BucketGetPolicyResult ...
+type BucketGetPolicyResult BucketPolicy
+
+// MethodBucketPutPolicy method name of Bucket.PutPolicy
+const MethodBucketPutPolicy MethodName = "Bucket.PutPolicy"
+
+// PutPolicy ...
+//
+// Put Bucket Policy 请求可以向存储桶写入权限策略，当存储桶已存在权限策略时，该请求上传的策略将覆盖原有的权限策略。
+// opt.Version 为空时使用 BucketPolicyVersion 。
+//
+// https://cloud.tencent.com/document/product/436/8282
+func (s *BucketService) PutPolicy(ctx context.Context, opt *BucketPutPolicyOptions) (*Response, error) {
+	if opt != nil && opt.Version == "" {
+		o := *opt
+		o.Version = BucketPolicyVersion
+		opt = &o
+	}
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/?policy",
+		method:   http.MethodPut,
+		body:     opt,
+		jsonBody: true,
+		caller: Caller{
+			Method: MethodBucketPutPolicy,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// MethodBucketGetPolicy method name of Bucket.GetPolicy
+const MethodBucketGetPolicy MethodName = "Bucket.GetPolicy"
+
+// GetPolicy ...
+//
+// Get Bucket Policy 请求可以向存储桶读取权限策略。存储桶没有设置权限策略时返回 404 NoSuchPolicy 错误。
+//
+// https://cloud.tencent.com/document/product/436/8276
+func (s *BucketService) GetPolicy(ctx context.Context) (*BucketGetPolicyResult, *Response, error) {
+	var res BucketGetPolicyResult
+	var body bytes.Buffer
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?policy",
+		method:  http.MethodGet,
+		result:  &body,
+		caller: Caller{
+			Method: MethodBucketGetPolicy,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	if err == nil && body.Len() > 0 {
+		err = json.Unmarshal(body.Bytes(), &res)
+	}
+	return &res, resp, err
+}
+
+// MethodBucketDeletePolicy method name of Bucket.DeletePolicy
+const MethodBucketDeletePolicy MethodName = "Bucket.DeletePolicy"
+
+// DeletePolicy ...
+//
+// Delete Bucket Policy 请求可以删除存储桶的权限策略。
+//
+// https://cloud.tencent.com/document/product/436/8285
+func (s *BucketService) DeletePolicy(ctx context.Context) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?policy",
+		method:  http.MethodDelete,
+		caller: Caller{
+			Method: MethodBucketDeletePolicy,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
@@ -0,0 +1,202 @@
+package cos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBucketService_PutPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketPutPolicyOptions{
+		Statement: []BucketPolicyStatement{
+			{
+				Principal: &BucketPolicyPrincipal{
+					QCS: PolicyStringList{"qcs::cam::uin/100000000001:uin/100000000011"},
+				},
+				Effect:   BucketPolicyEffectAllow,
+				Action:   PolicyStringList{"name/cos:GetObject"},
+				Resource: PolicyStringList{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"},
+				Condition: BucketPolicyCondition{
+					"ip_equal": {"qcs:ip": {"10.121.2.10/24"}},
+				},
+			},
+		},
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"policy": "",
+		}
+		testFormValues(t, r, vs)
+		testHeader(t, r, "Content-Type", "application/json")
+		if r.Header.Get("Content-MD5") == "" {
+			t.Error("Bucket.PutPolicy request should have Content-MD5 header")
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		want := `{"version":"2.0","Statement":[{"Principal":{"qcs":["qcs::cam::uin/100000000001:uin/100000000011"]},` +
+			`"Effect":"allow","Action":["name/cos:GetObject"],` +
+			`"Resource":["qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"],` +
+			`"Condition":{"ip_equal":{"qcs:ip":["10.121.2.10/24"]}}}]}`
+		if string(b) != want {
+			t.Errorf("Bucket.PutPolicy request body: %s, want %s", b, want)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Bucket.PutPolicy(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.PutPolicy returned error: %v", err)
+	}
+	if opt.Version != "" {
+		t.Errorf("Bucket.PutPolicy should not modify opt")
+	}
+}
+
+func TestBucketService_GetPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"policy": "",
+		}
+		testFormValues(t, r, vs)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+	"Statement": [
+		{
+			"Principal": {"qcs": "qcs::cam::anyone:anyone"},
+			"Effect": "deny",
+			"Action": "name/cos:*",
+			"Resource": ["qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/private/*"]
+		}
+	],
+	"version": "2.0"
+}`)
+	})
+
+	ref, _, err := client.Bucket.GetPolicy(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetPolicy returned error: %v", err)
+	}
+
+	want := &BucketGetPolicyResult{
+		Version: "2.0",
+		Statement: []BucketPolicyStatement{
+			{
+				Principal: &BucketPolicyPrincipal{QCS: PolicyStringList{"qcs::cam::anyone:anyone"}},
+				Effect:    BucketPolicyEffectDeny,
+				Action:    PolicyStringList{"name/cos:*"},
+				Resource:  PolicyStringList{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/private/*"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.GetPolicy returned %+v, want %+v", ref, want)
+	}
+}
+
+func TestBucketService_DeletePolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		vs := values{
+			"policy": "",
+		}
+		testFormValues(t, r, vs)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Bucket.DeletePolicy(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.DeletePolicy returned error: %v", err)
+	}
+}
+
+func TestPolicyStringList_UnmarshalJSON(t *testing.T) {
+	var v struct {
+		A PolicyStringList
+		B PolicyStringList
+	}
+	err := json.Unmarshal([]byte(`{"A": "a", "B": ["b", "c"]}`), &v)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if !reflect.DeepEqual(v.A, PolicyStringList{"a"}) || !reflect.DeepEqual(v.B, PolicyStringList{"b", "c"}) {
+		t.Errorf("json.Unmarshal returned %+v", v)
+	}
+	if err := json.Unmarshal([]byte(`{"A": 1}`), &v); err == nil {
+		t.Error("json.Unmarshal should return error")
+	}
+}
+
+func TestBucketPolicyCondition_UnmarshalJSON(t *testing.T) {
+	var c BucketPolicyCondition
+	err := json.Unmarshal([]byte(`{
+		"numeric_less_than": {"cos:x-cos-max-keys": 10},
+		"numeric_equal": {"qcs:x": [1.5, 2e3]},
+		"bool_equal": {"qcs:secure_transport": true},
+		"string_equal": {"qcs:ip": "10.0.0.1"}
+	}`), &c)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	want := BucketPolicyCondition{
+		"numeric_less_than": {"cos:x-cos-max-keys": {json.Number("10")}},
+		"numeric_equal":     {"qcs:x": {json.Number("1.5"), json.Number("2e3")}},
+		"bool_equal":        {"qcs:secure_transport": {true}},
+		"string_equal":      {"qcs:ip": {"10.0.0.1"}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("json.Unmarshal returned %+v, want %+v", c, want)
+	}
+
+	for _, data := range []string{`{"a": {"b": {}}}`, `{"a": {"b": [null]}}`, `{"a": {"b": [["c"]]}}`} {
+		if err := json.Unmarshal([]byte(data), &c); err == nil {
+			t.Errorf("json.Unmarshal(%s) should return error", data)
+		}
+	}
+}
+
+func TestBucketService_GetPolicy_roundTrip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	policy := `{"version":"2.0","Statement":[{"Effect":"allow","Action":["name/cos:GetBucket"],` +
+		`"Resource":["qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"],` +
+		`"Condition":{"bool_equal":{"qcs:secure_transport":[true]},` +
+		`"numeric_less_than_equal":{"cos:x-cos-max-keys":[100,1.5,2e3]},"string_equal":{"qcs:ip":["10.0.0.1"]}}}]}`
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, policy)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != policy {
+			t.Errorf("Bucket.PutPolicy request body: %s, want %s", b, policy)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	res, _, err := client.Bucket.GetPolicy(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetPolicy returned error: %v", err)
+	}
+	opt := BucketPutPolicyOptions(*res)
+	if _, err := client.Bucket.PutPolicy(context.Background(), &opt); err != nil {
+		t.Fatalf("Bucket.PutPolicy returned error: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+const (
+	// ReplicationStatusPending 对象的跨地域复制状态: 等待复制
+	ReplicationStatusPending string = "PENDING"
+	// ReplicationStatusCompleted 对象的跨地域复制状态: 复制成功
+	ReplicationStatusCompleted string = "COMPLETED"
+	// ReplicationStatusFailed 对象的跨地域复制状态: 复制失败
+	ReplicationStatusFailed string = "FAILED"
+	// ReplicationStatusReplica 对象的跨地域复制状态: 该对象是复制生成的副本
+	ReplicationStatusReplica string = "REPLICA"
+)
+
+// BucketReplicationDestination ...
+type BucketReplicationDestination struct {
+	// 目标存储桶的资源标识，格式为 qcs::cos:<Region>::<BucketName-APPID>，
+	// 比如：qcs::cos:ap-beijing::destinationbucket-1250000000
+	Bucket string `xml:"Bucket"`
+	// 副本的存储级别，枚举值：STANDARD，STANDARD_IA。为空时使用源对象的存储级别
+	StorageClass string `xml:"StorageClass,omitempty"`
+}
+
+// BucketReplicationRule ...
+//
+// https://cloud.tencent.com/document/product/436/19223
+type BucketReplicationRule struct {
+	// 用于唯一地标识规则，长度不能超过 255 个字符
+	ID string `xml:"ID,omitempty"`
+	// 指明规则是否启用，枚举值：Enabled，Disabled
+	Status string `xml:"Status"`
+	// 指定规则所适用的前缀，为空时表示复制存储桶中的所有对象。多条规则的前缀不能重叠
+	Prefix string `xml:"Prefix"`
+	// 复制的目标存储桶
+	Destination *BucketReplicationDestination `xml:"Destination"`
+}
+
+// BucketPutReplicationOptions ...
+//
+// https://cloud.tencent.com/document/product/436/19223
+type BucketPutReplicationOptions struct {
+	XMLName xml.Name `xml:"ReplicationConfiguration"`
+	// 发起复制的身份，格式为 qcs::cam::uin/<OwnerUin>:uin/<SubUin>
+	Role  string                  `xml:"Role"`
+	Rules []BucketReplicationRule `xml:"Rule,omitempty"`
+}
+
+// BucketGetReplicationResult ...
+//
+// https://cloud.tencent.com/document/product/436/19222
+type BucketGetReplicationResult struct {
+	XMLName xml.Name                `xml:"ReplicationConfiguration"`
+	Role    string                  `xml:"Role"`
+	Rules   []BucketReplicationRule `xml:"Rule,omitempty"`
+}
+
+// MethodBucketPutReplication method name of Bucket.PutReplication
+const MethodBucketPutReplication MethodName = "Bucket.PutReplication"
+
+// PutReplication ...
+//
+// Put Bucket Replication 请求用于向开启了版本控制的存储桶中配置跨地域复制规则，
+// 如果存储桶已经配置了跨地域复制规则，那么该请求会替换现有配置。
+//
+// 细节分析
+//
+// * 源存储桶和目标存储桶都必须已经启用了版本控制，见 PutVersioning；
+// * 配置生效后新上传的对象才会被复制，已有的对象不会被复制。
+//
+// https://cloud.tencent.com/document/product/436/19223
+func (s *BucketService) PutReplication(ctx context.Context, opt *BucketPutReplicationOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?replication",
+		method:  http.MethodPut,
+		body:    opt,
+		caller: Caller{
+			Method: MethodBucketPutReplication,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// MethodBucketGetReplication method name of Bucket.GetReplication
+const MethodBucketGetReplication MethodName = "Bucket.GetReplication"
+
+// GetReplication ...
+//
+// Get Bucket Replication 接口请求实现读取存储桶中用户跨地域复制配置信息。
+//
+// https://cloud.tencent.com/document/product/436/19222
+func (s *BucketService) GetReplication(ctx context.Context) (*BucketGetReplicationResult, *Response, error) {
+	var res BucketGetReplicationResult
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?replication",
+		method:  http.MethodGet,
+		result:  &res,
+		caller: Caller{
+			Method: MethodBucketGetReplication,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
+
+// MethodBucketDeleteReplication method name of Bucket.DeleteReplication
+const MethodBucketDeleteReplication MethodName = "Bucket.DeleteReplication"
+
+// DeleteReplication ...
+//
+// Delete Bucket Replication 接口请求实现删除存储桶中用户跨地域复制配置。
+//
+// https://cloud.tencent.com/document/product/436/19221
+func (s *BucketService) DeleteReplication(ctx context.Context) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?replication",
+		method:  http.MethodDelete,
+		caller: Caller{
+			Method: MethodBucketDeleteReplication,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
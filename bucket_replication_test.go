@@ -0,0 +1,117 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBucketService_PutReplication(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketPutReplicationOptions{
+		Role: "qcs::cam::uin/100000000001:uin/100000000001",
+		Rules: []BucketReplicationRule{
+			{
+				ID:     "rule1",
+				Status: "Enabled",
+				Prefix: "backup/",
+				Destination: &BucketReplicationDestination{
+					Bucket:       "qcs::cos:ap-beijing::destinationbucket-1250000000",
+					StorageClass: StorageClassStandardTA,
+				},
+			},
+		},
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		v := new(BucketPutReplicationOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"replication": "",
+		}
+		testFormValues(t, r, vs)
+
+		want := opt
+		want.XMLName = xml.Name{Local: "ReplicationConfiguration"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Bucket.PutReplication request body: %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Bucket.PutReplication(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.PutReplication returned error: %v", err)
+	}
+}
+
+func TestBucketService_GetReplication(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"replication": "",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<ReplicationConfiguration>
+	<Role>qcs::cam::uin/100000000001:uin/100000000001</Role>
+	<Rule>
+		<ID>rule1</ID>
+		<Status>Enabled</Status>
+		<Prefix></Prefix>
+		<Destination>
+			<Bucket>qcs::cos:ap-beijing::destinationbucket-1250000000</Bucket>
+		</Destination>
+	</Rule>
+</ReplicationConfiguration>`)
+	})
+
+	ref, _, err := client.Bucket.GetReplication(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetReplication returned error: %v", err)
+	}
+
+	want := &BucketGetReplicationResult{
+		XMLName: xml.Name{Local: "ReplicationConfiguration"},
+		Role:    "qcs::cam::uin/100000000001:uin/100000000001",
+		Rules: []BucketReplicationRule{
+			{
+				ID:     "rule1",
+				Status: "Enabled",
+				Destination: &BucketReplicationDestination{
+					Bucket: "qcs::cos:ap-beijing::destinationbucket-1250000000",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.GetReplication returned %+v, want %+v", ref, want)
+	}
+}
+
+func TestBucketService_DeleteReplication(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		vs := values{
+			"replication": "",
+		}
+		testFormValues(t, r, vs)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Bucket.DeleteReplication(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.DeleteReplication returned error: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+const (
+	// VersioningStatusEnabled 版本控制状态: 启用
+	VersioningStatusEnabled string = "Enabled"
+	// VersioningStatusSuspended 版本控制状态: 暂停
+	VersioningStatusSuspended string = "Suspended"
+)
+
+// BucketPutVersioningOptions ...
+//
+// https://cloud.tencent.com/document/product/436/19889
+type BucketPutVersioningOptions struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	// 版本控制状态，枚举值：Enabled，Suspended
+	Status string `xml:"Status"`
+}
+
+// BucketGetVersioningResult ...
+//
+// https://cloud.tencent.com/document/product/436/19888
+type BucketGetVersioningResult struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	// 版本控制状态，枚举值：Enabled，Suspended。从未启用过版本控制时为空
+	Status string `xml:"Status,omitempty"`
+}
+
+// MethodBucketPutVersioning method name of Bucket.PutVersioning
+const MethodBucketPutVersioning MethodName = "Bucket.PutVersioning"
+
+// PutVersioning ...
+//
+// Put Bucket Versioning 接口实现启用或者暂停存储桶的版本控制功能。
+//
+// 细节分析
+//
+// * 如果您从未在存储桶上启用过版本控制，则 GET Bucket versioning 请求不返回版本状态值；
+// * 开启版本控制功能后，只能暂停，不能关闭；
+// * 设置版本控制状态值为 Enabled 或者 Suspended，表示开启版本控制和暂停版本控制；
+// * 设置存储桶的版本控制功能，您需要有存储桶的写权限。
+//
+// https://cloud.tencent.com/document/product/436/19889
+func (s *BucketService) PutVersioning(ctx context.Context, opt *BucketPutVersioningOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?versioning",
+		method:  http.MethodPut,
+		body:    opt,
+		caller: Caller{
+			Method: MethodBucketPutVersioning,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// MethodBucketGetVersioning method name of Bucket.GetVersioning
+const MethodBucketGetVersioning MethodName = "Bucket.GetVersioning"
+
+// GetVersioning ...
+//
+// Get Bucket Versioning 接口实现获得存储桶的版本控制信息。
+//
+// https://cloud.tencent.com/document/product/436/19888
+func (s *BucketService) GetVersioning(ctx context.Context) (*BucketGetVersioningResult, *Response, error) {
+	var res BucketGetVersioningResult
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?versioning",
+		method:  http.MethodGet,
+		result:  &res,
+		caller: Caller{
+			Method: MethodBucketGetVersioning,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
+
+// BucketListObjectVersionsOptions ...
+//
+// https://cloud.tencent.com/document/product/436/35521
+type BucketListObjectVersionsOptions struct {
+	// 前缀匹配，用来规定返回的对象前缀地址
+	Prefix string `url:"prefix,omitempty"`
+	// 定界符，见 BucketGetOptions.Delimiter
+	Delimiter string `url:"delimiter,omitempty"`
+	// 规定返回值的编码方式，可选值：url
+	EncodingType string `url:"encoding-type,omitempty"`
+	// 起始对象键标记，从该标记之后（不含）按照 UTF-8 字典序返回对象版本条目
+	KeyMarker string `url:"key-marker,omitempty"`
+	// 起始版本 ID 标记，从该标记之后（不含）返回对象版本条目，需要和 KeyMarker 一起使用
+	VersionIDMarker string `url:"version-id-marker,omitempty"`
+	// 单次返回最大的条目数量，默认 1000
+	MaxKeys int `url:"max-keys,omitempty"`
+}
+
+// ObjectVersion 对象的一个版本
+type ObjectVersion struct {
+	// Object 的 Key
+	Key string
+	// 对象的版本 ID，未启用版本控制时上传的对象的版本 ID 为 null
+	VersionID string `xml:"VersionId"`
+	// 当前版本是否为该对象的最新版本
+	IsLatest bool
+	// 当前版本的最后修改时间
+	LastModified string
+	// 对象的实体标签
+	ETag string `xml:",omitempty"`
+	// 对象大小，单位是 Byte
+	Size int `xml:",omitempty"`
+	// 对象的存储级别
+	StorageClass string `xml:",omitempty"`
+	// 对象持有者信息
+	Owner *Owner `xml:",omitempty"`
+}
+
+// BucketListObjectVersionsResult ...
+//
+// https://cloud.tencent.com/document/product/436/35521
+type BucketListObjectVersionsResult struct {
+	XMLName xml.Name `xml:"ListVersionsResult"`
+	// 存储桶的名称
+	Name string
+	// 编码格式
+	EncodingType string `xml:"EncodingType,omitempty"`
+	// 请求中指定的前缀
+	Prefix string
+	// 请求中指定的 KeyMarker
+	KeyMarker string
+	// 请求中指定的 VersionIDMarker
+	VersionIDMarker string `xml:"VersionIdMarker"`
+	// 单次响应返回结果的最大条目数量
+	MaxKeys int
+	// 定界符
+	Delimiter string `xml:"Delimiter,omitempty"`
+	// 响应条目是否被截断，布尔值：true，false
+	IsTruncated bool
+	// 假如返回条目被截断，则 NextKeyMarker 和 NextVersionIDMarker 就是下一个条目的起点
+	NextKeyMarker       string `xml:"NextKeyMarker,omitempty"`
+	NextVersionIDMarker string `xml:"NextVersionIdMarker,omitempty"`
+	// 将 Prefix 到 delimiter 之间的相同路径归为一类，定义为 Common Prefix
+	CommonPrefixes []string `xml:"CommonPrefixes>Prefix,omitempty"`
+	// 对象版本条目
+	Versions []ObjectVersion `xml:"Version,omitempty"`
+	// 删除标记条目
+	DeleteMarkers []ObjectVersion `xml:"DeleteMarker,omitempty"`
+}
+
+// MethodBucketListObjectVersions method name of Bucket.ListObjectVersions
+const MethodBucketListObjectVersions MethodName = "Bucket.ListObjectVersions"
+
+// ListObjectVersions ...
+//
+// GET Object versions 接口用于拉取存储桶内的所有对象及其历史版本信息，
+// 单次请求最多返回 1000 个条目（对象版本和删除标记），可以通过 KeyMarker 和 VersionIDMarker 翻页。
+//
+// 该 API 的请求者需要对存储桶有读取权限。
+//
+// https://cloud.tencent.com/document/product/436/35521
+func (s *BucketService) ListObjectVersions(ctx context.Context, opt *BucketListObjectVersionsOptions) (*BucketListObjectVersionsResult, *Response, error) {
+	var res BucketListObjectVersionsResult
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/?versions",
+		method:   http.MethodGet,
+		optQuery: opt,
+		result:   &res,
+		caller: Caller{
+			Method: MethodBucketListObjectVersions,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
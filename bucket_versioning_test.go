@@ -0,0 +1,154 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBucketService_PutVersioning(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketPutVersioningOptions{
+		Status: VersioningStatusEnabled,
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		v := new(BucketPutVersioningOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"versioning": "",
+		}
+		testFormValues(t, r, vs)
+
+		want := opt
+		want.XMLName = xml.Name{Local: "VersioningConfiguration"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Bucket.PutVersioning request body: %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Bucket.PutVersioning(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.PutVersioning returned error: %v", err)
+	}
+}
+
+func TestBucketService_GetVersioning(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"versioning": "",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<VersioningConfiguration>
+	<Status>Suspended</Status>
+</VersioningConfiguration>`)
+	})
+
+	ref, _, err := client.Bucket.GetVersioning(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetVersioning returned error: %v", err)
+	}
+
+	want := &BucketGetVersioningResult{
+		XMLName: xml.Name{Local: "VersioningConfiguration"},
+		Status:  VersioningStatusSuspended,
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.GetVersioning returned %+v, want %+v", ref, want)
+	}
+}
+
+func TestBucketService_ListObjectVersions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketListObjectVersionsOptions{
+		Prefix:          "test",
+		KeyMarker:       "test/a.txt",
+		VersionIDMarker: "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+		MaxKeys:         2,
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"versions":          "",
+			"prefix":            "test",
+			"key-marker":        "test/a.txt",
+			"version-id-marker": "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+			"max-keys":          "2",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<ListVersionsResult>
+	<Name>test-1253846586</Name>
+	<Prefix>test</Prefix>
+	<KeyMarker>test/a.txt</KeyMarker>
+	<VersionIdMarker>MTg0NDUxNTc1NjIzMTQ1MDAwODg</VersionIdMarker>
+	<MaxKeys>2</MaxKeys>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>test/b.txt</NextKeyMarker>
+	<NextVersionIdMarker>MTg0NDUxNTc1NjE5MjU1MDAwMDA</NextVersionIdMarker>
+	<Version>
+		<Key>test/b.txt</Key>
+		<VersionId>MTg0NDUxNTc1NjE5MjU1MDAwMDA</VersionId>
+		<IsLatest>true</IsLatest>
+		<LastModified>2019-08-18T07:21:51.000Z</LastModified>
+		<ETag>"5d41402abc4b2a76b9719d911017c592"</ETag>
+		<Size>5</Size>
+		<StorageClass>STANDARD</StorageClass>
+	</Version>
+	<DeleteMarker>
+		<Key>test/a.txt</Key>
+		<VersionId>MTg0NDUxNTc1NjE5MjU3MDAwMDA</VersionId>
+		<IsLatest>true</IsLatest>
+		<LastModified>2019-08-18T07:22:01.000Z</LastModified>
+	</DeleteMarker>
+</ListVersionsResult>`)
+	})
+
+	ref, _, err := client.Bucket.ListObjectVersions(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.ListObjectVersions returned error: %v", err)
+	}
+
+	want := &BucketListObjectVersionsResult{
+		XMLName:             xml.Name{Local: "ListVersionsResult"},
+		Name:                "test-1253846586",
+		Prefix:              "test",
+		KeyMarker:           "test/a.txt",
+		VersionIDMarker:     "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+		MaxKeys:             2,
+		IsTruncated:         true,
+		NextKeyMarker:       "test/b.txt",
+		NextVersionIDMarker: "MTg0NDUxNTc1NjE5MjU1MDAwMDA",
+		Versions: []ObjectVersion{{
+			Key:          "test/b.txt",
+			VersionID:    "MTg0NDUxNTc1NjE5MjU1MDAwMDA",
+			IsLatest:     true,
+			LastModified: "2019-08-18T07:21:51.000Z",
+			ETag:         `"5d41402abc4b2a76b9719d911017c592"`,
+			Size:         5,
+			StorageClass: StorageClassStandard,
+		}},
+		DeleteMarkers: []ObjectVersion{{
+			Key:          "test/a.txt",
+			VersionID:    "MTg0NDUxNTc1NjE5MjU3MDAwMDA",
+			IsLatest:     true,
+			LastModified: "2019-08-18T07:22:01.000Z",
+		}},
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.ListObjectVersions returned %+v, want %+v", ref, want)
+	}
+}
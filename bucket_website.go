@@ -0,0 +1,137 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+// BucketWebsiteIndexDocument ...
+type BucketWebsiteIndexDocument struct {
+	// 索引文档的对象键后缀，比如：index.html。访问存储桶根目录或任意子目录时返回该目录下的索引文档
+	Suffix string `xml:"Suffix"`
+}
+
+// BucketWebsiteErrorDocument ...
+type BucketWebsiteErrorDocument struct {
+	// 发生 4XX 错误时返回的错误文档的对象键，比如：pages/error.html
+	Key string `xml:"Key"`
+}
+
+// BucketWebsiteRedirectAllRequestsTo ...
+type BucketWebsiteRedirectAllRequestsTo struct {
+	// 重定向所有请求时使用的协议，枚举值：http，https
+	Protocol string `xml:"Protocol"`
+}
+
+// BucketWebsiteRoutingCondition 重定向规则的匹配条件，HTTPErrorCodeReturnedEquals 和 KeyPrefixEquals 只能指定一个
+type BucketWebsiteRoutingCondition struct {
+	// 返回指定的错误码时应用重定向规则，只支持 4XX 错误码
+	HTTPErrorCodeReturnedEquals int `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+	// 对象键以指定的前缀开头时应用重定向规则
+	KeyPrefixEquals string `xml:"KeyPrefixEquals,omitempty"`
+}
+
+// BucketWebsiteRoutingRedirect 重定向规则的重定向目标，ReplaceKeyWith 和 ReplaceKeyPrefixWith 只能指定一个
+type BucketWebsiteRoutingRedirect struct {
+	// 重定向时使用的协议，枚举值：http，https
+	Protocol string `xml:"Protocol,omitempty"`
+	// 使用指定的对象键替换整个原始请求的对象键
+	ReplaceKeyWith string `xml:"ReplaceKeyWith,omitempty"`
+	// 使用指定的前缀替换原始请求中匹配 KeyPrefixEquals 的前缀部分，仅在条件为 KeyPrefixEquals 时可用
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+}
+
+// BucketWebsiteRoutingRule ...
+type BucketWebsiteRoutingRule struct {
+	Condition BucketWebsiteRoutingCondition `xml:"Condition"`
+	Redirect  BucketWebsiteRoutingRedirect  `xml:"Redirect"`
+}
+
+// BucketPutWebsiteOptions ...
+//
+// https://cloud.tencent.com/document/product/436/31930
+type BucketPutWebsiteOptions struct {
+	XMLName xml.Name `xml:"WebsiteConfiguration"`
+	// 索引文档配置
+	IndexDocument *BucketWebsiteIndexDocument `xml:"IndexDocument,omitempty"`
+	// 重定向所有请求配置，比如将 http 请求全部重定向到 https
+	RedirectAllRequestsTo *BucketWebsiteRedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	// 错误文档配置
+	ErrorDocument *BucketWebsiteErrorDocument `xml:"ErrorDocument,omitempty"`
+	// 重定向规则，最多设置 100 条
+	RoutingRules []BucketWebsiteRoutingRule `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// BucketGetWebsiteResult ...
+//
+// https://cloud.tencent.com/document/product/436/31929
+type BucketGetWebsiteResult BucketPutWebsiteOptions
+
+// MethodBucketPutWebsite method name of Bucket.PutWebsite
+const MethodBucketPutWebsite MethodName = "Bucket.PutWebsite"
+
+// PutWebsite ...
+//
+// Put Bucket Website 请求用于为存储桶配置静态网站，如果存储桶已经配置了静态网站，那么该请求会替换现有配置。
+//
+// 配置后需要通过静态网站域名（<BucketName-APPID>.cos-website.<Region>.myqcloud.com）访问才会生效。
+//
+// https://cloud.tencent.com/document/product/436/31930
+func (s *BucketService) PutWebsite(ctx context.Context, opt *BucketPutWebsiteOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?website",
+		method:  http.MethodPut,
+		body:    opt,
+		caller: Caller{
+			Method: MethodBucketPutWebsite,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// MethodBucketGetWebsite method name of Bucket.GetWebsite
+const MethodBucketGetWebsite MethodName = "Bucket.GetWebsite"
+
+// GetWebsite ...
+//
+// Get Bucket Website 请求用于查询存储桶的静态网站配置，未配置时返回 404 NoSuchWebsiteConfiguration 错误。
+//
+// https://cloud.tencent.com/document/product/436/31929
+func (s *BucketService) GetWebsite(ctx context.Context) (*BucketGetWebsiteResult, *Response, error) {
+	var res BucketGetWebsiteResult
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?website",
+		method:  http.MethodGet,
+		result:  &res,
+		caller: Caller{
+			Method: MethodBucketGetWebsite,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
+
+// MethodBucketDeleteWebsite method name of Bucket.DeleteWebsite
+const MethodBucketDeleteWebsite MethodName = "Bucket.DeleteWebsite"
+
+// DeleteWebsite ...
+//
+// Delete Bucket Website 请求用于删除存储桶中的静态网站配置。
+//
+// https://cloud.tencent.com/document/product/436/31928
+func (s *BucketService) DeleteWebsite(ctx context.Context) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/?website",
+		method:  http.MethodDelete,
+		caller: Caller{
+			Method: MethodBucketDeleteWebsite,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
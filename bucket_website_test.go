@@ -0,0 +1,124 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBucketService_PutWebsite(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &BucketPutWebsiteOptions{
+		IndexDocument:         &BucketWebsiteIndexDocument{Suffix: "index.html"},
+		RedirectAllRequestsTo: &BucketWebsiteRedirectAllRequestsTo{Protocol: "https"},
+		ErrorDocument:         &BucketWebsiteErrorDocument{Key: "error.html"},
+		RoutingRules: []BucketWebsiteRoutingRule{
+			{
+				Condition: BucketWebsiteRoutingCondition{HTTPErrorCodeReturnedEquals: 404},
+				Redirect:  BucketWebsiteRoutingRedirect{ReplaceKeyWith: "index.html"},
+			},
+			{
+				Condition: BucketWebsiteRoutingCondition{KeyPrefixEquals: "docs/"},
+				Redirect:  BucketWebsiteRoutingRedirect{Protocol: "https", ReplaceKeyPrefixWith: "documents/"},
+			},
+		},
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		v := new(BucketPutWebsiteOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"website": "",
+		}
+		testFormValues(t, r, vs)
+
+		want := opt
+		want.XMLName = xml.Name{Local: "WebsiteConfiguration"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Bucket.PutWebsite request body: %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Bucket.PutWebsite(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.PutWebsite returned error: %v", err)
+	}
+}
+
+func TestBucketService_GetWebsite(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"website": "",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<WebsiteConfiguration>
+	<IndexDocument>
+		<Suffix>index.html</Suffix>
+	</IndexDocument>
+	<ErrorDocument>
+		<Key>pages/error.html</Key>
+	</ErrorDocument>
+	<RoutingRules>
+		<RoutingRule>
+			<Condition>
+				<HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals>
+			</Condition>
+			<Redirect>
+				<Protocol>https</Protocol>
+				<ReplaceKeyWith>404.html</ReplaceKeyWith>
+			</Redirect>
+		</RoutingRule>
+	</RoutingRules>
+</WebsiteConfiguration>`)
+	})
+
+	ref, _, err := client.Bucket.GetWebsite(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.GetWebsite returned error: %v", err)
+	}
+
+	want := &BucketGetWebsiteResult{
+		XMLName:       xml.Name{Local: "WebsiteConfiguration"},
+		IndexDocument: &BucketWebsiteIndexDocument{Suffix: "index.html"},
+		ErrorDocument: &BucketWebsiteErrorDocument{Key: "pages/error.html"},
+		RoutingRules: []BucketWebsiteRoutingRule{
+			{
+				Condition: BucketWebsiteRoutingCondition{HTTPErrorCodeReturnedEquals: 404},
+				Redirect:  BucketWebsiteRoutingRedirect{Protocol: "https", ReplaceKeyWith: "404.html"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Bucket.GetWebsite returned %+v, want %+v", ref, want)
+	}
+}
+
+func TestBucketService_DeleteWebsite(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		vs := values{
+			"website": "",
+		}
+		testFormValues(t, r, vs)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Bucket.DeleteWebsite(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.DeleteWebsite returned error: %v", err)
+	}
+}
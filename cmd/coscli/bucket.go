@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mozillazg/go-cos"
+)
+
+func init() {
+	register(
+		&command{name: "mb", args: "cos://<bucket>", short: "create a bucket", run: runMb},
+		&command{name: "rb", args: "cos://<bucket>", short: "delete an empty bucket", run: runRb},
+		&command{name: "acl", args: "get|put cos://<bucket>[/<key>] [file]", short: "get or put the ACL of a bucket or an object", run: runACL},
+		&command{name: "cors", args: "get|put|delete cos://<bucket> [file]", short: "get, put or delete the CORS configuration of a bucket", run: runCORS},
+		&command{name: "lifecycle", args: "get|put|delete cos://<bucket> [file]", short: "get, put or delete the lifecycle configuration of a bucket", run: runLifecycle},
+	)
+}
+
+// bucketArg 解析存储桶参数，不允许指定 Object
+func bucketArg(s string) (location, error) {
+	l, err := cosArg(s, false)
+	if err == nil && l.key != "" {
+		err = fmt.Errorf("%q is not a bucket (cos://<bucket>)", s)
+	}
+	return l, err
+}
+
+func runMb(a *app, args []string) error {
+	fs := a.flagSet("mb")
+	acl := fs.String("acl", "", "ACL of the bucket: private, public-read or public-read-write")
+	args, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := bucketArg(args[0])
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+	_, err = c.Bucket.Put(a.ctx, &cos.BucketPutOptions{XCosACL: *acl})
+	return err
+}
+
+func runRb(a *app, args []string) error {
+	fs := a.flagSet("rb")
+	args, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := bucketArg(args[0])
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+	_, err = c.Bucket.Delete(a.ctx)
+	return err
+}
+
+// readInput 读取配置文件，"-" 表示标准输入
+func (a *app) readInput(filename string) ([]byte, error) {
+	if filename == "-" {
+		return ioutil.ReadAll(a.stdin)
+	}
+	return ioutil.ReadFile(filename)
+}
+
+// printXML 以 XML 格式输出配置，可以直接作为 put 的输入
+func (a *app) printXML(v interface{}) error {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(a.stdout, "%s\n", b)
+	return err
+}
+
+// subcommand 拆分出 get、put、delete 之类的操作，其余的参数由 fs 解析
+func subcommand(fs *flag.FlagSet, args []string, min, max int) (string, []string, error) {
+	if len(args) == 0 {
+		fs.Usage()
+		return "", nil, flag.ErrHelp
+	}
+	rest, err := parse(fs, args[1:], min, max)
+	return args[0], rest, err
+}
+
+func runACL(a *app, args []string) error {
+	fs := a.flagSet("acl")
+	acl := fs.String("acl", "", "put a canned ACL (private, public-read, ...) instead of reading the ACL from file")
+	action, args, err := subcommand(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	l, err := cosArg(args[0], false)
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "get":
+		var res interface{}
+		if l.key == "" {
+			res, _, err = c.Bucket.GetACL(a.ctx)
+		} else {
+			res, _, err = c.Object.GetACL(a.ctx, l.key)
+		}
+		if err != nil {
+			return err
+		}
+		return a.printXML(res)
+	case "put":
+		var header *cos.ACLHeaderOptions
+		var body *cos.ACLXml
+		switch {
+		case *acl != "":
+			header = &cos.ACLHeaderOptions{XCosACL: *acl}
+		case len(args) == 2:
+			data, err := a.readInput(args[1])
+			if err != nil {
+				return err
+			}
+			body = new(cos.ACLXml)
+			if err := xml.Unmarshal(data, body); err != nil {
+				return fmt.Errorf("invalid ACL file %s: %v", args[1], err)
+			}
+		default:
+			return fmt.Errorf("acl put: either -acl or an ACL file is required")
+		}
+		if l.key == "" {
+			_, err = c.Bucket.PutACL(a.ctx, &cos.BucketPutACLOptions{Header: header, Body: body})
+		} else {
+			_, err = c.Object.PutACL(a.ctx, l.key, &cos.ObjectPutACLOptions{Header: header, Body: body})
+		}
+		return err
+	default:
+		return fmt.Errorf("acl: unknown action %q, want get or put", action)
+	}
+}
+
+// bucketConfig 存储桶的一类配置（比如 CORS、生命周期）的查询、设置和删除操作
+type bucketConfig struct {
+	get func(a *app, c *cos.Client) (interface{}, error)
+	// data 是 XML 格式的配置
+	put func(a *app, c *cos.Client, data []byte) error
+	del func(a *app, c *cos.Client) error
+}
+
+func (a *app) runBucketConfig(name string, args []string, cfg bucketConfig) error {
+	fs := a.flagSet(name)
+	action, args, err := subcommand(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	l, err := bucketArg(args[0])
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "get":
+		res, err := cfg.get(a, c)
+		if err != nil {
+			return err
+		}
+		return a.printXML(res)
+	case "put":
+		if len(args) != 2 {
+			return fmt.Errorf("%s put: configuration file is required", name)
+		}
+		data, err := a.readInput(args[1])
+		if err != nil {
+			return err
+		}
+		return cfg.put(a, c, data)
+	case "delete":
+		return cfg.del(a, c)
+	default:
+		return fmt.Errorf("%s: unknown action %q, want get, put or delete", name, action)
+	}
+}
+
+func runCORS(a *app, args []string) error {
+	return a.runBucketConfig("cors", args, bucketConfig{
+		get: func(a *app, c *cos.Client) (interface{}, error) {
+			res, _, err := c.Bucket.GetCORS(a.ctx)
+			return res, err
+		},
+		put: func(a *app, c *cos.Client, data []byte) error {
+			var opt cos.BucketPutCORSOptions
+			if err := xml.Unmarshal(data, &opt); err != nil {
+				return fmt.Errorf("invalid CORS configuration: %v", err)
+			}
+			_, err := c.Bucket.PutCORS(a.ctx, &opt)
+			return err
+		},
+		del: func(a *app, c *cos.Client) error {
+			_, err := c.Bucket.DeleteCORS(a.ctx)
+			return err
+		},
+	})
+}
+
+func runLifecycle(a *app, args []string) error {
+	return a.runBucketConfig("lifecycle", args, bucketConfig{
+		get: func(a *app, c *cos.Client) (interface{}, error) {
+			res, _, err := c.Bucket.GetLifecycle(a.ctx)
+			return res, err
+		},
+		put: func(a *app, c *cos.Client, data []byte) error {
+			var opt cos.BucketPutLifecycleOptions
+			if err := xml.Unmarshal(data, &opt); err != nil {
+				return fmt.Errorf("invalid lifecycle configuration: %v", err)
+			}
+			_, err := c.Bucket.PutLifecycle(a.ctx, &opt)
+			return err
+		},
+		del: func(a *app, c *cos.Client) error {
+			_, err := c.Bucket.DeleteLifecycle(a.ctx)
+			return err
+		},
+	})
+}
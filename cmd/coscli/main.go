@@ -0,0 +1,245 @@
+/*
+Command coscli 是一个基于 go-cos 的命令行工具，用于日常的存储桶和对象操作。
+
+用法：
+
+	coscli [-profile name] [-region region] [-debug] <command> [flags] [args]
+
+Object 通过 cos://<bucket>-<appid>/<key> 的形式指定，其他的参数都是本地文件的路径，"-" 表示标准输入或标准输出。
+支持的命令：
+
+	ls        列出存储桶或者 Object
+	cp        复制文件（本地 <-> COS，COS <-> COS）
+	mv        移动文件
+	rm        删除 Object
+	cat       输出 Object 的内容
+	stat      输出 Object 的元数据
+	presign   生成预签名授权 URL
+	mb        创建存储桶
+	rb        删除存储桶
+	acl       查询或设置存储桶和 Object 的 ACL
+	cors      查询、设置或删除存储桶的跨域配置
+	lifecycle 查询、设置或删除存储桶的生命周期配置
+
+密钥依次从环境变量（COS_SECRETID、COS_SECRETKEY、COS_SESSIONTOKEN）和密钥文件
+（环境变量 COS_CREDENTIALS_FILE 或 ~/.cos/credentials）中读取，密钥文件的格式见 cos.FileCredentialProvider。
+地域依次从 -region 参数、环境变量 COS_REGION 和密钥文件中的 region 配置读取：
+
+	[default]
+	secret_id = AKIDxxx
+	secret_key = xxx
+	region = ap-guangzhou
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/debug"
+)
+
+// command 一个子命令
+type command struct {
+	name string
+	// 参数的说明，用于输出帮助信息
+	args  string
+	short string
+	run   func(a *app, args []string) error
+}
+
+var commands = map[string]*command{}
+
+func register(cmds ...*command) {
+	for _, c := range cmds {
+		commands[c.name] = c
+	}
+}
+
+// app 执行命令时的上下文
+type app struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	region      string
+	debug       bool
+	credentials cos.CredentialProvider
+	// 返回访问 bucket 的 Client，bucket 为空时用于访问 Service API。测试时会被替换
+	newClient func(bucket string) (*cos.Client, error)
+}
+
+func main() {
+	a := &app{
+		ctx:    context.Background(),
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	if err := a.run(os.Args[1:]); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "coscli: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.stderr, "Usage: coscli [-profile name] [-region region] [-debug] <command> [flags] [args]")
+	fmt.Fprintln(a.stderr, "\nCommands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(a.stderr, "  %-10s %s\n", name, commands[name].short)
+	}
+	fmt.Fprintln(a.stderr, "\nRun 'coscli <command> -h' for more information about a command.")
+}
+
+func (a *app) run(args []string) error {
+	fs := flag.NewFlagSet("coscli", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = a.usage
+	profile := fs.String("profile", "", "profile in the credentials file (default $COS_PROFILE or default)")
+	region := fs.String("region", "", "region of the buckets (default $COS_REGION or region in the profile)")
+	fs.BoolVar(&a.debug, "debug", false, "print HTTP request and response headers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		a.usage()
+		return flag.ErrHelp
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		a.usage()
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	if a.newClient == nil {
+		file := &cos.FileCredentialProvider{Profile: *profile}
+		a.credentials = &cos.CachedCredentialProvider{
+			Provider: cos.ChainCredentialProvider{&cos.EnvCredentialProvider{}, file},
+		}
+		a.region = *region
+		if a.region == "" {
+			a.region = os.Getenv("COS_REGION")
+		}
+		if a.region == "" {
+			// 密钥文件不存在时忽略
+			values, _ := file.ProfileValues()
+			a.region = values["region"]
+		}
+		a.newClient = a.defaultClient
+	}
+	return cmd.run(a, fs.Args()[1:])
+}
+
+// flagSet 返回解析子命令 name 的参数的 FlagSet
+func (a *app) flagSet(name string) *flag.FlagSet {
+	cmd := commands[name]
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: coscli %s [flags] %s\n\n%s\n", cmd.name, cmd.args, cmd.short)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse 解析子命令的参数，并检查剩余参数的数量是否在 [min, max] 之间，max 小于 0 表示不限制
+func parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if n := fs.NArg(); n < min || (max >= 0 && n > max) {
+		fs.Usage()
+		return nil, flag.ErrHelp
+	}
+	return fs.Args(), nil
+}
+
+func (a *app) defaultClient(bucket string) (*cos.Client, error) {
+	var transport http.RoundTripper
+	if a.debug {
+		transport = &debug.DebugRequestTransport{
+			RequestHeader:  true,
+			ResponseHeader: true,
+			Writer:         a.stderr,
+		}
+	}
+	httpClient := &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			CredentialProvider: a.credentials,
+			Transport:          transport,
+		},
+	}
+	if bucket == "" {
+		return cos.NewClient(nil, httpClient), nil
+	}
+
+	i := strings.LastIndex(bucket, "-")
+	if i <= 0 || i == len(bucket)-1 {
+		return nil, fmt.Errorf("invalid bucket %q: must be in the form <name>-<appid>", bucket)
+	}
+	if a.region == "" {
+		return nil, errors.New("region is required: use -region, $COS_REGION or region in the profile")
+	}
+	u := cos.NewBucketURL(bucket[:i], bucket[i+1:], a.region, true)
+	return cos.NewClient(&cos.BaseURL{BucketURL: u}, httpClient), nil
+}
+
+// location 命令行中的一个路径，bucket 为空时表示本地文件
+type location struct {
+	bucket string
+	// Object 的 Key 或者本地文件的路径
+	key string
+}
+
+const scheme = "cos://"
+
+func parseLocation(s string) location {
+	if !strings.HasPrefix(s, scheme) {
+		return location{key: s}
+	}
+	s = strings.TrimPrefix(s, scheme)
+	i := strings.Index(s, "/")
+	if i < 0 {
+		return location{bucket: s}
+	}
+	return location{bucket: s[:i], key: s[i+1:]}
+}
+
+func (l location) isCOS() bool {
+	return l.bucket != ""
+}
+
+func (l location) String() string {
+	if !l.isCOS() {
+		return l.key
+	}
+	return scheme + l.bucket + "/" + l.key
+}
+
+// cosArg 解析 COS 路径参数，needKey 为 true 时要求指定 Object
+func cosArg(s string, needKey bool) (location, error) {
+	l := parseLocation(s)
+	if !l.isCOS() {
+		return l, fmt.Errorf("%q is not a COS path (cos://<bucket>/<key>)", s)
+	}
+	if needKey && (l.key == "" || strings.HasSuffix(l.key, "/")) {
+		return l, fmt.Errorf("%q: object key is required", s)
+	}
+	return l, nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/costest"
+)
+
+const (
+	testBucket  = "test-1250000000"
+	otherBucket = "other-1250000000"
+)
+
+// testApp 返回一个访问 costest.Server 的 app
+func testApp(t *testing.T) (*app, *costest.Server, *bytes.Buffer) {
+	srv := costest.NewServer()
+	srv.CreateBucket(testBucket)
+	srv.CreateBucket(otherBucket)
+	out := new(bytes.Buffer)
+	a := &app{
+		ctx:    context.Background(),
+		stdin:  strings.NewReader(""),
+		stdout: out,
+		stderr: ioutil.Discard,
+		credentials: &cos.StaticCredentialProvider{
+			SecretID:  srv.SecretID,
+			SecretKey: srv.SecretKey,
+		},
+		newClient: func(bucket string) (*cos.Client, error) {
+			return srv.NewClient(bucket), nil
+		},
+	}
+	return a, srv, out
+}
+
+// run 执行命令并返回输出
+func run(t *testing.T, a *app, out *bytes.Buffer, args ...string) string {
+	out.Reset()
+	if err := a.run(args); err != nil {
+		t.Fatalf("coscli %s returned error: %v", strings.Join(args, " "), err)
+	}
+	return out.String()
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "coscli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestParseLocation(t *testing.T) {
+	for s, want := range map[string]location{
+		"a.txt":                    {key: "a.txt"},
+		"cos://test-125/":          {bucket: "test-125"},
+		"cos://test-125":           {bucket: "test-125"},
+		"cos://test-125/dir/a.txt": {bucket: "test-125", key: "dir/a.txt"},
+	} {
+		if got := parseLocation(s); got != want {
+			t.Errorf("parseLocation(%s) = %+v, want %+v", s, got, want)
+		}
+	}
+}
+
+func TestCopyAndList(t *testing.T) {
+	a, srv, out := testApp(t)
+	defer srv.Close()
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, "hello.txt")
+	ioutil.WriteFile(local, []byte("hello"), 0644)
+
+	// 本地 -> COS
+	run(t, a, out, "cp", local, "cos://"+testBucket+"/dir/")
+	a.stdin = strings.NewReader("from stdin")
+	run(t, a, out, "cp", "-", "cos://"+testBucket+"/stdin.txt")
+	// COS -> COS
+	run(t, a, out, "cp", "cos://"+testBucket+"/dir/hello.txt", "cos://"+otherBucket+"/copy.txt")
+	// COS -> 本地
+	run(t, a, out, "cp", "cos://"+otherBucket+"/copy.txt", dir+string(filepath.Separator))
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "copy.txt")); string(b) != "hello" {
+		t.Errorf("downloaded file: %q", b)
+	}
+
+	// Key 中包含需要编码的字符
+	special := "dir/a b?c#d%e 中文.txt"
+	run(t, a, out, "cp", "cos://"+testBucket+"/dir/hello.txt", "cos://"+otherBucket+"/"+special)
+	run(t, a, out, "cp", "cos://"+otherBucket+"/"+special, "cos://"+otherBucket+"/"+special+".bak")
+	if got := run(t, a, out, "cat", "cos://"+otherBucket+"/"+special+".bak"); got != "hello" {
+		t.Errorf("cat returned %q", got)
+	}
+
+	if got := run(t, a, out, "cat", "cos://"+testBucket+"/stdin.txt", "cos://"+otherBucket+"/copy.txt"); got != "from stdinhello" {
+		t.Errorf("cat returned %q", got)
+	}
+	if got := run(t, a, out, "ls", "cos://"+testBucket); got != "dir/\nstdin.txt\n" {
+		t.Errorf("ls returned %q", got)
+	}
+	if got := run(t, a, out, "ls", "-r", "cos://"+testBucket+"/"); got != "dir/hello.txt\nstdin.txt\n" {
+		t.Errorf("ls -r returned %q", got)
+	}
+	got := run(t, a, out, "ls", "-l", "cos://"+testBucket+"/dir/")
+	if fields := strings.Fields(got); len(fields) != 5 || fields[2] != "5" || fields[4] != "dir/hello.txt" {
+		t.Errorf("ls -l returned %q", got)
+	}
+	if got := run(t, a, out, "ls"); got != "cos://"+otherBucket+"\ncos://"+testBucket+"\n" {
+		t.Errorf("ls returned %q", got)
+	}
+
+	got = run(t, a, out, "stat", "cos://"+testBucket+"/dir/hello.txt")
+	for _, want := range []string{"Key: cos://" + testBucket + "/dir/hello.txt\n", "Content-Length: 5\n", "Content-Type: text/plain"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("stat returned %q, want %q", got, want)
+		}
+	}
+}
+
+func TestMoveAndRemove(t *testing.T) {
+	a, srv, out := testApp(t)
+	defer srv.Close()
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(local, []byte("a"), 0644)
+	run(t, a, out, "mv", local, "cos://"+testBucket+"/a.txt")
+	if _, err := os.Stat(local); !os.IsNotExist(err) {
+		t.Error("mv should remove the local file")
+	}
+	run(t, a, out, "mv", "cos://"+testBucket+"/a.txt", "cos://"+testBucket+"/dir/b.txt")
+	if got := run(t, a, out, "ls", "-r", "cos://"+testBucket); got != "dir/b.txt\n" {
+		t.Errorf("ls returned %q", got)
+	}
+
+	for _, key := range []string{"dir/c.txt", "dir/sub/d.txt", "dir2/e.txt", "f.txt"} {
+		a.stdin = strings.NewReader(key)
+		run(t, a, out, "cp", "-", "cos://"+testBucket+"/"+key)
+	}
+	got := run(t, a, out, "rm", "-r", "cos://"+testBucket+"/dir")
+	if strings.Count(got, "delete: ") != 3 {
+		t.Errorf("rm -r returned %q", got)
+	}
+	run(t, a, out, "rm", "cos://"+testBucket+"/f.txt")
+	if got := run(t, a, out, "ls", "-r", "cos://"+testBucket); got != "dir2/e.txt\n" {
+		t.Errorf("ls returned %q", got)
+	}
+
+	if err := a.run([]string{"rm", "cos://" + testBucket + "/dir/"}); err == nil {
+		t.Error("rm without -r should require an object key")
+	}
+}
+
+func TestPresign(t *testing.T) {
+	a, srv, out := testApp(t)
+	defer srv.Close()
+
+	a.stdin = strings.NewReader("hello")
+	run(t, a, out, "cp", "-", "cos://"+testBucket+"/hello.txt")
+	u := strings.TrimSpace(run(t, a, out, "presign", "-expire", "10m", "cos://"+testBucket+"/hello.txt"))
+	if !strings.Contains(u, "sign=") {
+		t.Fatalf("presign returned %q", u)
+	}
+	resp, err := (&http.Client{Transport: srv.Transport()}).Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, _ := ioutil.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(b) != "hello" {
+		t.Errorf("GET presigned URL returned %d %q", resp.StatusCode, b)
+	}
+}
+
+func TestBucketCommands(t *testing.T) {
+	a, srv, out := testApp(t)
+	defer srv.Close()
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	run(t, a, out, "mb", "-acl", "public-read", "cos://new-1250000000")
+	if got := run(t, a, out, "acl", "get", "cos://new-1250000000"); !strings.Contains(got, "<AccessControlPolicy>") {
+		t.Errorf("acl get returned %q", got)
+	}
+	run(t, a, out, "rb", "cos://new-1250000000")
+	if err := a.run([]string{"acl", "get", "cos://new-1250000000"}); err == nil {
+		t.Error("acl get should return error after rb")
+	}
+
+	// acl get 的输出可以直接作为 acl put 的输入
+	a.stdin = strings.NewReader("hello")
+	run(t, a, out, "cp", "-", "cos://"+testBucket+"/hello.txt")
+	run(t, a, out, "acl", "put", "-acl", "public-read", "cos://"+testBucket+"/hello.txt")
+	acl := run(t, a, out, "acl", "get", "cos://"+testBucket+"/hello.txt")
+	a.stdin = strings.NewReader(acl)
+	run(t, a, out, "acl", "put", "cos://"+testBucket+"/hello.txt", "-")
+
+	cors := filepath.Join(dir, "cors.xml")
+	ioutil.WriteFile(cors, []byte(`<CORSConfiguration><CORSRule>
+<AllowedOrigin>http://example.com</AllowedOrigin><AllowedMethod>GET</AllowedMethod>
+</CORSRule></CORSConfiguration>`), 0644)
+	run(t, a, out, "cors", "put", "cos://"+testBucket, cors)
+	if got := run(t, a, out, "cors", "get", "cos://"+testBucket); !strings.Contains(got, "<AllowedOrigin>http://example.com</AllowedOrigin>") {
+		t.Errorf("cors get returned %q", got)
+	}
+	run(t, a, out, "cors", "delete", "cos://"+testBucket)
+	if err := a.run([]string{"cors", "get", "cos://" + testBucket}); err == nil {
+		t.Error("cors get should return error after delete")
+	}
+
+	lifecycle := filepath.Join(dir, "lifecycle.xml")
+	ioutil.WriteFile(lifecycle, []byte(`<LifecycleConfiguration><Rule>
+<ID>expire</ID><Filter><Prefix>logs/</Prefix></Filter><Status>Enabled</Status><Expiration><Days>7</Days></Expiration>
+</Rule></LifecycleConfiguration>`), 0644)
+	run(t, a, out, "lifecycle", "put", "cos://"+testBucket, lifecycle)
+	if got := run(t, a, out, "lifecycle", "get", "cos://"+testBucket); !strings.Contains(got, "<Days>7</Days>") {
+		t.Errorf("lifecycle get returned %q", got)
+	}
+	run(t, a, out, "lifecycle", "delete", "cos://"+testBucket)
+}
+
+func TestUsage(t *testing.T) {
+	a, srv, _ := testApp(t)
+	defer srv.Close()
+	var stderr bytes.Buffer
+	a.stderr = &stderr
+
+	for _, args := range [][]string{nil, {"cp", "a.txt"}, {"ls", "-h"}, {"cors"}} {
+		stderr.Reset()
+		if err := a.run(args); err != flag.ErrHelp || !strings.Contains(stderr.String(), "Usage: coscli") {
+			t.Errorf("coscli %v returned %v, output %q", args, err, stderr.String())
+		}
+	}
+	for _, args := range [][]string{{"unknown"}, {"cp", "a.txt", "b.txt"}, {"cat", "a.txt"}, {"cors", "list", "cos://" + testBucket}} {
+		if err := a.run(args); err == nil || err == flag.ErrHelp {
+			t.Errorf("coscli %v returned %v, want error", args, err)
+		}
+	}
+}
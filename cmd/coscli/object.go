@@ -0,0 +1,421 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+)
+
+// DeleteMulti 每次最多删除 1000 个 Object
+const deleteBatchSize = 1000
+
+func init() {
+	register(
+		&command{name: "ls", args: "[cos://<bucket>[/<prefix>]]", short: "list buckets, or objects under a prefix", run: runLs},
+		&command{name: "cp", args: "<src> <dst>", short: "copy a file between local and COS, or between COS paths", run: runCp},
+		&command{name: "mv", args: "<src> <dst>", short: "move a file between local and COS, or between COS paths", run: runMv},
+		&command{name: "rm", args: "cos://<bucket>/<key>...", short: "delete objects", run: runRm},
+		&command{name: "cat", args: "cos://<bucket>/<key>...", short: "print the content of objects", run: runCat},
+		&command{name: "stat", args: "cos://<bucket>/<key>", short: "print the metadata of an object", run: runStat},
+		&command{name: "presign", args: "cos://<bucket>/<key>", short: "generate a presigned URL for an object", run: runPresign},
+	)
+}
+
+func runLs(a *app, args []string) error {
+	fs := a.flagSet("ls")
+	recursive := fs.Bool("r", false, "list all objects under the prefix instead of one level")
+	long := fs.Bool("l", false, "use a long listing format (last modified, size, storage class)")
+	args, err := parse(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return a.listBuckets(*long)
+	}
+
+	l, err := cosArg(args[0], false)
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+	opt := &cos.BucketGetOptions{Prefix: l.key}
+	if !*recursive {
+		opt.Delimiter = "/"
+	}
+	pager := c.Bucket.ListObjects(a.ctx, opt)
+	for pager.Next() {
+		o := pager.Object()
+		switch {
+		case o == nil && *long:
+			fmt.Fprintf(a.stdout, "%19s %12s %-12s %s\n", "", "DIR", "", pager.CommonPrefix())
+		case o == nil:
+			fmt.Fprintln(a.stdout, pager.CommonPrefix())
+		case *long:
+			fmt.Fprintf(a.stdout, "%19s %12d %-12s %s\n", formatTime(o.LastModified), o.Size, o.StorageClass, o.Key)
+		default:
+			fmt.Fprintln(a.stdout, o.Key)
+		}
+	}
+	return pager.Err()
+}
+
+func (a *app) listBuckets(long bool) error {
+	c, err := a.newClient("")
+	if err != nil {
+		return err
+	}
+	res, _, err := c.Service.Get(a.ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range res.Buckets {
+		name := b.Name
+		if b.AppID != "" && !strings.HasSuffix(name, "-"+b.AppID) {
+			name += "-" + b.AppID
+		}
+		if long {
+			fmt.Fprintf(a.stdout, "%19s %-16s %s%s\n", formatTime(b.CreateDate), b.Region, scheme, name)
+		} else {
+			fmt.Fprintf(a.stdout, "%s%s\n", scheme, name)
+		}
+	}
+	return nil
+}
+
+// formatTime 将 COS 返回的 ISO 8601 格式的时间转换为 UTC 时间，无法解析时原样返回
+func formatTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+func runCp(a *app, args []string) error {
+	fs := a.flagSet("cp")
+	args, err := parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	_, err = a.copy(parseLocation(args[0]), parseLocation(args[1]))
+	return err
+}
+
+func runMv(a *app, args []string) error {
+	fs := a.flagSet("mv")
+	args, err := parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	src := parseLocation(args[0])
+	if src.key == "-" && !src.isCOS() {
+		return errors.New("mv: cannot move from standard input")
+	}
+	dst, err := a.copy(src, parseLocation(args[1]))
+	if err != nil {
+		return err
+	}
+	if src == dst {
+		return nil
+	}
+	if !src.isCOS() {
+		return os.Remove(src.key)
+	}
+	c, err := a.newClient(src.bucket)
+	if err != nil {
+		return err
+	}
+	_, err = c.Object.Delete(a.ctx, src.key)
+	return err
+}
+
+// copy 复制 src 到 dst，返回实际的目标路径。
+// dst 以 "/" 结尾（或者是本地的目录）时使用 src 的文件名作为目标文件名
+func (a *app) copy(src, dst location) (location, error) {
+	if !src.isCOS() && !dst.isCOS() {
+		return dst, errors.New("at least one of the source and destination must be a COS path")
+	}
+	if src.isCOS() && (src.key == "" || strings.HasSuffix(src.key, "/")) {
+		return dst, fmt.Errorf("%s: object key is required", src)
+	}
+
+	base := path.Base(src.key)
+	if !src.isCOS() {
+		base = filepath.Base(src.key)
+	}
+	if dst.isCOS() && (dst.key == "" || strings.HasSuffix(dst.key, "/")) {
+		if !src.isCOS() && src.key == "-" {
+			return dst, fmt.Errorf("%s: object key is required when copying from standard input", dst)
+		}
+		dst.key += base
+	}
+	if !dst.isCOS() && dst.key != "-" {
+		if info, err := os.Stat(dst.key); (err == nil && info.IsDir()) || strings.HasSuffix(dst.key, string(filepath.Separator)) {
+			dst.key = filepath.Join(dst.key, base)
+		}
+	}
+
+	switch {
+	case !src.isCOS():
+		return dst, a.upload(src.key, dst)
+	case !dst.isCOS():
+		return dst, a.download(src, dst.key)
+	default:
+		return dst, a.copyObject(src, dst)
+	}
+}
+
+func (a *app) upload(filename string, dst location) error {
+	var r io.Reader = a.stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	c, err := a.newClient(dst.bucket)
+	if err != nil {
+		return err
+	}
+	opt := &cos.ObjectUploadOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: mime.TypeByExtension(path.Ext(dst.key)),
+		},
+	}
+	_, _, err = c.Object.Upload(a.ctx, dst.key, r, opt)
+	return err
+}
+
+func (a *app) download(src location, filename string) error {
+	c, err := a.newClient(src.bucket)
+	if err != nil {
+		return err
+	}
+	if filename == "-" {
+		return a.cat(c, src.key)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	_, err = c.Object.Download(a.ctx, src.key, f, nil)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(filename)
+	}
+	return err
+}
+
+func (a *app) copyObject(src, dst location) error {
+	sc, err := a.newClient(src.bucket)
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(dst.bucket)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.Object.Copy(a.ctx, dst.key, copySource(sc, src.key), nil)
+	return err
+}
+
+// copySource 返回 Object.Copy 使用的源 Object 地址，Key 中的空格、?、# 以及非 ASCII 字符等需要进行 URL 编码
+func copySource(c *cos.Client, key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return c.BaseURL.BucketURL.Host + "/" + strings.Join(segments, "/")
+}
+
+func runRm(a *app, args []string) error {
+	fs := a.flagSet("rm")
+	recursive := fs.Bool("r", false, `delete all objects under the prefix ("/" is appended if missing)`)
+	args, err := parse(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		l, err := cosArg(arg, !*recursive)
+		if err != nil {
+			return err
+		}
+		c, err := a.newClient(l.bucket)
+		if err != nil {
+			return err
+		}
+		if *recursive {
+			err = a.removeAll(c, l)
+		} else if _, err = c.Object.Delete(a.ctx, l.key); err == nil {
+			fmt.Fprintf(a.stdout, "delete: %s\n", l)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeAll 通过 DeleteMulti 分批删除以 l.key 为前缀的 Object
+func (a *app) removeAll(c *cos.Client, l location) error {
+	prefix := l.key
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	var objects []cos.Object
+	pager := c.Bucket.ListObjects(a.ctx, &cos.BucketGetOptions{Prefix: prefix, MaxKeys: deleteBatchSize})
+	for pager.Next() {
+		objects = append(objects, cos.Object{Key: pager.Object().Key})
+		if len(objects) == deleteBatchSize {
+			if err := a.deleteObjects(c, l.bucket, objects); err != nil {
+				return err
+			}
+			objects = objects[:0]
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return err
+	}
+	if len(objects) > 0 {
+		return a.deleteObjects(c, l.bucket, objects)
+	}
+	return nil
+}
+
+// deleteObjects 通过 DeleteMulti 删除 bucket 中的 objects
+func (a *app) deleteObjects(c *cos.Client, bucket string, objects []cos.Object) error {
+	res, _, err := c.Object.DeleteMulti(a.ctx, &cos.ObjectDeleteMultiOptions{Objects: objects})
+	if err != nil {
+		return err
+	}
+	for _, o := range res.DeletedObjects {
+		fmt.Fprintf(a.stdout, "delete: %s\n", location{bucket: bucket, key: o.Key})
+	}
+	if len(res.Errors) > 0 {
+		e := res.Errors[0]
+		return fmt.Errorf("failed to delete %d objects, %s: %s (%s)", len(res.Errors), e.Key, e.Message, e.Code)
+	}
+	return nil
+}
+
+func runCat(a *app, args []string) error {
+	fs := a.flagSet("cat")
+	args, err := parse(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		l, err := cosArg(arg, true)
+		if err != nil {
+			return err
+		}
+		c, err := a.newClient(l.bucket)
+		if err != nil {
+			return err
+		}
+		if err := a.cat(c, l.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *app) cat(c *cos.Client, key string) error {
+	resp, err := c.Object.Get(a.ctx, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(a.stdout, resp.Body)
+	return err
+}
+
+func runStat(a *app, args []string) error {
+	fs := a.flagSet("stat")
+	args, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := cosArg(args[0], true)
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Object.Head(a.ctx, l.key, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Key: %s\n", l)
+	printHeader(a.stdout, resp.Header)
+	return nil
+}
+
+func printHeader(w io.Writer, h http.Header) {
+	var names []string
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range h[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, v)
+		}
+	}
+}
+
+func runPresign(a *app, args []string) error {
+	fs := a.flagSet("presign")
+	method := fs.String("method", http.MethodGet, "HTTP method allowed by the URL (GET to download, PUT to upload)")
+	expire := fs.Duration("expire", time.Hour, "validity period of the URL")
+	args, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := cosArg(args[0], true)
+	if err != nil {
+		return err
+	}
+	c, err := a.newClient(l.bucket)
+	if err != nil {
+		return err
+	}
+	creds, err := a.credentials.Credentials(a.ctx)
+	if err != nil {
+		return err
+	}
+	u, err := c.Object.PresignedURL(a.ctx, strings.ToUpper(*method), l.key, cos.Auth{
+		SecretID:  creds.SecretID,
+		SecretKey: creds.SecretKey,
+		Expire:    *expire,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	// 使用临时密钥生成的 URL 需要带上对应的 token
+	if creds.SessionToken != "" {
+		u.RawQuery += "&x-cos-security-token=" + url.QueryEscape(creds.SessionToken)
+	}
+	fmt.Fprintln(a.stdout, u)
+	return nil
+}
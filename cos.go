@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -24,6 +25,7 @@ const (
 	Version               = "0.13.0"
 	userAgent             = "go-cos/" + Version
 	contentTypeXML        = "application/xml"
+	contentTypeJSON       = "application/json"
 	defaultServiceBaseURL = "https://service.cos.myqcloud.com"
 )
 
@@ -125,7 +127,7 @@ func NewClient(uri *BaseURL, httpClient *http.Client) *Client {
 	}
 
 	c := &Client{
-		Sender:         &DefaultSender{httpClient},
+		Sender:         &DefaultSender{Client: httpClient},
 		ResponseParser: &DefaultResponseParser{},
 		UserAgent:      userAgent,
 		BaseURL:        baseURL,
@@ -161,11 +163,17 @@ func (c *Client) newRequest(ctx context.Context, opt *sendOptions) (req *http.Re
 		if r, ok := body.(io.Reader); ok {
 			reader = r
 		} else {
-			b, err := xml.Marshal(body)
+			var b []byte
+			if opt.jsonBody {
+				b, err = json.Marshal(body)
+				contentType = contentTypeJSON
+			} else {
+				b, err = xml.Marshal(body)
+				contentType = contentTypeXML
+			}
 			if err != nil {
 				return nil, err
 			}
-			contentType = contentTypeXML
 			reader = bytes.NewReader(b)
 			contentMD5 = base64.StdEncoding.EncodeToString(calMD5Digest(b))
 			// xsha1 = base64.StdEncoding.EncodeToString(calSHA1Digest(b))
@@ -183,10 +191,17 @@ func (c *Client) newRequest(ctx context.Context, opt *sendOptions) (req *http.Re
 	if err != nil {
 		return
 	}
+	if err = setSSECustomerKeyMD5(req); err != nil {
+		return nil, err
+	}
 	if v := req.Header.Get("Content-Length"); req.ContentLength == 0 && v != "" && v != "0" {
 		req.ContentLength, _ = strconv.ParseInt(v, 10, 64)
 		req.Body = ioutil.NopCloser(reader)
 	}
+	// 支持在重试请求时重新读取 body
+	if req.GetBody == nil && req.Body != nil {
+		setGetBody(req, reader)
+	}
 
 	if contentMD5 != "" {
 		req.Header["Content-MD5"] = []string{contentMD5}
@@ -203,6 +218,27 @@ func (c *Client) newRequest(ctx context.Context, opt *sendOptions) (req *http.Re
 	return
 }
 
+// setGetBody 当 r 实现了 io.Seeker 时设置 req.GetBody，用于从 r 当前的位置开始重新读取 body。
+// 同时把 req.Body 替换为不会关闭 r 的 ReadCloser，避免 r（比如 *os.File）在第一次发送后被 Transport 关闭
+func setGetBody(req *http.Request, r io.Reader) {
+	seeker, ok := r.(io.ReadSeeker)
+	if !ok {
+		return
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// 比如 r 是 pipe 之类不支持 Seek 的 *os.File
+		return
+	}
+	req.Body = ioutil.NopCloser(seeker)
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(seeker), nil
+	}
+}
+
 func (c *Client) doAPI(ctx context.Context, caller Caller, req *http.Request, result interface{}, closeBody bool) (*Response, error) {
 	req = req.WithContext(ctx)
 	resp, err := c.Sender.Send(ctx, caller, req)
@@ -230,6 +266,8 @@ type sendOptions struct {
 	method string
 
 	body interface{}
+	// 使用 JSON 而不是 XML 格式序列化 body
+	jsonBody bool
 	// url 查询参数
 	optQuery interface{}
 	// http header 参数
@@ -338,6 +376,9 @@ var (
 	xCosVersionID            = "x-cos-version-id"
 	xCosServerSideEncryption = "x-cos-server-side-encryption"
 	xCosMetaPrefix           = "x-cos-meta-"
+	xCosHashCRC64ECMA        = "x-cos-hash-crc64ecma"
+	xCosReplicationStatus    = "x-cos-replication-status"
+	xCosRestore              = "x-cos-restore"
 )
 
 // RequestID 每次请求发送时，服务端将会自动为请求生成一个ID。
@@ -365,11 +406,22 @@ func (resp *Response) VersionID() string {
 	return resp.Header.Get(xCosVersionID)
 }
 
-// ServerSideEncryption 如果通过 COS 管理的服务端加密来存储对象，响应将包含此头部和所使用的加密算法的值，AES256。
+// ServerSideEncryption 如果通过 COS 管理的服务端加密来存储对象，响应将包含此头部和所使用的加密算法的值，AES256 或者 cos/kms。
 func (resp *Response) ServerSideEncryption() string {
 	return resp.Header.Get(xCosServerSideEncryption)
 }
 
+// HashCRC64ECMA Object 的 CRC64 校验值（ECMA-182 标准，十进制字符串）
+func (resp *Response) HashCRC64ECMA() string {
+	return resp.Header.Get(xCosHashCRC64ECMA)
+}
+
+// ReplicationStatus Object 的跨地域复制状态，
+// 枚举值：PENDING，COMPLETED，FAILED（源对象），REPLICA（复制生成的副本）。未配置跨地域复制时为空
+func (resp *Response) ReplicationStatus() string {
+	return resp.Header.Get(xCosReplicationStatus)
+}
+
 // MetaHeaders 用户自定义的元数据
 func (resp *Response) MetaHeaders() http.Header {
 	h := http.Header{}
@@ -436,6 +488,8 @@ const (
 
 	// ServerSideEncryptionAES256 服务端加密算法: AES256
 	ServerSideEncryptionAES256 string = "AES256"
+	// ServerSideEncryptionKMS 服务端加密算法: cos/kms，使用 KMS 托管的密钥进行服务端加密
+	ServerSideEncryptionKMS string = "cos/kms"
 
 	// PermissionRead 权限值: READ
 	PermissionRead string = "READ"
@@ -222,6 +222,7 @@ func Test_Response_header_method(t *testing.T) {
 	storageCls := "STANDARD"
 	versionID := "xxx-v1" // ?
 	encryption := "AES256"
+	crc64 := "16749565679157681890"
 
 	mux.HandleFunc("/test_down", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(xCosRequestID, reqID)
@@ -230,6 +231,8 @@ func Test_Response_header_method(t *testing.T) {
 		w.Header().Set(xCosStorageClass, storageCls)
 		w.Header().Set(xCosVersionID, versionID)
 		w.Header().Set(xCosServerSideEncryption, encryption)
+		w.Header().Set(xCosHashCRC64ECMA, crc64)
+		w.Header().Set(xCosReplicationStatus, ReplicationStatusReplica)
 		w.Header().Add("x-cos-meta-1", "1")
 		w.Header().Add("x-cos-meta-1", "11")
 		w.Header().Add("x-cos-meta-2", "2")
@@ -267,6 +270,8 @@ func Test_Response_header_method(t *testing.T) {
 		resp.StorageClass() != storageCls ||
 		resp.VersionID() != versionID ||
 		resp.ServerSideEncryption() != encryption ||
+		resp.HashCRC64ECMA() != crc64 ||
+		resp.ReplicationStatus() != ReplicationStatusReplica ||
 		!reflect.DeepEqual(keys,
 			[]string{"x-cos-meta-1", "x-cos-meta-2", "x-cos-meta-3"}) {
 		t.Errorf("result of response header method is not expected")
@@ -0,0 +1,461 @@
+/*
+Package coscrypto 提供了 COS 客户端加密（信封加密）的功能。
+
+每个对象使用随机生成的数据密钥通过 AES-256-CTR 或 AES-256-GCM 加密后再上传，
+数据密钥通过 KeyProvider 加密后和加密参数一起保存在对象的 x-cos-meta-client-side-encryption-* 元数据中，
+下载时先通过 KeyProvider 解密数据密钥，再解密对象的内容。
+
+	provider, err := coscrypto.NewMasterKeyProvider(masterKey, "master-key-v1")
+	c := coscrypto.NewClient(cos.NewClient(b, httpClient), provider, nil)
+
+	_, err = c.Object.Put(ctx, "secret.txt", strings.NewReader("hello"), nil)
+	resp, err := c.Object.Get(ctx, "secret.txt", nil)
+
+Client 内嵌了 *cos.Client，Client.Object 之外的 API（比如 c.Bucket）和原来的用法相同。
+通过 c.Client.Object 访问的 API 不会进行加解密。
+*/
+package coscrypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mozillazg/go-cos"
+)
+
+var (
+	// ErrUnsupportedRange 不支持的 Range 格式，或者对 AES-GCM 加密的对象进行 Range 读取
+	ErrUnsupportedRange = errors.New("coscrypto: unsupported range")
+	// ErrInvalidPartSize 分块上传的分块大小不是 16 的倍数
+	ErrInvalidPartSize = errors.New("coscrypto: part size must be a positive multiple of 16")
+	// ErrNotEncrypted 设置了 Options.RequireEncryption 时，下载的对象没有客户端加密的信息
+	ErrNotEncrypted = errors.New("coscrypto: object is not client-side encrypted")
+)
+
+// Options ...
+//
+// NewClient 的参数
+type Options struct {
+	// 加密对象内容的算法，枚举值：AlgorithmAESCTR，AlgorithmAESGCM，默认值：AlgorithmAESCTR。
+	// 分块上传总是使用 AlgorithmAESCTR
+	Algorithm string
+	// 为 true 时 Get 遇到没有加密的对象会返回 ErrNotEncrypted，而不是返回原始内容
+	RequireEncryption bool
+}
+
+// Client 客户端加密的 COS 客户端
+type Client struct {
+	*cos.Client
+
+	// 对上传和下载的内容进行加解密的 Object 相关 API
+	Object *ObjectService
+}
+
+// NewClient 返回一个使用 provider 加密数据密钥的 Client
+func NewClient(c *cos.Client, provider KeyProvider, opt *Options) *Client {
+	if opt == nil {
+		opt = &Options{}
+	}
+	algorithm := AlgorithmAESCTR
+	if opt.Algorithm != "" {
+		algorithm = opt.Algorithm
+	}
+	return &Client{
+		Client: c,
+		Object: &ObjectService{
+			client:            c,
+			provider:          provider,
+			algorithm:         algorithm,
+			requireEncryption: opt.RequireEncryption,
+		},
+	}
+}
+
+// ObjectService ...
+//
+// 会对上传和下载的内容进行加解密的 Object 相关 API
+type ObjectService struct {
+	client            *cos.Client
+	provider          KeyProvider
+	algorithm         string
+	requireEncryption bool
+}
+
+// Put 加密 r 中的数据后通过 cos.ObjectService.Put 上传。
+//
+// 使用 AlgorithmAESGCM 时会将 r 中的数据全部读取到内存中再进行加密。
+// 使用 AlgorithmAESCTR 时流式加密，r 的大小未知且没有指定 opt.ContentLength 时使用 chunked 编码上传。
+// opt 中的 XCosContentSHA1 会被忽略。
+func (s *ObjectService) Put(ctx context.Context, name string, r io.Reader, opt *cos.ObjectPutOptions) (*cos.Response, error) {
+	e, err := newEnvelope(s.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	o := &cos.ObjectPutOptions{}
+	header := &cos.ObjectPutHeaderOptions{}
+	if opt != nil {
+		*o = *opt
+		if opt.ObjectPutHeaderOptions != nil {
+			*header = *opt.ObjectPutHeaderOptions
+		}
+	}
+	o.ObjectPutHeaderOptions = header
+	header.XCosContentSHA1 = ""
+
+	size := int64(header.ContentLength)
+	if size <= 0 {
+		size = readerSize(r)
+	}
+	var body io.Reader
+	switch e.algorithm {
+	case AlgorithmAESGCM:
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := e.aead()
+		if err != nil {
+			return nil, err
+		}
+		size = int64(len(data))
+		sealed := aead.Seal(nil, e.iv, data, nil)
+		header.ContentLength = len(sealed)
+		body = bytes.NewReader(sealed)
+	default:
+		stream, err := e.ctrStream(0)
+		if err != nil {
+			return nil, err
+		}
+		if size >= 0 {
+			header.ContentLength = int(size)
+		}
+		body = &cipher.StreamReader{S: stream, R: r}
+	}
+
+	meta, err := s.metaHeader(ctx, e, header.XCosMetaXXX)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 {
+		meta.Set(headerUnencryptedLength, strconv.FormatInt(size, 10))
+	}
+	header.XCosMetaXXX = meta
+	return s.client.Object.Put(ctx, name, body, o)
+}
+
+// metaHeader 返回包含 h 中的元数据和加密信息的元数据头部
+func (s *ObjectService) metaHeader(ctx context.Context, e *envelope, h *http.Header) (*http.Header, error) {
+	meta := http.Header{}
+	if h != nil {
+		for k, v := range *h {
+			meta[k] = append([]string(nil), v...)
+		}
+	}
+	if err := e.setHeader(ctx, s.provider, meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// Get 下载对象并解密，返回的 Response.Body 是解密后的内容。
+// 对象没有加密时返回原始内容，设置了 Options.RequireEncryption 时返回 ErrNotEncrypted。
+//
+// AlgorithmAESCTR 加密的对象支持 opt.Range 指定 bytes=<start>-<end> 或 bytes=<start>- 格式的范围，
+// 会将范围的起始位置对齐到 16 字节后再请求，并跳过多出的数据。
+// AlgorithmAESGCM 加密的对象在解密时会将整个对象读取到内存中并校验数据的完整性，不支持 Range 读取。
+func (s *ObjectService) Get(ctx context.Context, name string, opt *cos.ObjectGetOptions) (*cos.Response, error) {
+	o := &cos.ObjectGetOptions{}
+	if opt != nil {
+		*o = *opt
+	}
+	var start, skip int64
+	if o.Range != "" {
+		var end string
+		var err error
+		start, end, err = parseRange(o.Range)
+		if err != nil {
+			return nil, err
+		}
+		skip = start % aes.BlockSize
+		o.Range = fmt.Sprintf("bytes=%d-%s", start-skip, end)
+	}
+
+	resp, err := s.client.Object.Get(ctx, name, o)
+	if err != nil {
+		return resp, err
+	}
+	e, err := loadEnvelope(ctx, s.provider, resp.Header)
+	if err != nil {
+		resp.Body.Close()
+		return resp, err
+	}
+	if e == nil {
+		// 没有加密的对象
+		if s.requireEncryption {
+			resp.Body.Close()
+			return resp, ErrNotEncrypted
+		}
+		if skip > 0 {
+			err = s.skip(resp, start, skip)
+		}
+		return resp, err
+	}
+
+	switch e.algorithm {
+	case AlgorithmAESGCM:
+		defer resp.Body.Close()
+		if o.Range != "" {
+			return resp, ErrUnsupportedRange
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp, err
+		}
+		aead, err := e.aead()
+		if err != nil {
+			return resp, err
+		}
+		plain, err := aead.Open(nil, e.iv, data, nil)
+		if err != nil {
+			return resp, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(plain))
+		setContentLength(resp, int64(len(plain)))
+		return resp, nil
+	default:
+		stream, err := e.ctrStream(start - skip)
+		if err != nil {
+			resp.Body.Close()
+			return resp, err
+		}
+		resp.Body = &readCloser{&cipher.StreamReader{S: stream, R: resp.Body}, resp.Body}
+		if skip > 0 {
+			err = s.skip(resp, start, skip)
+		}
+		return resp, err
+	}
+}
+
+// skip 跳过为了对齐而多读取的 n 字节数据，并修正响应中的 Content-Length 和 Content-Range
+func (s *ObjectService) skip(resp *cos.Response, start, n int64) error {
+	if _, err := io.CopyN(ioutil.Discard, resp.Body, n); err != nil {
+		resp.Body.Close()
+		return err
+	}
+	if resp.ContentLength >= 0 {
+		setContentLength(resp, resp.ContentLength-n)
+	}
+	if v := resp.Header.Get("Content-Range"); strings.HasPrefix(v, "bytes ") {
+		if i := strings.Index(v, "-"); i > 0 {
+			resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d%s", start, v[i:]))
+		}
+	}
+	return nil
+}
+
+// MultipartContext 分块上传的加密上下文，需要在 InitiateMultipartUpload 和 UploadPart 中使用同一个 MultipartContext
+type MultipartContext struct {
+	// 分块大小，必须是 16 的倍数。除最后一个分块外，每个分块的大小都必须等于 PartSize
+	PartSize int64
+	// 原始数据的大小，可选，会保存在对象的元数据中。指定后 UploadPart 会同时校验最后一个分块的大小
+	DataSize int64
+
+	envelope *envelope
+
+	mu sync.Mutex
+	// 已上传的最大的分块编号，以及小于 PartSize 的分块（即最后一个分块）的编号
+	maxPart, lastPart int
+}
+
+// checkPart 校验第 n 个分块的大小：除最后一个分块外都必须等于 PartSize，
+// 没有指定 DataSize 时小于 PartSize 的分块被当做最后一个分块，之后不能再上传编号更大的分块
+func (mc *MultipartContext) checkPart(n int, size int64) error {
+	if size > mc.PartSize {
+		return fmt.Errorf("coscrypto: part %d size %d exceeds %d", n, size, mc.PartSize)
+	}
+	if mc.DataSize > 0 {
+		want := mc.DataSize - int64(n-1)*mc.PartSize
+		if want <= 0 {
+			return fmt.Errorf("coscrypto: part number %d exceeds the last part of %d bytes data", n, mc.DataSize)
+		}
+		if want > mc.PartSize {
+			want = mc.PartSize
+		}
+		if size != want {
+			return fmt.Errorf("coscrypto: part %d size is %d, want %d", n, size, want)
+		}
+		return nil
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if size < mc.PartSize {
+		if (mc.lastPart != 0 && mc.lastPart != n) || mc.maxPart > n {
+			return fmt.Errorf("coscrypto: part %d size %d is less than %d but it is not the last part", n, size, mc.PartSize)
+		}
+		mc.lastPart = n
+	} else if mc.lastPart != 0 && n >= mc.lastPart {
+		return fmt.Errorf("coscrypto: part %d is after the last part %d", n, mc.lastPart)
+	}
+	if n > mc.maxPart {
+		mc.maxPart = n
+	}
+	return nil
+}
+
+// InitiateMultipartUpload 生成数据密钥并初始化分块上传，加密信息会保存在 mc 和对象的元数据中。
+// 分块上传总是使用 AlgorithmAESCTR 加密
+func (s *ObjectService) InitiateMultipartUpload(ctx context.Context, name string, opt *cos.InitiateMultipartUploadOptions, mc *MultipartContext) (*cos.InitiateMultipartUploadResult, *cos.Response, error) {
+	if mc == nil || mc.PartSize <= 0 || mc.PartSize%aes.BlockSize != 0 {
+		return nil, nil, ErrInvalidPartSize
+	}
+	e, err := newEnvelope(AlgorithmAESCTR)
+	if err != nil {
+		return nil, nil, err
+	}
+	o := &cos.InitiateMultipartUploadOptions{}
+	header := &cos.ObjectPutHeaderOptions{}
+	if opt != nil {
+		*o = *opt
+		if opt.ObjectPutHeaderOptions != nil {
+			*header = *opt.ObjectPutHeaderOptions
+		}
+	}
+	o.ObjectPutHeaderOptions = header
+
+	meta, err := s.metaHeader(ctx, e, header.XCosMetaXXX)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta.Set(headerPartSize, strconv.FormatInt(mc.PartSize, 10))
+	if mc.DataSize > 0 {
+		meta.Set(headerDataSize, strconv.FormatInt(mc.DataSize, 10))
+		meta.Set(headerUnencryptedLength, strconv.FormatInt(mc.DataSize, 10))
+	}
+	header.XCosMetaXXX = meta
+
+	res, resp, err := s.client.Object.InitiateMultipartUpload(ctx, name, o)
+	if err == nil {
+		mc.mu.Lock()
+		mc.envelope = e
+		mc.maxPart, mc.lastPart = 0, 0
+		mc.mu.Unlock()
+	}
+	return res, resp, err
+}
+
+// UploadPart 加密 r 中的数据后上传第 partNumber 个分块。
+//
+// 除最后一个分块外，r 中的数据大小必须等于 mc.PartSize，否则返回错误（CTR 计数器是按照 PartSize 计算的）。
+// 没有指定 opt.ContentLength 且无法获取 r 的大小时，会先将数据读取到内存中（最多 mc.PartSize 字节）。
+func (s *ObjectService) UploadPart(ctx context.Context, name, uploadID string, partNumber int, r io.Reader, mc *MultipartContext, opt *cos.ObjectUploadPartOptions) (*cos.Response, error) {
+	if mc == nil || mc.envelope == nil {
+		return nil, errors.New("coscrypto: MultipartContext is not initialized by InitiateMultipartUpload")
+	}
+	if partNumber < 1 {
+		return nil, fmt.Errorf("coscrypto: invalid part number %d", partNumber)
+	}
+	stream, err := mc.envelope.ctrStream(int64(partNumber-1) * mc.PartSize)
+	if err != nil {
+		return nil, err
+	}
+	o := &cos.ObjectUploadPartOptions{}
+	if opt != nil {
+		*o = *opt
+	}
+	// 校验值是针对原始数据计算的，不能用于加密后的数据
+	o.ContentMD5 = ""
+	o.XCosContentSHA1 = ""
+	if o.ContentLength <= 0 {
+		if size := readerSize(r); size >= 0 {
+			o.ContentLength = int(size)
+		} else {
+			data, err := ioutil.ReadAll(io.LimitReader(r, mc.PartSize+1))
+			if err != nil {
+				return nil, err
+			}
+			o.ContentLength = len(data)
+			r = bytes.NewReader(data)
+		}
+	}
+	if err := mc.checkPart(partNumber, int64(o.ContentLength)); err != nil {
+		return nil, err
+	}
+	return s.client.Object.UploadPart(ctx, name, uploadID, partNumber, &cipher.StreamReader{S: stream, R: r}, o)
+}
+
+// CompleteMultipartUpload 同 cos.ObjectService.CompleteMultipartUpload
+func (s *ObjectService) CompleteMultipartUpload(ctx context.Context, name, uploadID string, opt *cos.CompleteMultipartUploadOptions) (*cos.CompleteMultipartUploadResult, *cos.Response, error) {
+	return s.client.Object.CompleteMultipartUpload(ctx, name, uploadID, opt)
+}
+
+// AbortMultipartUpload 同 cos.ObjectService.AbortMultipartUpload
+func (s *ObjectService) AbortMultipartUpload(ctx context.Context, name, uploadID string) (*cos.Response, error) {
+	return s.client.Object.AbortMultipartUpload(ctx, name, uploadID)
+}
+
+// parseRange 解析 bytes=<start>-<end> 或 bytes=<start>- 格式的范围，end 为空时表示到文件末尾
+func parseRange(s string) (int64, string, error) {
+	if !strings.HasPrefix(s, "bytes=") || strings.Contains(s, ",") {
+		return 0, "", ErrUnsupportedRange
+	}
+	parts := strings.SplitN(strings.TrimPrefix(s, "bytes="), "-", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return 0, "", ErrUnsupportedRange
+	}
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || start < 0 {
+		return 0, "", ErrUnsupportedRange
+	}
+	if parts[1] != "" {
+		if end, err := strconv.ParseInt(parts[1], 10, 64); err != nil || end < start {
+			return 0, "", ErrUnsupportedRange
+		}
+	}
+	return start, parts[1], nil
+}
+
+// readCloser 读取解密后的数据，Close 时关闭原始的 Body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func setContentLength(resp *cos.Response, n int64) {
+	resp.ContentLength = n
+	resp.Header.Set("Content-Length", strconv.FormatInt(n, 10))
+}
+
+// readerSize 返回 r 中剩余数据的大小，无法获取时返回 -1
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface {
+		Len() int
+	}:
+		return int64(v.Len())
+	case io.Seeker:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := v.Seek(offset, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - offset
+	}
+	return -1
+}
@@ -0,0 +1,282 @@
+package coscrypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/costest"
+)
+
+const testBucket = "test-1250000000"
+
+var testMasterKey = []byte("0123456789abcdef0123456789abcdef")
+
+func setup(t *testing.T, opt *Options) (*costest.Server, *Client) {
+	srv := costest.NewServer()
+	srv.CreateBucket(testBucket)
+	provider, err := NewMasterKeyProvider(testMasterKey, "test-key")
+	if err != nil {
+		t.Fatalf("NewMasterKeyProvider returned error: %v", err)
+	}
+	return srv, NewClient(srv.NewClient(testBucket), provider, opt)
+}
+
+func getObject(t *testing.T, s interface {
+	Get(context.Context, string, *cos.ObjectGetOptions) (*cos.Response, error)
+}, name string, opt *cos.ObjectGetOptions) (*cos.Response, string) {
+	resp, err := s.Get(context.Background(), name, opt)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body returned error: %v", err)
+	}
+	return resp, string(b)
+}
+
+func TestObjectService_Put_Get(t *testing.T) {
+	data := strings.Repeat("0123456789", 100)
+	for _, algorithm := range []string{AlgorithmAESCTR, AlgorithmAESGCM} {
+		srv, c := setup(t, &Options{Algorithm: algorithm})
+		ctx := context.Background()
+
+		opt := &cos.ObjectPutOptions{
+			ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+				ContentType: "text/plain",
+				XCosMetaXXX: &http.Header{"X-Cos-Meta-Test": []string{"test"}},
+			},
+		}
+		if _, err := c.Object.Put(ctx, "secret.txt", strings.NewReader(data), opt); err != nil {
+			t.Fatalf("%s: Object.Put returned error: %v", algorithm, err)
+		}
+		if opt.XCosMetaXXX.Get(headerKey) != "" {
+			t.Errorf("%s: Object.Put should not modify opt", algorithm)
+		}
+
+		resp, got := getObject(t, c.Object, "secret.txt", nil)
+		if got != data {
+			t.Errorf("%s: Object.Get returned %q, want %q", algorithm, got, data)
+		}
+		if resp.ContentLength != int64(len(data)) || resp.Header.Get("X-Cos-Meta-Test") != "test" {
+			t.Errorf("%s: Object.Get returned Content-Length %d, header %v", algorithm, resp.ContentLength, resp.Header)
+		}
+		if resp.Header.Get(headerCEKAlg) != algorithm || resp.Header.Get(headerMatDesc) != "test-key" ||
+			resp.Header.Get(headerUnencryptedLength) != "1000" {
+			t.Errorf("%s: Object.Get returned header %v", algorithm, resp.Header)
+		}
+
+		// 通过 cos.Client 下载的是加密后的内容
+		_, raw := getObject(t, c.Client.Object, "secret.txt", nil)
+		if raw == data || strings.Contains(raw, "0123456789") {
+			t.Errorf("%s: object is not encrypted", algorithm)
+		}
+		srv.Close()
+	}
+}
+
+func TestObjectService_Get_range(t *testing.T) {
+	srv, c := setup(t, nil)
+	defer srv.Close()
+	ctx := context.Background()
+
+	data := strings.Repeat("abcdefghijklmnopqrstuvwxyz", 10)
+	if _, err := c.Object.Put(ctx, "secret.txt", strings.NewReader(data), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	// 没有加密的对象
+	if _, err := c.Client.Object.Put(ctx, "plain.txt", strings.NewReader(data), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+
+	for _, name := range []string{"secret.txt", "plain.txt"} {
+		for _, tt := range []struct {
+			r          string
+			start, end int
+		}{
+			{"bytes=0-9", 0, 9},
+			{"bytes=16-31", 16, 31},
+			{"bytes=37-100", 37, 100},
+			{"bytes=250-", 250, len(data) - 1},
+		} {
+			resp, got := getObject(t, c.Object, name, &cos.ObjectGetOptions{Range: tt.r})
+			if want := data[tt.start : tt.end+1]; got != want {
+				t.Errorf("%s %s: Object.Get returned %q, want %q", name, tt.r, got, want)
+			}
+			if resp.ContentLength != int64(tt.end-tt.start+1) {
+				t.Errorf("%s %s: Object.Get returned Content-Length %d", name, tt.r, resp.ContentLength)
+			}
+			if v := resp.Header.Get("Content-Range"); !strings.HasPrefix(v, "bytes "+strings.TrimPrefix(tt.r, "bytes=")) {
+				t.Errorf("%s %s: Object.Get returned Content-Range %q", name, tt.r, v)
+			}
+		}
+	}
+
+	if _, err := c.Object.Get(ctx, "secret.txt", &cos.ObjectGetOptions{Range: "bytes=-10"}); err != ErrUnsupportedRange {
+		t.Errorf("Object.Get returned error %v, want %v", err, ErrUnsupportedRange)
+	}
+
+	gcm := NewClient(c.Client, c.Object.provider, &Options{Algorithm: AlgorithmAESGCM})
+	if _, err := gcm.Object.Put(ctx, "gcm.txt", strings.NewReader(data), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if _, err := gcm.Object.Get(ctx, "gcm.txt", &cos.ObjectGetOptions{Range: "bytes=0-9"}); err != ErrUnsupportedRange {
+		t.Errorf("Object.Get returned error %v, want %v", err, ErrUnsupportedRange)
+	}
+}
+
+func TestObjectService_Get_wrongKey(t *testing.T) {
+	srv, c := setup(t, &Options{Algorithm: AlgorithmAESGCM})
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := c.Object.Put(ctx, "secret.txt", strings.NewReader("hello"), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+
+	other, _ := NewMasterKeyProvider([]byte("fedcba9876543210fedcba9876543210"), "test-key")
+	if _, err := NewClient(c.Client, other, nil).Object.Get(ctx, "secret.txt", nil); err == nil {
+		t.Error("Object.Get with wrong master key should return error")
+	}
+	renamed, _ := NewMasterKeyProvider(testMasterKey, "other-key")
+	if _, err := NewClient(c.Client, renamed, nil).Object.Get(ctx, "secret.txt", nil); err != ErrKeyDescriptionMismatch {
+		t.Errorf("Object.Get returned error %v, want %v", err, ErrKeyDescriptionMismatch)
+	}
+}
+
+func TestObjectService_multipart(t *testing.T) {
+	srv, c := setup(t, &Options{Algorithm: AlgorithmAESGCM})
+	defer srv.Close()
+	ctx := context.Background()
+
+	partSize := int64(1024 * 1024)
+	data := bytes.Repeat([]byte("0123456789"), 250*1024)
+	if _, _, err := c.Object.InitiateMultipartUpload(ctx, "big.bin", nil, &MultipartContext{PartSize: 1000}); err != ErrInvalidPartSize {
+		t.Errorf("Object.InitiateMultipartUpload returned error %v, want %v", err, ErrInvalidPartSize)
+	}
+
+	mc := &MultipartContext{PartSize: partSize, DataSize: int64(len(data))}
+	init, _, err := c.Object.InitiateMultipartUpload(ctx, "big.bin", nil, mc)
+	if err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+	for _, tt := range []struct {
+		n    int
+		data []byte
+	}{
+		{1, data},
+		{1, data[:partSize-16]},
+		{3, data[:partSize]},
+		{4, data[:16]},
+	} {
+		if _, err := c.Object.UploadPart(ctx, "big.bin", init.UploadID, tt.n, bytes.NewReader(tt.data), mc, nil); err == nil {
+			t.Errorf("Object.UploadPart(%d) with %d bytes should return error", tt.n, len(tt.data))
+		}
+	}
+
+	var parts []cos.Object
+	// 乱序上传分块
+	for _, n := range []int{3, 1, 2} {
+		start := int64(n-1) * partSize
+		end := start + partSize
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		resp, err := c.Object.UploadPart(ctx, "big.bin", init.UploadID, n, bytes.NewReader(data[start:end]), mc, nil)
+		if err != nil {
+			t.Fatalf("Object.UploadPart returned error: %v", err)
+		}
+		parts = append(parts, cos.Object{PartNumber: n, ETag: resp.Header.Get("ETag")})
+	}
+	parts[0], parts[1], parts[2] = parts[1], parts[2], parts[0]
+	if _, _, err := c.Object.CompleteMultipartUpload(ctx, "big.bin", init.UploadID, &cos.CompleteMultipartUploadOptions{Parts: parts}); err != nil {
+		t.Fatalf("Object.CompleteMultipartUpload returned error: %v", err)
+	}
+
+	resp, got := getObject(t, c.Object, "big.bin", nil)
+	if got != string(data) {
+		t.Errorf("Object.Get returned %d bytes, want %d", len(got), len(data))
+	}
+	if resp.Header.Get(headerCEKAlg) != AlgorithmAESCTR || resp.Header.Get(headerPartSize) != "1048576" {
+		t.Errorf("Object.Get returned header %v", resp.Header)
+	}
+	_, got = getObject(t, c.Object, "big.bin", &cos.ObjectGetOptions{Range: "bytes=1048570-1048600"})
+	if want := string(data[1048570:1048601]); got != want {
+		t.Errorf("Object.Get returned %q, want %q", got, want)
+	}
+}
+
+func TestObjectService_UploadPart_size(t *testing.T) {
+	srv, c := setup(t, nil)
+	defer srv.Close()
+	ctx := context.Background()
+
+	partSize := int64(1024 * 1024)
+	mc := &MultipartContext{PartSize: partSize}
+	init, _, err := c.Object.InitiateMultipartUpload(ctx, "big.bin", nil, mc)
+	if err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+	part := bytes.Repeat([]byte("a"), int(partSize))
+	for _, tt := range []struct {
+		n    int
+		r    io.Reader
+		fail bool
+	}{
+		{2, bytes.NewReader(part), false},
+		// 大小未知的数据
+		{1, struct{ io.Reader }{bytes.NewReader(part)}, false},
+		{4, bytes.NewReader(part[:100]), false},
+		// 最后一个分块之后的分块
+		{5, bytes.NewReader(part), true},
+		// 最后一个分块之前的分块必须等于 PartSize
+		{3, struct{ io.Reader }{bytes.NewReader(part[:100])}, true},
+		{3, bytes.NewReader(part), false},
+	} {
+		_, err := c.Object.UploadPart(ctx, "big.bin", init.UploadID, tt.n, tt.r, mc, nil)
+		if tt.fail != (err != nil) {
+			t.Errorf("Object.UploadPart(%d) returned error %v, want error: %v", tt.n, err, tt.fail)
+		}
+	}
+}
+
+func TestObjectService_Get_requireEncryption(t *testing.T) {
+	srv, c := setup(t, &Options{RequireEncryption: true})
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := c.Client.Object.Put(ctx, "plain.txt", strings.NewReader("hello"), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if _, err := c.Object.Get(ctx, "plain.txt", nil); err != ErrNotEncrypted {
+		t.Errorf("Object.Get returned error %v, want %v", err, ErrNotEncrypted)
+	}
+	if _, err := c.Object.Put(ctx, "secret.txt", strings.NewReader("hello"), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if _, got := getObject(t, c.Object, "secret.txt", nil); got != "hello" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+}
+
+func TestAddCounter(t *testing.T) {
+	iv := bytes.Repeat([]byte{0xff}, aes.BlockSize)
+	iv[0] = 0
+	got := addCounter(iv, 1)
+	want := make([]byte, aes.BlockSize)
+	want[0] = 1
+	if !bytes.Equal(got, want) {
+		t.Errorf("addCounter returned %x, want %x", got, want)
+	}
+	if got := addCounter(make([]byte, aes.BlockSize), 0x1ff); got[14] != 1 || got[15] != 0xff {
+		t.Errorf("addCounter returned %x", got)
+	}
+}
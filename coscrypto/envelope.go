@@ -0,0 +1,142 @@
+package coscrypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// AlgorithmAESCTR 使用 AES-256-CTR 加密对象的内容，加密后的大小和原始数据相同，支持流式加解密、分块上传和 Range 读取
+	AlgorithmAESCTR = "AES/CTR/NoPadding"
+	// AlgorithmAESGCM 使用 AES-256-GCM 加密对象的内容，可以校验数据的完整性，
+	// 但是加解密时需要将整个对象读取到内存中，并且不支持分块上传和 Range 读取
+	AlgorithmAESGCM = "AES/GCM/NoPadding"
+)
+
+// 保存加密信息的元数据头部
+const (
+	metaPrefix = "x-cos-meta-client-side-encryption-"
+	// 加密后的数据密钥，base64 编码
+	headerKey = metaPrefix + "key"
+	// CTR 的初始计数器或者 GCM 的 nonce，base64 编码
+	headerIV = metaPrefix + "start"
+	// 加密对象内容的算法
+	headerCEKAlg = metaPrefix + "cek-alg"
+	// KeyProvider 返回的数据密钥的描述信息
+	headerMatDesc = metaPrefix + "matdesc"
+	// 原始数据的大小
+	headerUnencryptedLength = metaPrefix + "unencrypted-content-length"
+	// 分块上传时的分块大小和原始数据的大小
+	headerPartSize = metaPrefix + "part-size"
+	headerDataSize = metaPrefix + "data-size"
+)
+
+// 数据密钥的长度，使用 AES-256
+const dataKeySize = 32
+
+// envelope 一个对象的数据密钥和加密参数
+type envelope struct {
+	algorithm string
+	key       []byte
+	iv        []byte
+}
+
+// newEnvelope 生成随机的数据密钥和初始向量
+func newEnvelope(algorithm string) (*envelope, error) {
+	ivSize := aes.BlockSize
+	switch algorithm {
+	case AlgorithmAESCTR:
+	case AlgorithmAESGCM:
+		ivSize = 12
+	default:
+		return nil, fmt.Errorf("coscrypto: unsupported algorithm %q", algorithm)
+	}
+	e := &envelope{
+		algorithm: algorithm,
+		key:       make([]byte, dataKeySize),
+		iv:        make([]byte, ivSize),
+	}
+	if _, err := io.ReadFull(rand.Reader, e.key); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, e.iv); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// setHeader 使用 provider 加密数据密钥，并将加密信息保存到元数据头部中
+func (e *envelope) setHeader(ctx context.Context, provider KeyProvider, h http.Header) error {
+	wrapped, desc, err := provider.WrapKey(ctx, e.key)
+	if err != nil {
+		return err
+	}
+	h.Set(headerKey, base64.StdEncoding.EncodeToString(wrapped))
+	h.Set(headerIV, base64.StdEncoding.EncodeToString(e.iv))
+	h.Set(headerCEKAlg, e.algorithm)
+	if desc != "" {
+		h.Set(headerMatDesc, desc)
+	}
+	return nil
+}
+
+// loadEnvelope 从对象的元数据头部中读取加密信息并使用 provider 解密数据密钥，对象没有加密时返回 nil
+func loadEnvelope(ctx context.Context, provider KeyProvider, h http.Header) (*envelope, error) {
+	if h.Get(headerKey) == "" {
+		return nil, nil
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(h.Get(headerKey))
+	if err != nil {
+		return nil, fmt.Errorf("coscrypto: invalid %s: %v", headerKey, err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(h.Get(headerIV))
+	if err != nil {
+		return nil, fmt.Errorf("coscrypto: invalid %s: %v", headerIV, err)
+	}
+	key, err := provider.UnwrapKey(ctx, wrapped, h.Get(headerMatDesc))
+	if err != nil {
+		return nil, err
+	}
+	e := &envelope{algorithm: h.Get(headerCEKAlg), key: key, iv: iv}
+	switch {
+	case e.algorithm == AlgorithmAESCTR && len(iv) == aes.BlockSize:
+	case e.algorithm == AlgorithmAESGCM && len(iv) == 12:
+	default:
+		return nil, fmt.Errorf("coscrypto: unsupported algorithm %q with %d bytes iv", e.algorithm, len(iv))
+	}
+	return e, nil
+}
+
+// ctrStream 返回从原始数据的 offset 处开始加解密的 CTR 流，offset 必须是 aes.BlockSize 的倍数
+func (e *envelope) ctrStream(offset int64) (cipher.Stream, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCTR(block, addCounter(e.iv, uint64(offset/aes.BlockSize))), nil
+}
+
+func (e *envelope) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// addCounter 将 iv 作为 128 位的大端整数加上 n
+func addCounter(iv []byte, n uint64) []byte {
+	counter := append([]byte(nil), iv...)
+	for i := len(counter) - 1; i >= 0 && n > 0; i-- {
+		sum := uint64(counter[i]) + n&0xff
+		counter[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+	return counter
+}
@@ -0,0 +1,69 @@
+package coscrypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// KeyProvider 用于加密和解密每个对象的数据密钥，可以使用本地的主密钥（MasterKeyProvider），
+// 也可以通过实现该接口使用 KMS 等密钥管理服务
+type KeyProvider interface {
+	// WrapKey 加密数据密钥，返回加密后的数据密钥，以及解密时需要的描述信息（比如主密钥的 ID）。
+	// 描述信息会和加密后的数据密钥一起保存在对象的元数据中
+	WrapKey(ctx context.Context, key []byte) (wrapped []byte, description string, err error)
+	// UnwrapKey 使用 WrapKey 返回的加密后的数据密钥和描述信息解密数据密钥
+	UnwrapKey(ctx context.Context, wrapped []byte, description string) ([]byte, error)
+}
+
+// ErrKeyDescriptionMismatch 对象的数据密钥不是由当前的主密钥加密的
+var ErrKeyDescriptionMismatch = errors.New("coscrypto: data key was wrapped by a different master key")
+
+// MasterKeyProvider 使用本地的主密钥通过 AES-GCM 加密数据密钥
+type MasterKeyProvider struct {
+	aead        cipher.AEAD
+	description string
+}
+
+// NewMasterKeyProvider 创建使用 masterKey 加密数据密钥的 MasterKeyProvider。
+//
+// masterKey 的长度必须是 16、24 或 32 字节（分别对应 AES-128、AES-192 和 AES-256）。
+// description 用于标识主密钥（比如主密钥的 ID 或版本号），不能包含敏感信息，
+// 解密时描述信息不一致会返回 ErrKeyDescriptionMismatch。
+func NewMasterKeyProvider(masterKey []byte, description string) (*MasterKeyProvider, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &MasterKeyProvider{aead: aead, description: description}, nil
+}
+
+// WrapKey implements the KeyProvider interface.
+//
+// 返回的数据格式为 nonce + 密文，描述信息会作为附加数据参与认证
+func (p *MasterKeyProvider) WrapKey(ctx context.Context, key []byte) ([]byte, string, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, "", err
+	}
+	return p.aead.Seal(nonce, nonce, key, []byte(p.description)), p.description, nil
+}
+
+// UnwrapKey implements the KeyProvider interface.
+func (p *MasterKeyProvider) UnwrapKey(ctx context.Context, wrapped []byte, description string) ([]byte, error) {
+	if description != p.description {
+		return nil, ErrKeyDescriptionMismatch
+	}
+	n := p.aead.NonceSize()
+	if len(wrapped) < n {
+		return nil, errors.New("coscrypto: wrapped data key is too short")
+	}
+	return p.aead.Open(nil, wrapped[:n], wrapped[n:], []byte(description))
+}
@@ -0,0 +1,513 @@
+/*
+Package cossync 提供了在本地目录和存储桶中的前缀（目录）之间同步文件的功能。
+
+Syncer 先比较本地目录和 Bucket.ListObjects 列出的 Object，生成同步计划（Plan），再并发执行计划中的上传、下载和删除操作：
+
+	s := cossync.NewSyncer(c, &cossync.Options{Delete: true, Exclude: []string{"*.map"}})
+	plan, err := s.Plan(ctx, cossync.Upload, "./dist", "static/")
+	plan.WriteTo(os.Stdout) // 输出将要执行的操作（dry-run）
+	err = s.Execute(ctx, plan)
+
+文件是否需要同步按照以下规则判断：
+
+ 1. 目标不存在或者大小不同时需要同步
+ 2. ETag 是内容的 MD5 值（非分块上传）时，如果指定了 Options.Checksum 或者源文件的修改时间比目标新，
+    通过比较本地文件的 MD5 值和 ETag 判断是否需要同步
+ 3. 否则按照修改时间判断（精确到秒）：上传时本地文件的修改时间晚于 Object 的 LastModified，
+    下载时 Object 的 LastModified 晚于本地文件的修改时间
+
+下载完成后会将本地文件的修改时间设置为 Object 的 LastModified，以便下次同步时跳过没有变化的文件。
+*/
+package cossync
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/internal/parallel"
+)
+
+// 默认同时执行的操作数量
+const defaultConcurrency = 4
+
+// ErrInvalidDirection 未知的同步方向
+var ErrInvalidDirection = errors.New("cossync: invalid direction")
+
+// Direction 同步的方向
+type Direction int
+
+const (
+	// Upload 将本地目录同步到存储桶
+	Upload Direction = iota
+	// Download 将存储桶同步到本地目录
+	Download
+)
+
+func (d Direction) String() string {
+	if d == Download {
+		return "download"
+	}
+	return "upload"
+}
+
+// ActionType 同步计划中的操作类型
+type ActionType int
+
+const (
+	// ActionSkip 文件没有变化，不需要同步
+	ActionSkip ActionType = iota
+	// ActionUpload 上传本地文件
+	ActionUpload
+	// ActionDownload 下载 Object
+	ActionDownload
+	// ActionDelete 删除目标中多余的文件（上传时删除 Object，下载时删除本地文件）
+	ActionDelete
+)
+
+func (t ActionType) String() string {
+	switch t {
+	case ActionUpload:
+		return "upload"
+	case ActionDownload:
+		return "download"
+	case ActionDelete:
+		return "delete"
+	default:
+		return "skip"
+	}
+}
+
+// Action 同步计划中的一个操作
+type Action struct {
+	Type ActionType
+	// 相对于本地目录和前缀的路径，使用 "/" 分隔
+	Path string
+	// 执行该操作的原因，比如 "new"、"size differs"、"modified"、"stale"
+	Reason string
+	// 本地文件的大小和修改时间，本地文件不存在时为零值
+	LocalSize    int64
+	LocalModTime time.Time
+	// Object 的大小和 LastModified，Object 不存在时为零值
+	RemoteSize    int64
+	RemoteModTime time.Time
+}
+
+// Plan 通过 Syncer.Plan 生成的同步计划
+type Plan struct {
+	Direction Direction
+	LocalDir  string
+	// 存储桶中的前缀，不为空时以 "/" 结尾
+	Prefix string
+	// 按照 Path 排序的操作，包括不需要同步的文件（ActionSkip）
+	Actions []Action
+}
+
+// Key 返回 action 对应的 Object Key
+func (p *Plan) Key(a Action) string {
+	return p.Prefix + a.Path
+}
+
+// LocalPath 返回 action 对应的本地文件路径
+func (p *Plan) LocalPath(a Action) string {
+	return filepath.Join(p.LocalDir, filepath.FromSlash(a.Path))
+}
+
+// Count 返回计划中各类操作的数量
+func (p *Plan) Count() map[ActionType]int {
+	m := map[ActionType]int{}
+	for _, a := range p.Actions {
+		m[a.Type]++
+	}
+	return m
+}
+
+// WriteTo 按行输出计划中需要执行的操作（不包括 ActionSkip），用于 dry-run
+func (p *Plan) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, a := range p.Actions {
+		if a.Type == ActionSkip {
+			continue
+		}
+		n, err := fmt.Fprintln(w, p.describe(a))
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (p *Plan) describe(a Action) string {
+	switch a.Type {
+	case ActionUpload:
+		return fmt.Sprintf("upload: %s -> %s (%s)", p.LocalPath(a), p.Key(a), a.Reason)
+	case ActionDownload:
+		return fmt.Sprintf("download: %s -> %s (%s)", p.Key(a), p.LocalPath(a), a.Reason)
+	case ActionDelete:
+		if p.Direction == Upload {
+			return fmt.Sprintf("delete: %s (%s)", p.Key(a), a.Reason)
+		}
+		return fmt.Sprintf("delete: %s (%s)", p.LocalPath(a), a.Reason)
+	default:
+		return fmt.Sprintf("skip: %s (%s)", a.Path, a.Reason)
+	}
+}
+
+// Options ...
+//
+// NewSyncer 的参数
+type Options struct {
+	// 删除目标中多余的文件（源中不存在并且没有被排除的文件）
+	Delete bool
+	// 只同步匹配这些模式的文件，为空时同步所有文件。
+	// 模式的语法同 path.Match，包含 "/" 的模式匹配相对路径，否则匹配文件名
+	Include []string
+	// 不同步匹配这些模式的文件，优先于 Include
+	Exclude []string
+	// 对于 ETag 是 MD5 值的 Object，总是通过比较 MD5 值判断是否需要同步，而不仅仅是修改时间发生变化时
+	Checksum bool
+	// 同时执行的操作数量，默认值：4
+	Concurrency int
+	// 执行计划时每完成一个操作输出一行日志，为 nil 时不输出
+	Output io.Writer
+	// 上传文件时使用的参数，没有指定 ContentType 时根据文件的扩展名设置
+	UploadOptions *cos.ObjectUploadOptions
+	// 下载文件时使用的参数
+	DownloadOptions *cos.ObjectDownloadOptions
+}
+
+// Syncer 在本地目录和存储桶之间同步文件
+type Syncer struct {
+	client *cos.Client
+	opt    *Options
+}
+
+// NewSyncer 创建一个使用 c 访问存储桶的 Syncer，opt 为 nil 时使用默认参数
+func NewSyncer(c *cos.Client, opt *Options) *Syncer {
+	if opt == nil {
+		opt = &Options{}
+	}
+	return &Syncer{client: c, opt: opt}
+}
+
+// fileInfo 本地文件或 Object 的信息
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// Plan 比较本地目录 localDir 和存储桶中以 prefix 开头的 Object，生成同步计划，不会修改任何文件。
+//
+// prefix 不为空且不以 "/" 结尾时会自动加上 "/"。以 "/" 结尾的 Object（目录）会被忽略。
+func (s *Syncer) Plan(ctx context.Context, direction Direction, localDir, prefix string) (*Plan, error) {
+	if direction != Upload && direction != Download {
+		return nil, ErrInvalidDirection
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	local, err := s.listLocal(localDir, direction == Download)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.listRemote(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{Direction: direction, LocalDir: localDir, Prefix: prefix}
+	src, dst := local, remote
+	if direction == Download {
+		src, dst = remote, local
+	}
+	for name, sf := range src {
+		a := Action{Path: name}
+		df, ok := dst[name]
+		switch {
+		case !ok:
+			a.Type, a.Reason = ActionUpload, "new"
+		case sf.size != df.size:
+			a.Type, a.Reason = ActionUpload, "size differs"
+		default:
+			changed, reason, err := s.changed(p, name, remote[name].etag, sf.modTime, df.modTime)
+			if err != nil {
+				return nil, err
+			}
+			a.Reason = reason
+			if changed {
+				a.Type = ActionUpload
+			}
+		}
+		if a.Type == ActionUpload && direction == Download {
+			a.Type = ActionDownload
+		}
+		p.Actions = append(p.Actions, s.fill(a, local[name], remote[name]))
+	}
+	if s.opt.Delete {
+		for name := range dst {
+			if _, ok := src[name]; !ok {
+				a := Action{Type: ActionDelete, Path: name, Reason: "stale"}
+				p.Actions = append(p.Actions, s.fill(a, local[name], remote[name]))
+			}
+		}
+	}
+	sort.Slice(p.Actions, func(i, j int) bool { return p.Actions[i].Path < p.Actions[j].Path })
+	return p, nil
+}
+
+// changed 判断大小相同的本地文件和 Object 是否需要同步
+func (s *Syncer) changed(p *Plan, name, etag string, srcTime, dstTime time.Time) (bool, string, error) {
+	newer := srcTime.Truncate(time.Second).After(dstTime.Truncate(time.Second))
+	etag = strings.Trim(etag, `"`)
+	if len(etag) == md5.Size*2 && (s.opt.Checksum || newer) {
+		// ETag 是内容的 MD5 值（非分块上传）
+		sum, err := fileMD5(p.LocalPath(Action{Path: name}))
+		if err != nil {
+			return false, "", err
+		}
+		if sum != strings.ToLower(etag) {
+			return true, "checksum differs", nil
+		}
+		return false, "checksum matches", nil
+	}
+	if newer {
+		return true, "modified", nil
+	}
+	return false, "unchanged", nil
+}
+
+func (s *Syncer) fill(a Action, lf, rf *fileInfo) Action {
+	if lf != nil {
+		a.LocalSize, a.LocalModTime = lf.size, lf.modTime
+	}
+	if rf != nil {
+		a.RemoteSize, a.RemoteModTime = rf.size, rf.modTime
+	}
+	return a
+}
+
+// match 判断相对路径 name 是否需要同步
+func (s *Syncer) match(name string) bool {
+	matchAny := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			target := name
+			if !strings.Contains(pattern, "/") {
+				target = path.Base(name)
+			}
+			if ok, _ := path.Match(pattern, target); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if matchAny(s.opt.Exclude) {
+		return false
+	}
+	return len(s.opt.Include) == 0 || matchAny(s.opt.Include)
+}
+
+// listLocal 列出 dir 中的普通文件，dir 不存在且 allowMissing 为 true 时返回空的结果
+func (s *Syncer) listLocal(dir string, allowMissing bool) (map[string]*fileInfo, error) {
+	files := map[string]*fileInfo{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) && allowMissing {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if name := filepath.ToSlash(rel); s.match(name) {
+			files[name] = &fileInfo{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// listRemote 列出以 prefix 开头的 Object。
+// Object 相对于 prefix 的路径不是合法的本地相对路径（比如包含 ..）时返回错误，避免下载时写入 localDir 之外的文件
+func (s *Syncer) listRemote(ctx context.Context, prefix string) (map[string]*fileInfo, error) {
+	files := map[string]*fileInfo{}
+	pager := s.client.Bucket.ListObjects(ctx, &cos.BucketGetOptions{Prefix: prefix})
+	for pager.Next() {
+		o := pager.Object()
+		name := strings.TrimPrefix(o.Key, prefix)
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		if !validName(name) {
+			return nil, fmt.Errorf("cossync: invalid object key %q: %q is not a valid relative path", o.Key, name)
+		}
+		if !s.match(name) {
+			continue
+		}
+		modTime, _ := time.Parse(time.RFC3339, o.LastModified)
+		files[name] = &fileInfo{size: int64(o.Size), modTime: modTime, etag: o.ETag}
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// validName 判断 name 是否可以作为 localDir 中的相对路径：不能以 / 开头，不能包含 .、.. 以及空的路径元素，
+// 在使用 \ 作为路径分隔符的系统中也不能包含 \
+func validName(name string) bool {
+	if filepath.Separator != '/' && strings.ContainsRune(name, filepath.Separator) {
+		return false
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute 并发执行计划中的操作，最多同时执行 opt.Concurrency 个操作。
+// 出现错误时不再执行剩余的操作，并返回第一个错误。
+func (s *Syncer) Execute(ctx context.Context, p *Plan) error {
+	var actions []Action
+	for _, a := range p.Actions {
+		if a.Type != ActionSkip {
+			actions = append(actions, a)
+		}
+	}
+	concurrency := s.opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	var mu sync.Mutex
+	return parallel.Run(ctx, len(actions), concurrency, func(ctx context.Context, i int) error {
+		a := actions[i]
+		if err := s.execute(ctx, p, a); err != nil {
+			return fmt.Errorf("cossync: %s: %v", p.describe(a), err)
+		}
+		if s.opt.Output != nil {
+			mu.Lock()
+			fmt.Fprintln(s.opt.Output, p.describe(a))
+			mu.Unlock()
+		}
+		return nil
+	})
+}
+
+// Sync 生成同步计划并执行，返回生成的计划
+func (s *Syncer) Sync(ctx context.Context, direction Direction, localDir, prefix string) (*Plan, error) {
+	p, err := s.Plan(ctx, direction, localDir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return p, s.Execute(ctx, p)
+}
+
+func (s *Syncer) execute(ctx context.Context, p *Plan, a Action) error {
+	switch {
+	case a.Type == ActionUpload:
+		return s.upload(ctx, p.LocalPath(a), p.Key(a))
+	case a.Type == ActionDownload:
+		return s.download(ctx, p.Key(a), p.LocalPath(a), a.RemoteModTime)
+	case a.Type == ActionDelete && p.Direction == Upload:
+		_, err := s.client.Object.Delete(ctx, p.Key(a))
+		return err
+	case a.Type == ActionDelete:
+		return os.Remove(p.LocalPath(a))
+	}
+	return nil
+}
+
+func (s *Syncer) upload(ctx context.Context, filename, key string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var opt cos.ObjectUploadOptions
+	if s.opt.UploadOptions != nil {
+		opt = *s.opt.UploadOptions
+	}
+	var header cos.ObjectPutHeaderOptions
+	if opt.ObjectPutHeaderOptions != nil {
+		header = *opt.ObjectPutHeaderOptions
+	}
+	if header.ContentType == "" {
+		header.ContentType = mime.TypeByExtension(path.Ext(key))
+	}
+	opt.ObjectPutHeaderOptions = &header
+	// 多个文件共用同一个断点续传文件没有意义
+	opt.CheckpointFile = ""
+	_, _, err = s.client.Object.Upload(ctx, key, f, &opt)
+	return err
+}
+
+// download 先下载到同一目录下的临时文件，下载成功后再重命名，避免下载失败时破坏原有的文件
+func (s *Syncer) download(ctx context.Context, key, filename string, modTime time.Time) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(filename)+".cossync")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	var opt cos.ObjectDownloadOptions
+	if s.opt.DownloadOptions != nil {
+		opt = *s.opt.DownloadOptions
+	}
+	opt.CheckpointFile = ""
+	_, err = s.client.Object.Download(ctx, key, f, &opt)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		if err := os.Chtimes(tmp, modTime, modTime); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, filename)
+}
+
+func fileMD5(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,300 @@
+package cossync
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+	"github.com/mozillazg/go-cos/costest"
+)
+
+const testBucket = "test-1250000000"
+
+func setup(t *testing.T) (*costest.Server, *cos.Client, string) {
+	srv := costest.NewServer()
+	srv.CreateBucket(testBucket)
+	dir, err := ioutil.TempDir("", "cossync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, srv.NewClient(testBucket), dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func putObjects(t *testing.T, c *cos.Client, objects map[string]string) {
+	for key, content := range objects {
+		if _, err := c.Object.Put(context.Background(), key, strings.NewReader(content), nil); err != nil {
+			t.Fatalf("Object.Put returned error: %v", err)
+		}
+	}
+}
+
+func getObject(t *testing.T, c *cos.Client, key string) (string, *cos.Response) {
+	resp, err := c.Object.Get(context.Background(), key, nil)
+	if err != nil {
+		t.Fatalf("Object.Get(%s) returned error: %v", key, err)
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	return string(b), resp
+}
+
+// actions 返回计划中各个操作的类型和原因
+func actions(p *Plan) map[string]string {
+	m := map[string]string{}
+	for _, a := range p.Actions {
+		m[a.Path] = a.Type.String() + " " + a.Reason
+	}
+	return m
+}
+
+func TestSyncer_upload(t *testing.T) {
+	srv, c, dir := setup(t)
+	defer srv.Close()
+	defer os.RemoveAll(dir)
+
+	writeFiles(t, dir, map[string]string{
+		"index.html":    "<h1>hello</h1>",
+		"js/app.js":     "console.log(1)",
+		"js/app.js.map": "{}",
+	})
+	putObjects(t, c, map[string]string{
+		"static/old.txt":     "old",
+		"static/keep.js.map": "{}",
+		"other/a.txt":        "a",
+	})
+
+	var out bytes.Buffer
+	s := NewSyncer(c, &Options{Delete: true, Exclude: []string{"*.map"}, Output: &out})
+	p, err := s.Sync(context.Background(), Upload, dir, "static")
+	if err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+	want := map[string]string{
+		"index.html": "upload new",
+		"js/app.js":  "upload new",
+		"old.txt":    "delete stale",
+	}
+	if got := actions(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("Syncer.Plan returned %v, want %v", got, want)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 3 {
+		t.Errorf("Syncer.Execute output %d lines, want 3:\n%s", n, out.String())
+	}
+
+	if content, resp := getObject(t, c, "static/js/app.js"); content != "console.log(1)" ||
+		!strings.Contains(resp.Header.Get("Content-Type"), "javascript") {
+		t.Errorf("static/js/app.js: %q, Content-Type %s", content, resp.Header.Get("Content-Type"))
+	}
+	// 被排除的文件和前缀之外的 Object 不会被删除
+	for _, key := range []string{"static/keep.js.map", "other/a.txt"} {
+		getObject(t, c, key)
+	}
+	if _, err := c.Object.Head(context.Background(), "static/old.txt", nil); err == nil {
+		t.Error("static/old.txt should be deleted")
+	}
+	if _, err := c.Object.Head(context.Background(), "static/js/app.js.map", nil); err == nil {
+		t.Error("static/js/app.js.map should be excluded")
+	}
+
+	// 再次同步时跳过没有变化的文件
+	p, err = s.Plan(context.Background(), Upload, dir, "static/")
+	if err != nil {
+		t.Fatalf("Syncer.Plan returned error: %v", err)
+	}
+	if got := p.Count(); got[ActionSkip] != 2 || len(p.Actions) != 2 {
+		t.Errorf("Syncer.Plan returned %v", actions(p))
+	}
+}
+
+func TestSyncer_uploadChanged(t *testing.T) {
+	srv, c, dir := setup(t)
+	defer srv.Close()
+	defer os.RemoveAll(dir)
+
+	writeFiles(t, dir, map[string]string{
+		"same.txt":    "hello",
+		"changed.txt": "hello",
+		"touched.txt": "hello",
+		"size.txt":    "hello",
+	})
+	s := NewSyncer(c, nil)
+	if _, err := s.Sync(context.Background(), Upload, dir, ""); err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	writeFiles(t, dir, map[string]string{
+		"changed.txt": "world",
+		"size.txt":    "hello world",
+	})
+	for _, name := range []string{"changed.txt", "touched.txt"} {
+		os.Chtimes(filepath.Join(dir, name), future, future)
+	}
+	p, err := s.Plan(context.Background(), Upload, dir, "")
+	if err != nil {
+		t.Fatalf("Syncer.Plan returned error: %v", err)
+	}
+	want := map[string]string{
+		"same.txt":    "skip unchanged",
+		"changed.txt": "upload checksum differs",
+		"touched.txt": "skip checksum matches",
+		"size.txt":    "upload size differs",
+	}
+	if got := actions(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("Syncer.Plan returned %v, want %v", got, want)
+	}
+
+	// Checksum 为 true 时修改时间没有变化也会比较 MD5 值
+	writeFiles(t, dir, map[string]string{"same.txt": "HELLO"})
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "same.txt"), past, past)
+	p, _ = s.Plan(context.Background(), Upload, dir, "")
+	if got := actions(p)["same.txt"]; got != "skip unchanged" {
+		t.Errorf("same.txt: %s, want skip unchanged", got)
+	}
+	p, _ = NewSyncer(c, &Options{Checksum: true}).Plan(context.Background(), Upload, dir, "")
+	if got := actions(p)["same.txt"]; got != "upload checksum differs" {
+		t.Errorf("same.txt: %s, want upload checksum differs", got)
+	}
+}
+
+func TestSyncer_download(t *testing.T) {
+	srv, c, dir := setup(t)
+	defer srv.Close()
+	defer os.RemoveAll(dir)
+
+	putObjects(t, c, map[string]string{
+		"backup/a.txt":     "a",
+		"backup/dir/b.txt": "b",
+		"backup/dir/":      "",
+		"backup/c.log":     "c",
+	})
+	writeFiles(t, dir, map[string]string{"stale.txt": "stale"})
+
+	s := NewSyncer(c, &Options{Delete: true, Include: []string{"*.txt"}, Concurrency: 2})
+	p, err := s.Sync(context.Background(), Download, dir, "backup")
+	if err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+	want := map[string]string{
+		"a.txt":     "download new",
+		"dir/b.txt": "download new",
+		"stale.txt": "delete stale",
+	}
+	if got := actions(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("Syncer.Plan returned %v, want %v", got, want)
+	}
+	for name, content := range map[string]string{"a.txt": "a", "dir/b.txt": "b"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil || string(b) != content {
+			t.Errorf("%s: %q, %v", name, b, err)
+		}
+	}
+	for _, name := range []string{"stale.txt", "c.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist", name)
+		}
+	}
+
+	// 下载的文件的修改时间和 Object 一致，再次同步时跳过
+	p, _ = s.Plan(context.Background(), Download, dir, "backup")
+	if got := p.Count(); got[ActionSkip] != 2 || len(p.Actions) != 2 {
+		t.Errorf("Syncer.Plan returned %v", actions(p))
+	}
+
+	// 本地目录不存在时创建
+	sub := filepath.Join(dir, "new")
+	if _, err := s.Sync(context.Background(), Download, sub, "backup/dir"); err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(sub, "b.txt")); string(b) != "b" {
+		t.Errorf("b.txt: %q", b)
+	}
+}
+
+func TestSyncer_downloadInvalidKey(t *testing.T) {
+	srv, c, dir := setup(t)
+	defer srv.Close()
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, "local")
+	putObjects(t, c, map[string]string{
+		"backup/a.txt":         "a",
+		"backup/../escape.txt": "escape",
+		"backup/dir/../b.txt":  "b",
+		"backup/dir//c.txt":    "c",
+	})
+	for _, key := range []string{"backup/../escape.txt", "backup/dir/../b.txt", "backup/dir//c.txt"} {
+		if got, _ := getObject(t, c, key); got == "" {
+			t.Fatalf("Object %s was not created", key)
+		}
+	}
+
+	s := NewSyncer(c, nil)
+	if _, err := s.Sync(context.Background(), Download, local, "backup/"); err == nil || !strings.Contains(err.Error(), "invalid object key") {
+		t.Fatalf("Syncer.Sync returned error %v, want invalid object key", err)
+	}
+	for _, name := range []string{"escape.txt", "local/a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist", name)
+		}
+	}
+}
+
+func TestPlan_WriteTo(t *testing.T) {
+	p := &Plan{
+		Direction: Upload,
+		LocalDir:  "dist",
+		Prefix:    "static/",
+		Actions: []Action{
+			{Type: ActionUpload, Path: "a.txt", Reason: "new"},
+			{Type: ActionSkip, Path: "b.txt", Reason: "unchanged"},
+			{Type: ActionDelete, Path: "c.txt", Reason: "stale"},
+		},
+	}
+	var buf bytes.Buffer
+	p.WriteTo(&buf)
+	want := "upload: " + filepath.Join("dist", "a.txt") + " -> static/a.txt (new)\n" +
+		"delete: static/c.txt (stale)\n"
+	if buf.String() != want {
+		t.Errorf("Plan.WriteTo wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSyncer_match(t *testing.T) {
+	s := NewSyncer(nil, &Options{
+		Include: []string{"*.go", "docs/*"},
+		Exclude: []string{"*_test.go", "vendor/*/*"},
+	})
+	for name, want := range map[string]bool{
+		"main.go":            true,
+		"pkg/a.go":           true,
+		"pkg/a_test.go":      false,
+		"docs/index.md":      true,
+		"docs/sub/index.md":  false,
+		"README.md":          false,
+		"vendor/x/y.go":      false,
+		"pkg/docs/readme.md": false,
+	} {
+		if got := s.match(name); got != want {
+			t.Errorf("match(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
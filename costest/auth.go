@@ -0,0 +1,127 @@
+package costest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkAuthorization 校验请求的签名，签名可以在 Authorization 头部中，也可以在 URL 的 sign 参数中（预签名授权 URL）。
+//
+// 没有签名的请求为匿名请求，返回 false 和 nil，由各个 API 根据 ACL 判断是否允许访问。
+//
+// https://cloud.tencent.com/document/product/436/7778
+func (s *Server) checkAuthorization(r *http.Request) (bool, *apiError) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		auth = r.URL.Query().Get("sign")
+	}
+	if auth == "" {
+		return false, nil
+	}
+	if s.SecretID == "" {
+		return true, nil
+	}
+
+	params := map[string]string{}
+	for _, kv := range strings.Split(auth, "&") {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			continue
+		}
+		params[kv[:i]] = kv[i+1:]
+	}
+	if params["q-sign-algorithm"] != "sha1" || params["q-ak"] != s.SecretID {
+		return false, errAccessDenied
+	}
+	if !inTimeRange(params["q-sign-time"], time.Now()) || !inTimeRange(params["q-key-time"], time.Now()) {
+		return false, &apiError{http.StatusForbidden, "AccessDenied", "Request has expired."}
+	}
+
+	headers := map[string][]string{}
+	for _, name := range splitList(params["q-header-list"]) {
+		if name == "host" {
+			headers[name] = []string{r.Host}
+			continue
+		}
+		headers[name] = r.Header[http.CanonicalHeaderKey(name)]
+	}
+	query := r.URL.Query()
+	values := map[string][]string{}
+	for _, name := range splitList(params["q-url-param-list"]) {
+		for k, v := range query {
+			if strings.ToLower(k) == name {
+				values[name] = append(values[name], v...)
+			}
+		}
+	}
+
+	formatString := fmt.Sprintf("%s\n%s\n%s\n%s\n", strings.ToLower(r.Method), r.URL.Path,
+		formatValues(values), formatValues(headers))
+	stringToSign := fmt.Sprintf("sha1\n%s\n%x\n", params["q-sign-time"], sha1.Sum([]byte(formatString)))
+	signKey := fmt.Sprintf("%x", hmacSHA1(s.SecretKey, params["q-key-time"]))
+	signature := fmt.Sprintf("%x", hmacSHA1(signKey, stringToSign))
+	if !hmac.Equal([]byte(signature), []byte(params["q-signature"])) {
+		return false, errSignatureDoesNotMatch
+	}
+	return true, nil
+}
+
+func hmacSHA1(key, msg string) []byte {
+	h := hmac.New(sha1.New, []byte(key))
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+// inTimeRange 判断 t 是否在 "<start>;<end>" 格式的时间范围内
+func inTimeRange(s string, t time.Time) bool {
+	parts := strings.Split(s, ";")
+	if len(parts) != 2 {
+		return false
+	}
+	start, err1 := strconv.ParseInt(parts[0], 10, 64)
+	end, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	// 允许客户端和服务端之间存在少量的时钟误差
+	now := t.Unix()
+	return start-60 <= now && now <= end
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ";")
+}
+
+// formatValues 生成 FormatParameters 或 FormatHeaders: <key1>=<value1>&<key2>=<value2>
+func formatValues(vs map[string][]string) string {
+	var keys []string
+	for k := range vs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		items := append([]string(nil), vs[k]...)
+		sort.Strings(items)
+		for _, v := range items {
+			pairs = append(pairs, camSafeURLEncode(k)+"="+camSafeURLEncode(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// camSafeURLEncode 和 cos 包的 camSafeURLEncode 一样，只保留 A-Za-z0-9-_.~ 不编码
+func camSafeURLEncode(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
@@ -0,0 +1,503 @@
+package costest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+)
+
+// bucket 内存中的 Bucket
+type bucket struct {
+	name    string
+	created time.Time
+	acl     *acl
+	objects map[string]*object
+	uploads map[string]*upload
+	// 版本控制的状态
+	versioning string
+	// 日志存储配置，Server 不会生成访问日志
+	logging *cos.BucketLoggingEnabled
+	// 以原始请求 body 保存的 Bucket 配置，key 为子资源的名称，比如 cors
+	configs map[string][]byte
+}
+
+func newBucket(name string) *bucket {
+	return &bucket{
+		name:    name,
+		created: time.Now(),
+		acl:     &acl{},
+		objects: map[string]*object{},
+		uploads: map[string]*upload{},
+		configs: map[string][]byte{},
+	}
+}
+
+// bucketConfigs 通过 PUT/GET/DELETE 原样保存和返回配置的子资源，以及配置不存在时返回的错误码
+var bucketConfigs = map[string]string{
+	"cors":        "NoSuchCORSConfiguration",
+	"tagging":     "NoSuchTagSet",
+	"lifecycle":   "NoSuchLifecycleConfiguration",
+	"policy":      "NoSuchPolicy",
+	"replication": "ReplicationConfigurationnotFoundError",
+	"website":     "NoSuchWebsiteConfiguration",
+	"encryption":  "NoSuchEncryptionConfiguration",
+}
+
+func (s *Server) serveBucket(r *request) {
+	b := s.buckets[r.bucketName]
+	if r.Method == http.MethodPut && len(r.URL.Query()) == 0 {
+		s.createBucket(r, b)
+		return
+	}
+	if b == nil {
+		r.writeError(errNoSuchBucket)
+		return
+	}
+	if r.Method == http.MethodOptions {
+		s.preflight(r, b)
+		return
+	}
+	if r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		s.postObject(r, b)
+		return
+	}
+
+	// 除了 Bucket 的读写之外的操作都需要签名
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+	write := r.Method == http.MethodPost && r.hasQuery("delete")
+	if !r.authorized && !(read && len(r.URL.Query()) == 0 && b.acl.allowRead()) &&
+		!(write && b.acl.allowWrite()) {
+		r.writeError(errAccessDenied)
+		return
+	}
+
+	for name, code := range bucketConfigs {
+		if r.hasQuery(name) {
+			s.serveBucketConfig(r, b, name, code)
+			return
+		}
+	}
+
+	switch {
+	case r.hasQuery("acl"):
+		s.serveACL(r, b.acl)
+	case r.hasQuery("versioning"):
+		s.serveVersioning(r, b)
+	case r.hasQuery("logging"):
+		s.serveLogging(r, b)
+	case r.hasQuery("versions") && r.Method == http.MethodGet:
+		s.listObjectVersions(r, b)
+	case r.hasQuery("location") && r.Method == http.MethodGet:
+		r.writeXML(http.StatusOK, &cos.BucketGetLocationResult{Location: s.Region})
+	case r.hasQuery("uploads") && r.Method == http.MethodGet:
+		s.listMultipartUploads(r, b)
+	case r.hasQuery("delete") && r.Method == http.MethodPost:
+		s.deleteMulti(r, b)
+	case r.Method == http.MethodGet:
+		s.listObjects(r, b)
+	case r.Method == http.MethodHead:
+		r.w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete:
+		if len(b.objects) > 0 || len(b.uploads) > 0 {
+			r.writeError(errBucketNotEmpty)
+			return
+		}
+		delete(s.buckets, b.name)
+		r.w.WriteHeader(http.StatusNoContent)
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+func (s *Server) createBucket(r *request, b *bucket) {
+	if !r.authorized {
+		r.writeError(errAccessDenied)
+		return
+	}
+	if b != nil {
+		r.writeError(errBucketAlreadyExists)
+		return
+	}
+	b = newBucket(r.bucketName)
+	b.acl.setHeader(r.Header)
+	s.buckets[b.name] = b
+	r.w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) serveBucketConfig(r *request, b *bucket, name, code string) {
+	switch r.Method {
+	case http.MethodPut:
+		if name == "replication" && b.versioning != cos.VersioningStatusEnabled {
+			r.writeError(&apiError{http.StatusBadRequest, "InvalidRequest", "Versioning must be enabled on the bucket."})
+			return
+		}
+		body, err := readBody(r)
+		if err != nil {
+			r.writeError(err)
+			return
+		}
+		b.configs[name] = body
+		r.w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		body, ok := b.configs[name]
+		if !ok {
+			r.writeError(&apiError{http.StatusNotFound, code, "The " + name + " configuration does not exist."})
+			return
+		}
+		contentType := "application/xml"
+		if name == "policy" {
+			contentType = "application/json"
+		}
+		r.writeRaw(contentType, body)
+	case http.MethodDelete:
+		delete(b.configs, name)
+		r.w.WriteHeader(http.StatusNoContent)
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+// readBody 读取请求 body，并在请求带有 Content-MD5 头部时校验 body 的 MD5
+func readBody(r *request) ([]byte, *apiError) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, "IncompleteBody", err.Error()}
+	}
+	if v := r.Header.Get("Content-MD5"); v != "" {
+		sum := md5.Sum(body)
+		if v != base64.StdEncoding.EncodeToString(sum[:]) {
+			return nil, errBadDigest
+		}
+	}
+	return body, nil
+}
+
+// readXML 读取并解析 XML 格式的请求 body
+func readXML(r *request, v interface{}) *apiError {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	if xml.Unmarshal(body, v) != nil {
+		return errMalformedXML
+	}
+	return nil
+}
+
+// listObjects 实现 Bucket.Get
+func (s *Server) listObjects(r *request, b *bucket) {
+	q := r.URL.Query()
+	prefix, delimiter, marker := q.Get("prefix"), q.Get("delimiter"), q.Get("marker")
+	maxKeys := 1000
+	if v := q.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 1000 {
+			r.writeError(errInvalidArgument)
+			return
+		}
+		maxKeys = n
+	}
+	encode := func(s string) string { return s }
+	if v := q.Get("encoding-type"); v == "url" {
+		encode = camSafeURLEncode
+	} else if v != "" {
+		r.writeError(errInvalidArgument)
+		return
+	}
+
+	res := &cos.BucketGetResult{
+		Name:         b.name,
+		Prefix:       encode(prefix),
+		Marker:       encode(marker),
+		Delimiter:    encode(delimiter),
+		MaxKeys:      maxKeys,
+		EncodingType: q.Get("encoding-type"),
+	}
+	var last string
+	seen := map[string]bool{}
+	for _, key := range sortedKeys(b.objects) {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		commonPrefix := ""
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				commonPrefix = key[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		if commonPrefix != "" && (seen[commonPrefix] || commonPrefix <= marker) {
+			continue
+		}
+		if len(res.Contents)+len(res.CommonPrefixes) >= maxKeys {
+			res.IsTruncated = true
+			break
+		}
+		if commonPrefix != "" {
+			seen[commonPrefix] = true
+			res.CommonPrefixes = append(res.CommonPrefixes, encode(commonPrefix))
+			last = commonPrefix
+			continue
+		}
+		o := b.objects[key]
+		res.Contents = append(res.Contents, cos.Object{
+			Key:          encode(key),
+			ETag:         o.etag,
+			Size:         len(o.data),
+			LastModified: o.modTime.UTC().Format(time.RFC3339),
+			StorageClass: o.storageClass(),
+			Owner:        &cos.Owner{ID: "1250000000"},
+		})
+		last = key
+	}
+	// 和 COS 一样，只有指定了 delimiter 时才会返回 NextMarker
+	if res.IsTruncated && delimiter != "" {
+		res.NextMarker = encode(last)
+	}
+	r.writeXML(http.StatusOK, res)
+}
+
+// serveVersioning 实现 Bucket.PutVersioning 和 Bucket.GetVersioning，只保存版本控制的状态
+func (s *Server) serveVersioning(r *request, b *bucket) {
+	switch r.Method {
+	case http.MethodPut:
+		var opt cos.BucketPutVersioningOptions
+		if err := readXML(r, &opt); err != nil {
+			r.writeError(err)
+			return
+		}
+		if opt.Status != cos.VersioningStatusEnabled && opt.Status != cos.VersioningStatusSuspended {
+			r.writeError(errMalformedXML)
+			return
+		}
+		b.versioning = opt.Status
+		r.w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		r.writeXML(http.StatusOK, &cos.BucketGetVersioningResult{Status: b.versioning})
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveLogging(r *request, b *bucket) {
+	switch r.Method {
+	case http.MethodPut:
+		var opt cos.BucketPutLoggingOptions
+		if err := readXML(r, &opt); err != nil {
+			r.writeError(err)
+			return
+		}
+		if opt.LoggingEnabled != nil && s.buckets[opt.LoggingEnabled.TargetBucket] == nil {
+			r.writeError(&apiError{http.StatusBadRequest, "InvalidTargetBucketForLogging", "The target bucket for logging does not exist."})
+			return
+		}
+		b.logging = opt.LoggingEnabled
+		r.w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		r.writeXML(http.StatusOK, &cos.BucketGetLoggingResult{LoggingEnabled: b.logging})
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+// listObjectVersions 实现 Bucket.ListObjectVersions。
+// Server 不保留 Object 的历史版本，每个 Object 只返回版本 ID 为 null 的当前版本
+func (s *Server) listObjectVersions(r *request, b *bucket) {
+	q := r.URL.Query()
+	prefix, keyMarker := q.Get("prefix"), q.Get("key-marker")
+	maxKeys := 1000
+	if v := q.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 1000 {
+			r.writeError(errInvalidArgument)
+			return
+		}
+		maxKeys = n
+	}
+	res := &cos.BucketListObjectVersionsResult{
+		Name:      b.name,
+		Prefix:    prefix,
+		KeyMarker: keyMarker,
+		MaxKeys:   maxKeys,
+	}
+	for _, key := range sortedKeys(b.objects) {
+		if !strings.HasPrefix(key, prefix) || key <= keyMarker {
+			continue
+		}
+		if len(res.Versions) >= maxKeys {
+			res.IsTruncated = true
+			break
+		}
+		o := b.objects[key]
+		res.Versions = append(res.Versions, cos.ObjectVersion{
+			Key:          key,
+			VersionID:    "null",
+			IsLatest:     true,
+			LastModified: o.modTime.UTC().Format(time.RFC3339),
+			ETag:         o.etag,
+			Size:         len(o.data),
+			StorageClass: o.storageClass(),
+		})
+		res.NextKeyMarker = key
+		res.NextVersionIDMarker = "null"
+	}
+	if !res.IsTruncated {
+		res.NextKeyMarker, res.NextVersionIDMarker = "", ""
+	}
+	r.writeXML(http.StatusOK, res)
+}
+
+// deleteMulti 实现 Object.DeleteMulti
+func (s *Server) deleteMulti(r *request, b *bucket) {
+	var opt cos.ObjectDeleteMultiOptions
+	if err := readXML(r, &opt); err != nil {
+		r.writeError(err)
+		return
+	}
+	if len(opt.Objects) > 1000 {
+		r.writeError(errMalformedXML)
+		return
+	}
+	res := &cos.ObjectDeleteMultiResult{}
+	for _, o := range opt.Objects {
+		delete(b.objects, o.Key)
+		if !opt.Quiet {
+			res.DeletedObjects = append(res.DeletedObjects, cos.Object{Key: o.Key})
+		}
+	}
+	r.writeXML(http.StatusOK, res)
+}
+
+// preflight 根据 Bucket 的 CORS 配置响应 OPTIONS 请求
+func (s *Server) preflight(r *request, b *bucket) {
+	origin := r.Header.Get("Origin")
+	method := r.Header.Get("Access-Control-Request-Method")
+	var conf cos.BucketGetCORSResult
+	if body, ok := b.configs["cors"]; ok {
+		xml.Unmarshal(body, &conf)
+	}
+	for _, rule := range conf.Rules {
+		if !matchAny(rule.AllowedOrigins, origin) || !matchAny(rule.AllowedMethods, method) {
+			continue
+		}
+		h := r.w.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ","))
+		if len(rule.AllowedHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(rule.AllowedHeaders, ","))
+		}
+		if len(rule.ExposeHeaders) > 0 {
+			h.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ","))
+		}
+		if rule.MaxAgeSeconds > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+		}
+		r.w.WriteHeader(http.StatusOK)
+		return
+	}
+	r.writeError(&apiError{http.StatusForbidden, "AccessForbidden", "CORSResponse: This CORS request is not allowed."})
+}
+
+// matchAny 判断 s 是否匹配 patterns 中的某一项，pattern 中可以包含一个通配符 *
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if i := strings.Index(p, "*"); i >= 0 {
+			if len(s) >= len(p)-1 && strings.HasPrefix(s, p[:i]) && strings.HasSuffix(s, p[i+1:]) {
+				return true
+			}
+		} else if strings.EqualFold(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*bucket:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*object:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// acl Bucket 或 Object 的访问权限
+type acl struct {
+	// x-cos-acl 头部指定的预设 ACL，比如 public-read
+	canned string
+	// 通过 XML 设置的 ACL
+	policy []byte
+}
+
+func (a *acl) allowRead() bool {
+	return a.canned == "public-read" || a.canned == "public-read-write"
+}
+
+func (a *acl) allowWrite() bool {
+	return a.canned == "public-read-write"
+}
+
+func (a *acl) setHeader(h http.Header) {
+	a.canned = h.Get("x-cos-acl")
+	a.policy = nil
+}
+
+// serveACL 实现 Bucket 和 Object 的 PutACL 和 GetACL
+func (s *Server) serveACL(r *request, a *acl) {
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readBody(r)
+		if err != nil {
+			r.writeError(err)
+			return
+		}
+		a.setHeader(r.Header)
+		if len(bytes.TrimSpace(body)) > 0 {
+			var policy cos.ACLXml
+			if xml.Unmarshal(body, &policy) != nil {
+				r.writeError(errMalformedXML)
+				return
+			}
+			a.policy = body
+		}
+		r.w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		if a.policy != nil {
+			r.writeRaw("application/xml", a.policy)
+			return
+		}
+		owner := &cos.Owner{
+			ID:          "qcs::cam::uin/100000000001:uin/100000000001",
+			DisplayName: "qcs::cam::uin/100000000001:uin/100000000001",
+		}
+		res := &cos.ACLXml{
+			Owner: owner,
+			AccessControlList: []cos.ACLGrant{{
+				Grantee: &cos.ACLGrantee{
+					Type:        "CanonicalUser",
+					ID:          owner.ID,
+					DisplayName: owner.DisplayName,
+				},
+				Permission: cos.PermissionFullControl,
+			}},
+		}
+		r.writeXML(http.StatusOK, res)
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
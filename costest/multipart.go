@@ -0,0 +1,295 @@
+package costest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+)
+
+const (
+	// 除最后一个分块外，每个分块的最小大小
+	minPartSize   = 1024 * 1024
+	maxPartNumber = 10000
+)
+
+// upload 进行中的分块上传
+type upload struct {
+	id        string
+	key       string
+	header    http.Header
+	initiated time.Time
+	parts     map[int]*part
+}
+
+type part struct {
+	data    []byte
+	etag    string
+	modTime time.Time
+}
+
+// initiateMultipartUpload 实现 Object.InitiateMultipartUpload
+func (s *Server) initiateMultipartUpload(r *request, b *bucket) {
+	u := &upload{
+		id:        fmt.Sprintf("%d%04d", time.Now().Unix(), s.requestID),
+		key:       r.key,
+		header:    http.Header{},
+		initiated: time.Now(),
+		parts:     map[int]*part{},
+	}
+	for k, v := range r.Header {
+		u.header[k] = append([]string(nil), v...)
+	}
+	b.uploads[u.id] = u
+	r.writeXML(http.StatusOK, &cos.InitiateMultipartUploadResult{
+		Bucket:   b.name,
+		Key:      u.key,
+		UploadID: u.id,
+	})
+}
+
+// serveUpload 处理带 uploadId 参数的请求：UploadPart、ListParts、CompleteMultipartUpload 和 AbortMultipartUpload
+func (s *Server) serveUpload(r *request, b *bucket) {
+	u := b.uploads[r.URL.Query().Get("uploadId")]
+	if u == nil || u.key != r.key {
+		r.writeError(errNoSuchUpload)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		s.uploadPart(r, u)
+	case http.MethodGet:
+		s.listParts(r, b, u)
+	case http.MethodPost:
+		s.completeMultipartUpload(r, b, u)
+	case http.MethodDelete:
+		delete(b.uploads, u.id)
+		r.w.WriteHeader(http.StatusNoContent)
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+func (s *Server) uploadPart(r *request, u *upload) {
+	n, e := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if e != nil || n < 1 || n > maxPartNumber {
+		r.writeError(errInvalidArgument)
+		return
+	}
+	if r.Header.Get("x-cos-copy-source") != "" {
+		s.uploadPartCopy(r, u, n)
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		r.writeError(err)
+		return
+	}
+	p := &part{
+		data:    body,
+		etag:    fmt.Sprintf(`"%x"`, md5.Sum(body)),
+		modTime: time.Now(),
+	}
+	u.parts[n] = p
+	r.w.Header().Set("ETag", p.etag)
+	r.w.WriteHeader(http.StatusOK)
+}
+
+// uploadPartCopy 实现 Object.UploadPartCopy，支持 x-cos-copy-source-range 头部
+func (s *Server) uploadPartCopy(r *request, u *upload, n int) {
+	src, err := s.copySource(r)
+	if err != nil {
+		r.writeError(err)
+		return
+	}
+	if checkPreconditions(r.Header, "x-cos-copy-source-", src) != http.StatusOK {
+		r.writeError(errPreconditionFailed)
+		return
+	}
+	data := src.data
+	if v := r.Header.Get("x-cos-copy-source-range"); v != "" {
+		start, end, partial, err := parseRange(v, len(data))
+		if err != nil || !partial {
+			r.writeError(errInvalidArgument)
+			return
+		}
+		data = data[start : end+1]
+	}
+	p := &part{
+		data:    append([]byte(nil), data...),
+		etag:    fmt.Sprintf(`"%x"`, md5.Sum(data)),
+		modTime: time.Now(),
+	}
+	u.parts[n] = p
+	r.writeXML(http.StatusOK, &cos.CopyPartResult{
+		ETag:         p.etag,
+		LastModified: p.modTime.UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *Server) listParts(r *request, b *bucket, u *upload) {
+	q := r.URL.Query()
+	maxParts, marker := 1000, 0
+	if v := q.Get("max-parts"); v != "" {
+		n, e := strconv.Atoi(v)
+		if e != nil || n < 0 {
+			r.writeError(errInvalidArgument)
+			return
+		}
+		maxParts = n
+	}
+	if v := q.Get("part-number-marker"); v != "" {
+		n, e := strconv.Atoi(v)
+		if e != nil || n < 0 {
+			r.writeError(errInvalidArgument)
+			return
+		}
+		marker = n
+	}
+
+	res := &cos.ObjectListPartsResult{
+		Bucket:           b.name,
+		Key:              u.key,
+		UploadID:         u.id,
+		StorageClass:     cos.StorageClassStandard,
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+	for _, n := range sortedParts(u) {
+		if n <= marker {
+			continue
+		}
+		if len(res.Parts) >= maxParts {
+			res.IsTruncated = true
+			break
+		}
+		p := u.parts[n]
+		res.Parts = append(res.Parts, cos.Object{
+			PartNumber:   n,
+			ETag:         p.etag,
+			Size:         len(p.data),
+			LastModified: p.modTime.UTC().Format(time.RFC3339),
+		})
+		res.NextPartNumberMarker = n
+	}
+	r.writeXML(http.StatusOK, res)
+}
+
+func (s *Server) completeMultipartUpload(r *request, b *bucket, u *upload) {
+	var opt cos.CompleteMultipartUploadOptions
+	if err := readXML(r, &opt); err != nil {
+		r.writeError(err)
+		return
+	}
+	if len(opt.Parts) == 0 {
+		r.writeError(errMalformedXML)
+		return
+	}
+
+	for i := 1; i < len(opt.Parts); i++ {
+		if opt.Parts[i].PartNumber <= opt.Parts[i-1].PartNumber {
+			r.writeError(errInvalidPartOrder)
+			return
+		}
+	}
+
+	var data bytes.Buffer
+	var sums []byte
+	for i, p := range opt.Parts {
+		uploaded := u.parts[p.PartNumber]
+		if uploaded == nil || strings.Trim(p.ETag, `"`) != strings.Trim(uploaded.etag, `"`) {
+			r.writeError(errInvalidPart)
+			return
+		}
+		if i < len(opt.Parts)-1 && len(uploaded.data) < minPartSize {
+			r.writeError(errEntityTooSmall)
+			return
+		}
+		data.Write(uploaded.data)
+		sum, _ := hex.DecodeString(strings.Trim(uploaded.etag, `"`))
+		sums = append(sums, sum...)
+	}
+
+	o := newObject(u.key, data.Bytes(), u.header)
+	// 和 COS 一样，分块上传的 Object 的 ETag 不是内容的 MD5 值
+	o.etag = fmt.Sprintf(`"%x-%d"`, md5.Sum(sums), len(opt.Parts))
+	b.objects[u.key] = o
+	delete(b.uploads, u.id)
+	r.writeXML(http.StatusOK, &cos.CompleteMultipartUploadResult{
+		Location: r.Host + "/" + u.key,
+		Bucket:   b.name,
+		Key:      u.key,
+		ETag:     o.etag,
+	})
+}
+
+// listMultipartUploads 实现 Bucket.ListMultipartUploads
+func (s *Server) listMultipartUploads(r *request, b *bucket) {
+	q := r.URL.Query()
+	prefix := q.Get("prefix")
+	maxUploads := 1000
+	if v := q.Get("max-uploads"); v != "" {
+		n, e := strconv.Atoi(v)
+		if e != nil || n < 0 || n > 1000 {
+			r.writeError(errInvalidArgument)
+			return
+		}
+		maxUploads = n
+	}
+
+	var uploads []*upload
+	for _, u := range b.uploads {
+		if strings.HasPrefix(u.key, prefix) {
+			uploads = append(uploads, u)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].key != uploads[j].key {
+			return uploads[i].key < uploads[j].key
+		}
+		return uploads[i].id < uploads[j].id
+	})
+
+	keyMarker, idMarker := q.Get("key-marker"), q.Get("upload-id-marker")
+	res := &cos.ListMultipartUploadsResult{
+		Bucket:         b.name,
+		Prefix:         prefix,
+		KeyMarker:      keyMarker,
+		UploadIDMarker: idMarker,
+		MaxUploads:     maxUploads,
+	}
+	for _, u := range uploads {
+		if u.key < keyMarker || u.key == keyMarker && (idMarker == "" || u.id <= idMarker) {
+			continue
+		}
+		if len(res.Uploads) >= maxUploads {
+			res.IsTruncated = true
+			break
+		}
+		res.Uploads = append(res.Uploads, cos.MultipartUpload{
+			Key:          u.key,
+			UploadID:     u.id,
+			StorageClass: cos.StorageClassStandard,
+			Initiated:    u.initiated.UTC().Format(time.RFC3339),
+		})
+		res.NextKeyMarker = u.key
+		res.NextUploadIDMarker = u.id
+	}
+	r.writeXML(http.StatusOK, res)
+}
+
+func sortedParts(u *upload) []int {
+	var numbers []int
+	for n := range u.parts {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
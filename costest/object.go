@@ -0,0 +1,464 @@
+package costest
+
+import (
+	"crypto/md5"
+	"fmt"
+	"hash/crc64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+)
+
+// object 内存中的 Object
+type object struct {
+	key     string
+	data    []byte
+	etag    string
+	modTime time.Time
+	// 上传时指定的需要保存的头部，比如 Content-Type 和 x-cos-meta-*
+	header     http.Header
+	appendable bool
+	acl        *acl
+	// 对象的标签，通过 x-cos-tagging 头部或者 Object.PutTagging 设置
+	tags []cos.BucketTaggingTag
+	// 归档对象恢复出的临时副本的过期时间，为零值时表示没有恢复。Server 会立即完成恢复任务
+	restoreExpiry time.Time
+}
+
+func newObject(key string, data []byte, h http.Header) *object {
+	o := &object{
+		key:     key,
+		data:    data,
+		etag:    fmt.Sprintf(`"%x"`, md5.Sum(data)),
+		modTime: time.Now(),
+		header:  objectHeader(h),
+		acl:     &acl{},
+	}
+	o.acl.setHeader(h)
+	o.tags = parseTagging(h.Get("x-cos-tagging"))
+	return o
+}
+
+// parseTagging 解析 x-cos-tagging 头部，按照标签键排序返回
+func parseTagging(v string) []cos.BucketTaggingTag {
+	q, _ := url.ParseQuery(v)
+	var tags []cos.BucketTaggingTag
+	for k := range q {
+		tags = append(tags, cos.BucketTaggingTag{Key: k, Value: q.Get(k)})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags
+}
+
+func (o *object) storageClass() string {
+	if v := o.header.Get("x-cos-storage-class"); v != "" {
+		return v
+	}
+	return cos.StorageClassStandard
+}
+
+// 上传 Object 时需要保存并在下载时返回的头部
+var storedHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+	"x-cos-storage-class",
+	"x-cos-server-side-encryption",
+}
+
+// objectHeader 从请求头部中提取需要保存的头部
+func objectHeader(h http.Header) http.Header {
+	header := http.Header{}
+	for _, k := range storedHeaders {
+		if v := h.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+	for k, v := range h {
+		if strings.HasPrefix(strings.ToLower(k), "x-cos-meta-") {
+			header[k] = append([]string(nil), v...)
+		}
+	}
+	return header
+}
+
+func (s *Server) serveObject(r *request) {
+	b := s.buckets[r.bucketName]
+	if b == nil {
+		r.writeError(errNoSuchBucket)
+		return
+	}
+	if r.Method == http.MethodOptions {
+		s.preflight(r, b)
+		return
+	}
+	o := b.objects[r.key]
+
+	if !r.authorized {
+		subresource := r.hasQuery("acl") || r.hasQuery("tagging") || r.hasQuery("restore") || r.hasQuery("uploadId") || r.hasQuery("uploads")
+		read := (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+			(b.acl.allowRead() || o != nil && o.acl.allowRead())
+		write := r.Method != http.MethodGet && r.Method != http.MethodHead && b.acl.allowWrite()
+		if subresource || !(read || write) {
+			r.writeError(errAccessDenied)
+			return
+		}
+	}
+
+	switch {
+	case r.hasQuery("uploadId"):
+		s.serveUpload(r, b)
+	case r.hasQuery("uploads") && r.Method == http.MethodPost:
+		s.initiateMultipartUpload(r, b)
+	case r.hasQuery("acl"):
+		if o == nil {
+			r.writeError(errNoSuchKey)
+			return
+		}
+		s.serveACL(r, o.acl)
+	case r.hasQuery("tagging"):
+		s.serveTagging(r, o)
+	case r.hasQuery("restore") && r.Method == http.MethodPost:
+		s.restoreObject(r, o)
+	case r.hasQuery("append") && r.Method == http.MethodPost:
+		s.appendObject(r, b, o)
+	case r.Method == http.MethodPut && r.Header.Get("x-cos-copy-source") != "":
+		s.copyObject(r, b)
+	case r.Method == http.MethodPut:
+		body, err := readBody(r)
+		if err != nil {
+			r.writeError(err)
+			return
+		}
+		o = newObject(r.key, body, r.Header)
+		b.objects[r.key] = o
+		r.w.Header().Set("ETag", o.etag)
+		r.w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.getObject(r, o)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, r.key)
+		r.w.WriteHeader(http.StatusNoContent)
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+// getObject 实现 Object.Get 和 Object.Head，支持单个 Range 和条件请求
+func (s *Server) getObject(r *request, o *object) {
+	if o == nil {
+		r.writeError(errNoSuchKey)
+		return
+	}
+	if status := checkPreconditions(r.Header, "", o); status == http.StatusNotModified {
+		r.w.WriteHeader(status)
+		return
+	} else if status != http.StatusOK {
+		r.writeError(errPreconditionFailed)
+		return
+	}
+
+	if r.Method == http.MethodGet && o.storageClass() == cos.StorageClassArchive && o.restoreExpiry.IsZero() {
+		r.writeError(errInvalidObjectState)
+		return
+	}
+
+	data := o.data
+	status := http.StatusOK
+	if v := r.Header.Get("Range"); v != "" {
+		start, end, partial, err := parseRange(v, len(data))
+		if err != nil {
+			r.w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(data)))
+			r.writeError(err)
+			return
+		}
+		if partial {
+			r.w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			data = data[start : end+1]
+			status = http.StatusPartialContent
+		}
+	}
+
+	h := r.w.Header()
+	setObjectHeader(h, o)
+	// 通过 response-* 参数覆盖响应头部
+	for k, v := range r.URL.Query() {
+		if strings.HasPrefix(k, "response-") && len(v) > 0 {
+			h.Set(strings.TrimPrefix(k, "response-"), v[0])
+		}
+	}
+	h.Set("Content-Length", strconv.Itoa(len(data)))
+	r.w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		r.w.Write(data)
+	}
+}
+
+func setObjectHeader(h http.Header, o *object) {
+	h.Set("Content-Type", "application/octet-stream")
+	for k, v := range o.header {
+		h[k] = append([]string(nil), v...)
+	}
+	h.Set("ETag", o.etag)
+	h.Set("Last-Modified", o.modTime.UTC().Format(http.TimeFormat))
+	h.Set("Accept-Ranges", "bytes")
+	h.Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(o.data, crc64.MakeTable(crc64.ECMA)), 10))
+	if o.appendable {
+		h.Set("x-cos-object-type", cos.ObjectTypeAppendable)
+		h.Set("x-cos-next-append-position", strconv.Itoa(len(o.data)))
+	} else {
+		h.Set("x-cos-object-type", cos.ObjectTypeNormal)
+	}
+	if !o.restoreExpiry.IsZero() {
+		h.Set("x-cos-restore", fmt.Sprintf(`ongoing-request="false", expiry-date="%s"`, o.restoreExpiry.UTC().Format(http.TimeFormat)))
+	}
+}
+
+// checkPreconditions 检查条件请求头部，prefix 用于检查 x-cos-copy-source-If-Match 之类的头部。
+// 返回 200 表示满足所有条件
+func checkPreconditions(h http.Header, prefix string, o *object) int {
+	modTime := o.modTime.Truncate(time.Second)
+	if v := h.Get(prefix + "If-Match"); v != "" && !matchETag(v, o.etag) {
+		return http.StatusPreconditionFailed
+	}
+	if v := h.Get(prefix + "If-Unmodified-Since"); v != "" {
+		if t, err := http.ParseTime(v); err == nil && modTime.After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+	if v := h.Get(prefix + "If-None-Match"); v != "" {
+		if matchETag(v, o.etag) {
+			return http.StatusNotModified
+		}
+	} else if v := h.Get(prefix + "If-Modified-Since"); v != "" {
+		if t, err := http.ParseTime(v); err == nil && !modTime.After(t) {
+			return http.StatusNotModified
+		}
+	}
+	return http.StatusOK
+}
+
+// matchETag 判断 etag 是否在 If-Match 或 If-None-Match 头部指定的列表中
+func matchETag(list, etag string) bool {
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.Trim(v, `"`) == strings.Trim(etag, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange 解析 Range 头部，只支持单个范围：bytes=<start>-<end>、bytes=<start>- 和 bytes=-<length>。
+// 格式错误或者包含多个范围时忽略该头部，返回 partial 为 false
+func parseRange(s string, size int) (start, end int, partial bool, err *apiError) {
+	if !strings.HasPrefix(s, "bytes=") || strings.Contains(s, ",") {
+		return 0, 0, false, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(s, "bytes="), "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false, nil
+	}
+	first, last := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if first == "" {
+		n, e := strconv.Atoi(last)
+		if e != nil || n <= 0 {
+			return 0, 0, false, nil
+		}
+		if size == 0 {
+			return 0, 0, false, errInvalidRange
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+	start, e := strconv.Atoi(first)
+	if e != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	end = size - 1
+	if last != "" {
+		if end, e = strconv.Atoi(last); e != nil || end < start {
+			return 0, 0, false, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, errInvalidRange
+	}
+	return start, end, true, nil
+}
+
+// copyObject 实现 Object.Copy
+func (s *Server) copyObject(r *request, b *bucket) {
+	src, err := s.copySource(r)
+	if err != nil {
+		r.writeError(err)
+		return
+	}
+	if checkPreconditions(r.Header, "x-cos-copy-source-", src) != http.StatusOK {
+		r.writeError(errPreconditionFailed)
+		return
+	}
+	replaced := strings.EqualFold(r.Header.Get("x-cos-metadata-directive"), "Replaced")
+	if src == b.objects[r.key] && !replaced {
+		r.writeError(&apiError{http.StatusBadRequest, "InvalidRequest",
+			"This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata."})
+		return
+	}
+
+	o := newObject(r.key, append([]byte(nil), src.data...), r.Header)
+	if !replaced {
+		o.header = objectHeader(src.header)
+	}
+	if !strings.EqualFold(r.Header.Get("x-cos-tagging-directive"), "Replaced") {
+		o.tags = append([]cos.BucketTaggingTag(nil), src.tags...)
+	}
+	b.objects[r.key] = o
+	r.writeXML(http.StatusOK, &cos.ObjectCopyResult{
+		ETag:         o.etag,
+		LastModified: o.modTime.UTC().Format(time.RFC3339),
+	})
+}
+
+// serveTagging 实现 Object.PutTagging、Object.GetTagging 和 Object.DeleteTagging
+func (s *Server) serveTagging(r *request, o *object) {
+	if o == nil {
+		r.writeError(errNoSuchKey)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var opt cos.ObjectPutTaggingOptions
+		if err := readXML(r, &opt); err != nil {
+			r.writeError(err)
+			return
+		}
+		o.tags = opt.TagSet
+		r.w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		r.writeXML(http.StatusOK, &cos.ObjectGetTaggingResult{TagSet: o.tags})
+	case http.MethodDelete:
+		o.tags = nil
+		r.w.WriteHeader(http.StatusNoContent)
+	default:
+		r.writeError(errMethodNotAllowed)
+	}
+}
+
+// restoreObject 实现 Object.PostRestore，恢复任务会立即完成
+func (s *Server) restoreObject(r *request, o *object) {
+	if o == nil {
+		r.writeError(errNoSuchKey)
+		return
+	}
+	if o.storageClass() != cos.StorageClassArchive {
+		r.writeError(errInvalidObjectState)
+		return
+	}
+	var opt cos.ObjectPostRestoreOptions
+	if err := readXML(r, &opt); err != nil {
+		r.writeError(err)
+		return
+	}
+	if opt.Days <= 0 {
+		r.writeError(errMalformedXML)
+		return
+	}
+	status := http.StatusAccepted
+	if !o.restoreExpiry.IsZero() {
+		status = http.StatusOK
+	}
+	o.restoreExpiry = time.Now().Add(time.Duration(opt.Days) * 24 * time.Hour).Truncate(time.Second)
+	r.w.WriteHeader(status)
+}
+
+// copySource 返回 x-cos-copy-source 头部指定的源 Object，头部的格式为 <BucketName-APPID>.cos.<Region>.myqcloud.com/<ObjectKey>
+func (s *Server) copySource(r *request) (*object, *apiError) {
+	v := r.Header.Get("x-cos-copy-source")
+	if i := strings.Index(v, "://"); i >= 0 {
+		v = v[i+3:]
+	}
+	if i := strings.Index(v, "?"); i >= 0 {
+		v = v[:i]
+	}
+	i := strings.Index(v, "/")
+	if i < 0 {
+		return nil, &apiError{http.StatusBadRequest, "InvalidArgument", "Invalid copy source."}
+	}
+	key := v[i+1:]
+	if k, err := url.PathUnescape(key); err == nil {
+		key = k
+	}
+	b := s.buckets[bucketName(v[:i])]
+	if b == nil {
+		return nil, errNoSuchBucket
+	}
+	o := b.objects[key]
+	if o == nil {
+		return nil, errNoSuchKey
+	}
+	return o, nil
+}
+
+// bucketName 根据域名返回 Bucket 的名称，不是 Bucket 的域名时返回 DefaultBucket
+func bucketName(host string) string {
+	if strings.HasSuffix(host, hostSuffix) {
+		return strings.TrimSuffix(host, hostSuffix)
+	}
+	if i := strings.Index(host, ".cos."); i > 0 {
+		return host[:i]
+	}
+	return DefaultBucket
+}
+
+// appendObject 实现 Object.Append
+func (s *Server) appendObject(r *request, b *bucket, o *object) {
+	position, e := strconv.Atoi(r.URL.Query().Get("position"))
+	if e != nil || position < 0 {
+		r.writeError(errInvalidArgument)
+		return
+	}
+	if o != nil && !o.appendable {
+		r.writeError(errObjectNotAppendable)
+		return
+	}
+	length := 0
+	if o != nil {
+		length = len(o.data)
+	}
+	if position != length {
+		r.w.Header().Set("x-cos-next-append-position", strconv.Itoa(length))
+		r.writeError(errPositionNotEqual)
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		r.writeError(err)
+		return
+	}
+
+	if o == nil {
+		o = newObject(r.key, body, r.Header)
+		o.appendable = true
+		b.objects[r.key] = o
+	} else {
+		o.data = append(o.data, body...)
+		o.etag = fmt.Sprintf(`"%x"`, md5.Sum(o.data))
+		o.modTime = time.Now()
+	}
+	r.w.Header().Set("ETag", o.etag)
+	r.w.Header().Set("x-cos-next-append-position", strconv.Itoa(len(o.data)))
+	r.w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,181 @@
+package costest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// POST Object 表单中除文件外的数据的最大大小
+const maxPostFormMemory = 1 << 20
+
+// postResponse success_action_status 为 201 时返回的结果
+type postResponse struct {
+	XMLName  xml.Name `xml:"PostResponse"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// postObject 实现 multipart/form-data 表单上传，表单中的 policy 和签名会代替 Authorization 头部校验
+//
+// https://cloud.tencent.com/document/product/436/14690
+func (s *Server) postObject(r *request, b *bucket) {
+	if err := r.ParseMultipartForm(maxPostFormMemory); err != nil {
+		r.writeError(&apiError{http.StatusBadRequest, "MalformedPOSTRequest", err.Error()})
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	// 表单字段的名称不区分大小写
+	fields := map[string]string{}
+	h := http.Header{}
+	for k, v := range r.MultipartForm.Value {
+		if len(v) > 0 {
+			fields[strings.ToLower(k)] = v[0]
+			h.Set(k, v[0])
+		}
+	}
+	files := r.MultipartForm.File["file"]
+	if len(files) == 0 || fields["key"] == "" {
+		r.writeError(&apiError{http.StatusBadRequest, "MalformedPOSTRequest", "The body of your POST request is not well-formed multipart/form-data."})
+		return
+	}
+	f, err := files[0].Open()
+	if err != nil {
+		r.writeError(&apiError{http.StatusBadRequest, "MalformedPOSTRequest", err.Error()})
+		return
+	}
+	data, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		r.writeError(&apiError{http.StatusBadRequest, "MalformedPOSTRequest", err.Error()})
+		return
+	}
+	key := strings.Replace(fields["key"], "${filename}", files[0].Filename, -1)
+	fields["key"] = key
+
+	switch {
+	case fields["policy"] != "":
+		if err := s.checkPostPolicy(b, fields, len(data)); err != nil {
+			r.writeError(err)
+			return
+		}
+	case !r.authorized && !b.acl.allowWrite():
+		r.writeError(errAccessDenied)
+		return
+	}
+
+	o := newObject(key, data, h)
+	b.objects[key] = o
+	location := fmt.Sprintf("http://%s/%s", r.Host, key)
+	r.w.Header().Set("ETag", o.etag)
+	r.w.Header().Set("Location", location)
+	switch fields["success_action_status"] {
+	case "200":
+		r.w.WriteHeader(http.StatusOK)
+	case "201":
+		r.writeXML(http.StatusCreated, &postResponse{
+			Location: location,
+			Bucket:   b.name,
+			Key:      key,
+			ETag:     o.etag,
+		})
+	default:
+		r.w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// checkPostPolicy 校验 POST Object 表单中的签名，以及表单是否满足 policy 中的条件
+func (s *Server) checkPostPolicy(b *bucket, fields map[string]string, size int) *apiError {
+	policy, err := base64.StdEncoding.DecodeString(fields["policy"])
+	if err != nil {
+		return &apiError{http.StatusBadRequest, "InvalidPolicyDocument", "Invalid Policy: Invalid Base64 Encoding."}
+	}
+	if s.SecretID != "" {
+		if fields["q-sign-algorithm"] != "sha1" || fields["q-ak"] != s.SecretID {
+			return errAccessDenied
+		}
+		if !inTimeRange(fields["q-key-time"], time.Now()) {
+			return &apiError{http.StatusForbidden, "AccessDenied", "Request has expired."}
+		}
+		signKey := fmt.Sprintf("%x", hmacSHA1(s.SecretKey, fields["q-key-time"]))
+		signature := fmt.Sprintf("%x", hmacSHA1(signKey, fmt.Sprintf("%x", sha1.Sum(policy))))
+		if !hmac.Equal([]byte(signature), []byte(fields["q-signature"])) {
+			return errSignatureDoesNotMatch
+		}
+	}
+
+	var doc struct {
+		Expiration string        `json:"expiration"`
+		Conditions []interface{} `json:"conditions"`
+	}
+	if err := json.Unmarshal(policy, &doc); err != nil {
+		return &apiError{http.StatusBadRequest, "InvalidPolicyDocument", "Invalid Policy: Invalid JSON."}
+	}
+	expiration, err := time.Parse(time.RFC3339, doc.Expiration)
+	if err != nil {
+		return &apiError{http.StatusBadRequest, "InvalidPolicyDocument", "Invalid Policy: Invalid 'expiration' value."}
+	}
+	if time.Now().After(expiration) {
+		return &apiError{http.StatusForbidden, "AccessDenied", "Invalid according to Policy: Policy expired."}
+	}
+
+	for _, c := range doc.Conditions {
+		if !matchPostCondition(b, fields, size, c) {
+			return &apiError{http.StatusForbidden, "AccessDenied",
+				fmt.Sprintf("Invalid according to Policy: Policy Condition failed: %v", c)}
+		}
+	}
+	return nil
+}
+
+// matchPostCondition 判断表单是否满足 policy 中的一个条件。
+// 条件可以是 {"<field>": "<value>"}，["eq", "$<field>", "<value>"]，["starts-with", "$<field>", "<prefix>"]
+// 或者 ["content-length-range", <min>, <max>]
+func matchPostCondition(b *bucket, fields map[string]string, size int, c interface{}) bool {
+	field := func(name string) string {
+		name = strings.ToLower(strings.TrimPrefix(name, "$"))
+		switch name {
+		case "bucket":
+			return b.name
+		case "q-sign-time":
+			return fields["q-key-time"]
+		}
+		return fields[name]
+	}
+
+	switch c := c.(type) {
+	case map[string]interface{}:
+		for k, v := range c {
+			if field(k) != fmt.Sprint(v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		if len(c) != 3 {
+			return false
+		}
+		op, _ := c[0].(string)
+		switch strings.ToLower(op) {
+		case "eq":
+			return field(fmt.Sprint(c[1])) == fmt.Sprint(c[2])
+		case "starts-with":
+			return strings.HasPrefix(field(fmt.Sprint(c[1])), fmt.Sprint(c[2]))
+		case "content-length-range":
+			min, ok1 := c[1].(float64)
+			max, ok2 := c[2].(float64)
+			return ok1 && ok2 && min <= float64(size) && float64(size) <= max
+		}
+	}
+	return false
+}
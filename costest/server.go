@@ -0,0 +1,283 @@
+/*
+Package costest 提供了一个基于 httptest 的内存版 COS 服务，用于在单元测试中代替真实的 COS 服务。
+
+Server 实现了 go-cos 所使用的 XML API 的主要功能（Bucket 的创建、删除和列出 Object，
+Object 的上传、表单上传、下载、复制、删除、追加上传和分块上传，以及 ACL、CORS、标签、生命周期、版本控制等配置的存取），
+并且会校验 AuthorizationTransport 生成的签名（包括预签名授权 URL）。
+
+	srv := costest.NewServer()
+	defer srv.Close()
+	srv.CreateBucket("test-1250000000")
+
+	c := srv.NewClient("test-1250000000")
+	_, err := c.Object.Put(context.Background(), "hello.txt", strings.NewReader("hello"), nil)
+
+Server 只是为了测试而实现的简化版本，不保证与 COS 服务的行为完全一致。
+比如启用版本控制后 Server 并不会保留 Object 的历史版本。
+*/
+package costest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+)
+
+const (
+	// DefaultSecretID NewServer 默认使用的 SecretID
+	DefaultSecretID = "AKIDcostest"
+	// DefaultSecretKey NewServer 默认使用的 SecretKey
+	DefaultSecretKey = "costest-secret-key"
+	// DefaultBucket 直接使用 Server.URL 访问时所使用的 Bucket
+	DefaultBucket = "test-1250000000"
+
+	// Bucket 和 Service 的域名后缀，NewClient 返回的 Client 会把这些域名的请求都发送到 Server
+	hostSuffix  = ".cos.costest.local"
+	serviceHost = "service" + hostSuffix
+)
+
+// Server 内存版的 COS 服务
+type Server struct {
+	// Server 的地址，形如 http://127.0.0.1:1234 。
+	// 直接使用该地址作为 BucketURL 时访问的是 DefaultBucket
+	URL string
+	// 用于校验请求签名的密钥，SecretID 为空时不校验签名
+	SecretID  string
+	SecretKey string
+	// GetLocation 返回的地域，默认值：ap-guangzhou
+	Region string
+
+	srv       *httptest.Server
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	requestID int
+}
+
+// NewServer 启动一个新的 Server，使用完后需要调用 Close 方法关闭
+func NewServer() *Server {
+	s := &Server{
+		SecretID:  DefaultSecretID,
+		SecretKey: DefaultSecretKey,
+		Region:    "ap-guangzhou",
+		buckets:   map[string]*bucket{},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close 关闭 Server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// CreateBucket 创建一个 Bucket，Bucket 已存在时不做任何操作
+func (s *Server) CreateBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buckets[name] == nil {
+		s.buckets[name] = newBucket(name)
+	}
+}
+
+// BucketURL 返回访问 Bucket 的 BucketURL，需要配合 Transport 使用
+func (s *Server) BucketURL(name string) *url.URL {
+	return &url.URL{Scheme: "http", Host: name + hostSuffix}
+}
+
+// ServiceURL 返回访问 Service API 的 ServiceURL，需要配合 Transport 使用
+func (s *Server) ServiceURL() *url.URL {
+	return &url.URL{Scheme: "http", Host: serviceHost}
+}
+
+// Transport 返回一个将所有请求都发送到 Server 的 http.RoundTripper
+func (s *Server) Transport() http.RoundTripper {
+	addr := s.srv.Listener.Addr().String()
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// NewClient 返回一个访问 Server 中名为 bucket 的 Bucket 的 Client，使用 Server 的密钥对请求进行签名
+func (s *Server) NewClient(bucket string) *cos.Client {
+	return cos.NewClient(&cos.BaseURL{
+		BucketURL:  s.BucketURL(bucket),
+		ServiceURL: s.ServiceURL(),
+	}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  s.SecretID,
+			SecretKey: s.SecretKey,
+			Transport: s.Transport(),
+		},
+	})
+}
+
+// request 一次请求的上下文
+type request struct {
+	*http.Request
+	w         http.ResponseWriter
+	requestID string
+	// 请求是否带有有效的签名
+	authorized bool
+	bucketName string
+	// Object 的名称，访问 Bucket 时为空
+	key string
+}
+
+// hasQuery 请求中是否包含子资源参数，比如 ?acl
+func (r *request) hasQuery(name string) bool {
+	_, ok := r.URL.Query()[name]
+	return ok
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, hr *http.Request) {
+	// 响应先写入 rec，处理完成后在锁外发送给客户端
+	rec := httptest.NewRecorder()
+	r := &request{
+		Request: hr,
+		w:       rec,
+		key:     strings.TrimPrefix(hr.URL.Path, "/"),
+	}
+	// 在加锁前读取完整的 body，持有锁时只访问内存中的数据
+	body, err := ioutil.ReadAll(hr.Body)
+	hr.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requestID++
+	r.requestID = fmt.Sprintf("costest-%d", s.requestID)
+	s.mu.Unlock()
+	rec.Header().Set("x-cos-request-id", r.requestID)
+	rec.Header().Set("Server", "tencent-cos")
+
+	if err != nil {
+		r.writeError(&apiError{http.StatusBadRequest, "IncompleteBody", err.Error()})
+	} else if authorized, err := s.checkAuthorization(hr); err != nil {
+		r.writeError(err)
+	} else {
+		r.authorized = authorized
+		s.serve(r)
+	}
+
+	h := w.Header()
+	for k, v := range rec.Header() {
+		h[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+// serve 根据请求的域名和路径处理请求
+func (s *Server) serve(r *request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Host == serviceHost {
+		s.serveService(r)
+		return
+	}
+	r.bucketName = bucketName(r.Host)
+	if r.key == "" {
+		s.serveBucket(r)
+		return
+	}
+	s.serveObject(r)
+}
+
+func (s *Server) serveService(r *request) {
+	if r.Method != http.MethodGet {
+		r.writeError(errMethodNotAllowed)
+		return
+	}
+	if !r.authorized {
+		r.writeError(errAccessDenied)
+		return
+	}
+	res := &cos.ServiceGetResult{
+		Owner: &cos.Owner{ID: "qcs::cam::uin/100000000001:uin/100000000001"},
+	}
+	for _, name := range sortedKeys(s.buckets) {
+		b := s.buckets[name]
+		res.Buckets = append(res.Buckets, cos.Bucket{
+			Name:       b.name,
+			Region:     s.Region,
+			CreateDate: b.created.Format(time.RFC3339),
+		})
+	}
+	r.writeXML(http.StatusOK, res)
+}
+
+// writeXML 返回 XML 格式的响应
+func (r *request) writeXML(status int, v interface{}) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		r.writeError(&apiError{http.StatusInternalServerError, "InternalError", err.Error()})
+		return
+	}
+	r.w.Header().Set("Content-Type", "application/xml")
+	r.w.WriteHeader(status)
+	r.w.Write(b)
+}
+
+// writeRaw 返回 body 原始内容
+func (r *request) writeRaw(contentType string, body []byte) {
+	r.w.Header().Set("Content-Type", contentType)
+	r.w.Write(body)
+}
+
+func (r *request) writeError(err *apiError) {
+	res := &cos.ErrorResponse{
+		Code:      err.code,
+		Message:   err.message,
+		Resource:  r.Host + r.URL.Path,
+		RequestID: r.requestID,
+	}
+	r.w.Header().Set("Content-Type", "application/xml")
+	r.w.WriteHeader(err.status)
+	if r.Method != http.MethodHead {
+		b, _ := xml.Marshal(res)
+		r.w.Write(b)
+	}
+}
+
+// apiError COS API 返回的错误
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+var (
+	errAccessDenied          = &apiError{http.StatusForbidden, "AccessDenied", "Access Denied."}
+	errSignatureDoesNotMatch = &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The calculated signature does not match the provided one."}
+	errMethodNotAllowed      = &apiError{http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource."}
+	errNoSuchBucket          = &apiError{http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist."}
+	errNoSuchKey             = &apiError{http.StatusNotFound, "NoSuchKey", "The specified key does not exist."}
+	errNoSuchUpload          = &apiError{http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist."}
+	errBucketAlreadyExists   = &apiError{http.StatusConflict, "BucketAlreadyExists", "The requested bucket name is not available."}
+	errBucketNotEmpty        = &apiError{http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty."}
+	errMalformedXML          = &apiError{http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed."}
+	errBadDigest             = &apiError{http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what was received."}
+	errInvalidArgument       = &apiError{http.StatusBadRequest, "InvalidArgument", "Invalid argument."}
+	errInvalidRange          = &apiError{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable."}
+	errPreconditionFailed    = &apiError{http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the preconditions you specified did not hold."}
+	errInvalidPart           = &apiError{http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found."}
+	errInvalidPartOrder      = &apiError{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order."}
+	errEntityTooSmall        = &apiError{http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size."}
+	errPositionNotEqual      = &apiError{http.StatusConflict, "PositionNotEqualToLength", "The position is not equal to the length of the object."}
+	errObjectNotAppendable   = &apiError{http.StatusConflict, "ObjectNotAppendable", "The object is not appendable."}
+	errInvalidObjectState    = &apiError{http.StatusForbidden, "InvalidObjectState", "The operation is not valid for the object's storage class."}
+)
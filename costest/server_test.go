@@ -0,0 +1,806 @@
+package costest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+)
+
+const testBucket = "test-1250000000"
+
+func setup(t *testing.T) (*Server, *cos.Client) {
+	srv := NewServer()
+	srv.CreateBucket(testBucket)
+	return srv, srv.NewClient(testBucket)
+}
+
+func testErrorCode(t *testing.T, err error, code string) {
+	e, ok := err.(*cos.ErrorResponse)
+	if !ok || e.Code != code {
+		t.Errorf("returned error %v, want %s", err, code)
+	}
+}
+
+func putObject(t *testing.T, c *cos.Client, name, content string) {
+	_, err := c.Object.Put(context.Background(), name, strings.NewReader(content), nil)
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+}
+
+func getObject(t *testing.T, c *cos.Client, name string, opt *cos.ObjectGetOptions) string {
+	resp, err := c.Object.Get(context.Background(), name, opt)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	return string(b)
+}
+
+func TestServer_bucket(t *testing.T) {
+	srv, _ := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	c := srv.NewClient("new-1250000000")
+	if _, err := c.Bucket.Head(ctx); err == nil {
+		t.Fatal("Bucket.Head should return error")
+	}
+	if _, err := c.Bucket.Put(ctx, nil); err != nil {
+		t.Fatalf("Bucket.Put returned error: %v", err)
+	}
+	_, err := c.Bucket.Put(ctx, nil)
+	testErrorCode(t, err, "BucketAlreadyExists")
+	if _, err := c.Bucket.Head(ctx); err != nil {
+		t.Fatalf("Bucket.Head returned error: %v", err)
+	}
+
+	res, _, err := c.Service.Get(ctx)
+	if err != nil {
+		t.Fatalf("Service.Get returned error: %v", err)
+	}
+	if len(res.Buckets) != 2 || res.Buckets[0].Name != "new-1250000000" || res.Buckets[1].Name != testBucket {
+		t.Errorf("Service.Get returned %+v", res.Buckets)
+	}
+
+	putObject(t, c, "hello.txt", "hello")
+	_, err = c.Bucket.Delete(ctx)
+	testErrorCode(t, err, "BucketNotEmpty")
+	c.Object.Delete(ctx, "hello.txt")
+	if _, err := c.Bucket.Delete(ctx); err != nil {
+		t.Fatalf("Bucket.Delete returned error: %v", err)
+	}
+	_, _, err = c.Bucket.Get(ctx, nil)
+	testErrorCode(t, err, "NoSuchBucket")
+}
+
+func TestServer_authorization(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+	putObject(t, c, "hello.txt", "hello")
+
+	wrong := cos.NewClient(&cos.BaseURL{BucketURL: srv.BucketURL(testBucket)}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  srv.SecretID,
+			SecretKey: "wrong",
+			Transport: srv.Transport(),
+		},
+	})
+	_, err := wrong.Object.Head(ctx, "hello.txt", nil)
+	if e, ok := err.(*cos.ErrorResponse); !ok || e.Response.StatusCode != http.StatusForbidden {
+		t.Errorf("Object.Head returned error %v, want 403", err)
+	}
+
+	// 直接访问 Server.URL 时使用 DefaultBucket
+	u, _ := url.Parse(srv.URL)
+	anonymous := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+	_, err = anonymous.Object.Get(ctx, "hello.txt", nil)
+	testErrorCode(t, err, "AccessDenied")
+
+	if _, err := c.Object.PutACL(ctx, "hello.txt", &cos.ObjectPutACLOptions{
+		Header: &cos.ACLHeaderOptions{XCosACL: "public-read"},
+	}); err != nil {
+		t.Fatalf("Object.PutACL returned error: %v", err)
+	}
+	resp, err := anonymous.Object.Get(ctx, "hello.txt", nil)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	resp.Body.Close()
+	_, err = anonymous.Object.Put(ctx, "hello.txt", strings.NewReader("world"), nil)
+	testErrorCode(t, err, "AccessDenied")
+}
+
+func TestServer_concurrentRequests(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	putObject(t, c, "hello.txt", "hello")
+
+	// 上传中的请求不会阻塞其他请求
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Object.Put(context.Background(), "slow.txt", pr, nil)
+		done <- err
+	}()
+	pw.Write([]byte("sl"))
+	if got := getObject(t, c, "hello.txt", nil); got != "hello" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+	pw.Write([]byte("ow"))
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if got := getObject(t, c, "slow.txt", nil); got != "slow" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+}
+
+func TestServer_presignedURL(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+	putObject(t, c, "hello world.txt", "hello")
+
+	auth := cos.Auth{SecretID: srv.SecretID, SecretKey: srv.SecretKey}
+	u, err := c.Object.PresignedURL(ctx, http.MethodGet, "hello world.txt", auth, nil)
+	if err != nil {
+		t.Fatalf("Object.PresignedURL returned error: %v", err)
+	}
+	hc := &http.Client{Transport: srv.Transport()}
+	resp, err := hc.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(b) != "hello" {
+		t.Errorf("GET presigned URL returned %d %q", resp.StatusCode, b)
+	}
+
+	// 修改签名中的参数后签名不再有效
+	q := u.Query()
+	q.Set("sign", strings.Replace(q.Get("sign"), "q-ak="+srv.SecretID, "q-ak=other", 1))
+	u.RawQuery = q.Encode()
+	resp, err = hc.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("GET invalid presigned URL returned %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestServer_postObject(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	auth := cos.Auth{SecretID: srv.SecretID, SecretKey: srv.SecretKey}
+	policy, err := c.Object.PostPolicy(ctx, auth, &cos.ObjectPostPolicyOptions{
+		KeyPrefix:           "uploads/",
+		MaxContentLength:    10,
+		ContentType:         "text/plain",
+		SuccessActionStatus: 201,
+	})
+	if err != nil {
+		t.Fatalf("Object.PostPolicy returned error: %v", err)
+	}
+
+	// 不使用 Authorization 头部签名的 Client
+	anonymous := cos.NewClient(&cos.BaseURL{BucketURL: srv.BucketURL(testBucket)}, &http.Client{
+		Transport: srv.Transport(),
+	})
+	opt := &cos.ObjectPostOptions{Fields: policy.Fields}
+	resp, err := anonymous.Object.PostObject(ctx, "uploads/hello.txt", strings.NewReader("hello"), opt)
+	if err != nil {
+		t.Fatalf("Object.PostObject returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("ETag") == "" {
+		t.Errorf("Object.PostObject returned %d, ETag %q", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	resp, err = c.Object.Head(ctx, "uploads/hello.txt", nil)
+	if err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if resp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("Object.Head returned Content-Type %q", resp.Header.Get("Content-Type"))
+	}
+	if got := getObject(t, c, "uploads/hello.txt", nil); got != "hello" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+
+	// 不满足 policy 中的条件
+	_, err = anonymous.Object.PostObject(ctx, "other/hello.txt", strings.NewReader("hello"), opt)
+	testErrorCode(t, err, "AccessDenied")
+	_, err = anonymous.Object.PostObject(ctx, "uploads/large.txt", strings.NewReader("hello world"), opt)
+	testErrorCode(t, err, "AccessDenied")
+
+	// 修改 policy 后签名不再有效
+	fields := map[string]string{}
+	for k, v := range policy.Fields {
+		fields[k] = v
+	}
+	fields["q-ak"] = "other"
+	_, err = anonymous.Object.PostObject(ctx, "uploads/hello.txt", strings.NewReader("hello"), &cos.ObjectPostOptions{Fields: fields})
+	testErrorCode(t, err, "AccessDenied")
+
+	// 没有 policy 时使用 Authorization 头部签名
+	_, err = anonymous.Object.PostObject(ctx, "signed.txt", strings.NewReader("hello"), nil)
+	testErrorCode(t, err, "AccessDenied")
+	_, err = c.Object.PostObject(ctx, "signed.txt", bytes.NewReader([]byte("hello")), &cos.ObjectPostOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			XCosMetaXXX: &http.Header{"X-Cos-Meta-Test": []string{"test"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.PostObject returned error: %v", err)
+	}
+	resp, err = c.Object.Head(ctx, "signed.txt", nil)
+	if err != nil || resp.Header.Get("X-Cos-Meta-Test") != "test" {
+		t.Errorf("Object.Head returned %v, %v", resp, err)
+	}
+}
+
+func TestServer_object(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	opt := &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: "text/plain",
+			XCosMetaXXX: &http.Header{},
+		},
+	}
+	opt.XCosMetaXXX.Set("x-cos-meta-author", "costest")
+	if _, err := c.Object.Put(ctx, "a/hello.txt", strings.NewReader("hello world"), opt); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+
+	resp, err := c.Object.Head(ctx, "a/hello.txt", nil)
+	if err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	etag := resp.Header.Get("ETag")
+	if resp.Header.Get("Content-Type") != "text/plain" || resp.Header.Get("x-cos-meta-author") != "costest" ||
+		resp.Header.Get("Content-Length") != "11" {
+		t.Errorf("Object.Head returned header %+v", resp.Header)
+	}
+
+	if got := getObject(t, c, "a/hello.txt", &cos.ObjectGetOptions{Range: "bytes=6-"}); got != "world" {
+		t.Errorf("Object.Get with range returned %q, want world", got)
+	}
+	_, err = c.Object.Get(ctx, "a/hello.txt", &cos.ObjectGetOptions{IfMatch: `"other"`})
+	testErrorCode(t, err, "PreconditionFailed")
+	_, err = c.Object.Get(ctx, "a/hello.txt", &cos.ObjectGetOptions{Range: "bytes=100-"})
+	testErrorCode(t, err, "InvalidRange")
+
+	source := srv.BucketURL(testBucket).Host + "/a/hello.txt"
+	res, _, err := c.Object.Copy(ctx, "b/hello.txt", source, nil)
+	if err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+	if res.ETag != etag {
+		t.Errorf("Object.Copy returned ETag %s, want %s", res.ETag, etag)
+	}
+	if got := getObject(t, c, "b/hello.txt", nil); got != "hello world" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+	_, _, err = c.Object.Copy(ctx, "b/hello.txt", source+"-404", nil)
+	testErrorCode(t, err, "NoSuchKey")
+
+	if _, err := c.Object.Delete(ctx, "a/hello.txt"); err != nil {
+		t.Fatalf("Object.Delete returned error: %v", err)
+	}
+	_, err = c.Object.Head(ctx, "a/hello.txt", nil)
+	if e, ok := err.(*cos.ErrorResponse); !ok || e.Response.StatusCode != http.StatusNotFound {
+		t.Errorf("Object.Head returned error %v, want 404", err)
+	}
+
+	dres, _, err := c.Object.DeleteMulti(ctx, &cos.ObjectDeleteMultiOptions{
+		Objects: []cos.Object{{Key: "b/hello.txt"}, {Key: "not-exist"}},
+	})
+	if err != nil {
+		t.Fatalf("Object.DeleteMulti returned error: %v", err)
+	}
+	if len(dres.DeletedObjects) != 2 {
+		t.Errorf("Object.DeleteMulti returned %+v", dres)
+	}
+}
+
+func TestServer_objectTagging(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := c.Object.Put(ctx, "hello.txt", strings.NewReader("hello"), &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{XCosTagging: "team=storage&env=test"},
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	want := []cos.BucketTaggingTag{{Key: "env", Value: "test"}, {Key: "team", Value: "storage"}}
+	res, _, err := c.Object.GetTagging(ctx, "hello.txt", nil)
+	if err != nil || !reflect.DeepEqual(res.TagSet, want) {
+		t.Errorf("Object.GetTagging returned %+v, %v", res, err)
+	}
+
+	source := srv.BucketURL(testBucket).Host + "/hello.txt"
+	if _, _, err := c.Object.Copy(ctx, "copy.txt", source, nil); err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+	res, _, err = c.Object.GetTagging(ctx, "copy.txt", nil)
+	if err != nil || !reflect.DeepEqual(res.TagSet, want) {
+		t.Errorf("Object.GetTagging returned %+v, %v", res, err)
+	}
+	_, _, err = c.Object.Copy(ctx, "replaced.txt", source, &cos.ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &cos.ObjectCopyHeaderOptions{XCosTaggingDirective: "Replaced", XCosTagging: "env=prod"},
+	})
+	if err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+	res, _, err = c.Object.GetTagging(ctx, "replaced.txt", nil)
+	if err != nil || !reflect.DeepEqual(res.TagSet, []cos.BucketTaggingTag{{Key: "env", Value: "prod"}}) {
+		t.Errorf("Object.GetTagging returned %+v, %v", res, err)
+	}
+
+	tags := []cos.BucketTaggingTag{{Key: "owner", Value: "costest"}}
+	if _, err := c.Object.PutTagging(ctx, "hello.txt", &cos.ObjectPutTaggingOptions{TagSet: tags}); err != nil {
+		t.Fatalf("Object.PutTagging returned error: %v", err)
+	}
+	res, _, err = c.Object.GetTagging(ctx, "hello.txt", nil)
+	if err != nil || !reflect.DeepEqual(res.TagSet, tags) {
+		t.Errorf("Object.GetTagging returned %+v, %v", res, err)
+	}
+	if _, err := c.Object.DeleteTagging(ctx, "hello.txt", nil); err != nil {
+		t.Fatalf("Object.DeleteTagging returned error: %v", err)
+	}
+	res, _, err = c.Object.GetTagging(ctx, "hello.txt", nil)
+	if err != nil || len(res.TagSet) != 0 {
+		t.Errorf("Object.GetTagging returned %+v, %v", res, err)
+	}
+	_, _, err = c.Object.GetTagging(ctx, "not-exist", nil)
+	testErrorCode(t, err, "NoSuchKey")
+}
+
+func TestServer_restore(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	putObject(t, c, "standard.txt", "hello")
+	_, err := c.Object.PostRestore(ctx, "standard.txt", &cos.ObjectPostRestoreOptions{Days: 1})
+	testErrorCode(t, err, "InvalidObjectState")
+
+	_, err = c.Object.Put(ctx, "archived.txt", strings.NewReader("hello"), &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{XCosStorageClass: cos.StorageClassArchive},
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	_, err = c.Object.Get(ctx, "archived.txt", nil)
+	testErrorCode(t, err, "InvalidObjectState")
+	_, err = c.Object.WaitRestored(ctx, "archived.txt", nil)
+	if err != cos.ErrRestoreNotRequested {
+		t.Errorf("Object.WaitRestored returned error %v, want %v", err, cos.ErrRestoreNotRequested)
+	}
+
+	resp, err := c.Object.PostRestore(ctx, "archived.txt", &cos.ObjectPostRestoreOptions{
+		Days:             1,
+		CASJobParameters: &cos.ObjectRestoreJobParameters{Tier: cos.RestoreTierExpedited},
+	})
+	if err != nil || resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Object.PostRestore returned %v, %v", resp, err)
+	}
+	resp, err = c.Object.WaitRestored(ctx, "archived.txt", nil)
+	if err != nil {
+		t.Fatalf("Object.WaitRestored returned error: %v", err)
+	}
+	if status := resp.Restore(); status == nil || status.OngoingRequest || status.ExpiryDate.Before(time.Now()) {
+		t.Errorf("Response.Restore() returned %+v", status)
+	}
+	if got := getObject(t, c, "archived.txt", nil); got != "hello" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+}
+
+func TestServer_append(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := c.Object.Append(ctx, "log.txt", 0, strings.NewReader("hello "), nil); err != nil {
+		t.Fatalf("Object.Append returned error: %v", err)
+	}
+	_, err := c.Object.Append(ctx, "log.txt", 0, strings.NewReader("world"), nil)
+	testErrorCode(t, err, "PositionNotEqualToLength")
+	resp, err := c.Object.Append(ctx, "log.txt", 6, strings.NewReader("world"), nil)
+	if err != nil {
+		t.Fatalf("Object.Append returned error: %v", err)
+	}
+	if v := resp.Header.Get("x-cos-next-append-position"); v != "11" {
+		t.Errorf("x-cos-next-append-position is %s, want 11", v)
+	}
+	if got := getObject(t, c, "log.txt", nil); got != "hello world" {
+		t.Errorf("Object.Get returned %q", got)
+	}
+
+	putObject(t, c, "normal.txt", "hello")
+	_, err = c.Object.Append(ctx, "normal.txt", 5, strings.NewReader("world"), nil)
+	testErrorCode(t, err, "ObjectNotAppendable")
+}
+
+func TestServer_list(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+	for _, name := range []string{"a.txt", "dir/a.txt", "dir/b.txt", "dir/sub/c.txt", "e f.txt"} {
+		putObject(t, c, name, name)
+	}
+
+	list := func(opt *cos.BucketGetOptions) string {
+		res, _, err := c.Bucket.Get(ctx, opt)
+		if err != nil {
+			t.Fatalf("Bucket.Get returned error: %v", err)
+		}
+		var keys []string
+		for _, o := range res.Contents {
+			keys = append(keys, o.Key)
+		}
+		return fmt.Sprintf("%v %v %v %s", keys, res.CommonPrefixes, res.IsTruncated, res.NextMarker)
+	}
+	tests := []struct {
+		opt  *cos.BucketGetOptions
+		want string
+	}{
+		{nil, "[a.txt dir/a.txt dir/b.txt dir/sub/c.txt e f.txt] [] false "},
+		{&cos.BucketGetOptions{Delimiter: "/"}, "[a.txt e f.txt] [dir/] false "},
+		{&cos.BucketGetOptions{Prefix: "dir/", Delimiter: "/"}, "[dir/a.txt dir/b.txt] [dir/sub/] false "},
+		{&cos.BucketGetOptions{Delimiter: "/", MaxKeys: 2}, "[a.txt] [dir/] true dir/"},
+		{&cos.BucketGetOptions{Delimiter: "/", Marker: "dir/"}, "[e f.txt] [] false "},
+		{&cos.BucketGetOptions{Marker: "dir/a.txt", MaxKeys: 1}, "[dir/b.txt] [] true "},
+		{&cos.BucketGetOptions{Prefix: "e", EncodingType: "url"}, "[e%20f.txt] [] false "},
+	}
+	for _, tt := range tests {
+		if got := list(tt.opt); got != tt.want {
+			t.Errorf("Bucket.Get(%+v) returned %s, want %s", tt.opt, got, tt.want)
+		}
+	}
+}
+
+func TestServer_multipart(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), 250*1024)
+	res, _, err := c.Object.Upload(ctx, "big.bin", bytes.NewReader(data), &cos.ObjectUploadOptions{
+		PartSize:  1024 * 1024,
+		Threshold: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if len(res.Parts) != 3 || !strings.HasSuffix(res.ETag, `-3"`) {
+		t.Errorf("Object.Upload returned %+v", res)
+	}
+	if got := getObject(t, c, "big.bin", nil); got != string(data) {
+		t.Errorf("Object.Get returned %d bytes, want %d", len(got), len(data))
+	}
+
+	init, _, err := c.Object.InitiateMultipartUpload(ctx, "small.bin", nil)
+	if err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+	var parts []cos.Object
+	for i := 1; i <= 2; i++ {
+		resp, err := c.Object.UploadPart(ctx, "small.bin", init.UploadID, i, strings.NewReader("part"), nil)
+		if err != nil {
+			t.Fatalf("Object.UploadPart returned error: %v", err)
+		}
+		parts = append(parts, cos.Object{PartNumber: i, ETag: resp.Header.Get("ETag")})
+	}
+	lres, _, err := c.Object.ListParts(ctx, "small.bin", init.UploadID)
+	if err != nil {
+		t.Fatalf("Object.ListParts returned error: %v", err)
+	}
+	if len(lres.Parts) != 2 {
+		t.Errorf("Object.ListParts returned %+v", lres.Parts)
+	}
+	ures, _, err := c.Bucket.ListMultipartUploads(ctx, nil)
+	if err != nil {
+		t.Fatalf("Bucket.ListMultipartUploads returned error: %v", err)
+	}
+	if len(ures.Uploads) != 1 || ures.Uploads[0].UploadID != init.UploadID {
+		t.Errorf("Bucket.ListMultipartUploads returned %+v", ures.Uploads)
+	}
+
+	_, _, err = c.Object.CompleteMultipartUpload(ctx, "small.bin", init.UploadID, &cos.CompleteMultipartUploadOptions{
+		Parts: []cos.Object{parts[1], parts[0]},
+	})
+	testErrorCode(t, err, "InvalidPartOrder")
+	_, _, err = c.Object.CompleteMultipartUpload(ctx, "small.bin", init.UploadID, &cos.CompleteMultipartUploadOptions{
+		Parts: parts,
+	})
+	testErrorCode(t, err, "EntityTooSmall")
+
+	if _, err := c.Object.AbortMultipartUpload(ctx, "small.bin", init.UploadID); err != nil {
+		t.Fatalf("Object.AbortMultipartUpload returned error: %v", err)
+	}
+	_, _, err = c.Object.ListParts(ctx, "small.bin", init.UploadID)
+	testErrorCode(t, err, "NoSuchUpload")
+}
+
+func TestServer_multiCopy(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), 250*1024)
+	_, err := c.Object.Put(ctx, "big.bin", bytes.NewReader(data), &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: "application/octet-stream",
+			XCosMetaXXX: &http.Header{"X-Cos-Meta-Test": []string{"test"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+
+	srv.CreateBucket("other-1250000000")
+	other := srv.NewClient("other-1250000000")
+	sourceURL := srv.BucketURL(testBucket).Host + "/big.bin"
+	res, _, err := other.Object.MultiCopy(ctx, "copied.bin", sourceURL, &cos.ObjectMultiCopyOptions{
+		PartSize: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("Object.MultiCopy returned error: %v", err)
+	}
+	if !strings.HasSuffix(res.ETag, `-3"`) {
+		t.Errorf("Object.MultiCopy returned %+v", res)
+	}
+	if got := getObject(t, other, "copied.bin", nil); got != string(data) {
+		t.Errorf("Object.Get returned %d bytes, want %d", len(got), len(data))
+	}
+	resp, err := other.Object.Head(ctx, "copied.bin", nil)
+	if err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if resp.Header.Get("Content-Type") != "application/octet-stream" || resp.Header.Get("X-Cos-Meta-Test") != "test" {
+		t.Errorf("Object.Head returned header %v", resp.Header)
+	}
+
+	// 源文件小于 Threshold 时使用 Object.Copy
+	res, _, err = other.Object.MultiCopy(ctx, "small.bin", sourceURL, &cos.ObjectMultiCopyOptions{
+		Threshold: int64(len(data)),
+	})
+	if err != nil {
+		t.Fatalf("Object.MultiCopy returned error: %v", err)
+	}
+	if strings.Contains(res.ETag, "-") {
+		t.Errorf("Object.MultiCopy returned %+v", res)
+	}
+
+	// 源文件的 ETag 不满足条件
+	init, _, err := other.Object.InitiateMultipartUpload(ctx, "part.bin", nil)
+	if err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+	_, _, err = other.Object.UploadPartCopy(ctx, "part.bin", init.UploadID, 1, sourceURL, &cos.ObjectCopyPartOptions{
+		XCosCopySourceIfMatch: `"other"`,
+	})
+	testErrorCode(t, err, "PreconditionFailed")
+	_, _, err = other.Object.UploadPartCopy(ctx, "part.bin", init.UploadID, 1, sourceURL, &cos.ObjectCopyPartOptions{
+		XCosCopySourceRange: "bytes=0-9",
+	})
+	if err != nil {
+		t.Fatalf("Object.UploadPartCopy returned error: %v", err)
+	}
+	lres, _, err := other.Object.ListParts(ctx, "part.bin", init.UploadID)
+	if err != nil || len(lres.Parts) != 1 || lres.Parts[0].Size != 10 {
+		t.Errorf("Object.ListParts returned %+v, %v", lres, err)
+	}
+}
+
+func TestServer_bucketConfigs(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, _, err := c.Bucket.GetCORS(ctx)
+	testErrorCode(t, err, "NoSuchCORSConfiguration")
+	rules := []cos.BucketCORSRule{{
+		AllowedOrigins: []string{"http://*.example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		MaxAgeSeconds:  600,
+	}}
+	if _, err := c.Bucket.PutCORS(ctx, &cos.BucketPutCORSOptions{Rules: rules}); err != nil {
+		t.Fatalf("Bucket.PutCORS returned error: %v", err)
+	}
+	cres, _, err := c.Bucket.GetCORS(ctx)
+	if err != nil {
+		t.Fatalf("Bucket.GetCORS returned error: %v", err)
+	}
+	if !reflect.DeepEqual(cres.Rules, rules) {
+		t.Errorf("Bucket.GetCORS returned %+v, want %+v", cres.Rules, rules)
+	}
+	resp, err := c.Object.Options(ctx, "hello.txt", &cos.ObjectOptionsOptions{
+		Origin:                     "http://www.example.com",
+		AccessControlRequestMethod: "PUT",
+	})
+	if err != nil {
+		t.Fatalf("Object.Options returned error: %v", err)
+	}
+	if v := resp.Header.Get("Access-Control-Allow-Origin"); v != "http://www.example.com" {
+		t.Errorf("Access-Control-Allow-Origin is %s", v)
+	}
+	_, err = c.Object.Options(ctx, "hello.txt", &cos.ObjectOptionsOptions{
+		Origin:                     "http://www.example.org",
+		AccessControlRequestMethod: "PUT",
+	})
+	testErrorCode(t, err, "AccessForbidden")
+
+	tags := []cos.BucketTaggingTag{{Key: "env", Value: "test"}}
+	if _, err := c.Bucket.PutTagging(ctx, &cos.BucketPutTaggingOptions{TagSet: tags}); err != nil {
+		t.Fatalf("Bucket.PutTagging returned error: %v", err)
+	}
+	tres, _, err := c.Bucket.GetTagging(ctx)
+	if err != nil {
+		t.Fatalf("Bucket.GetTagging returned error: %v", err)
+	}
+	if !reflect.DeepEqual(tres.TagSet, tags) {
+		t.Errorf("Bucket.GetTagging returned %+v", tres.TagSet)
+	}
+	c.Bucket.DeleteTagging(ctx)
+	_, _, err = c.Bucket.GetTagging(ctx)
+	testErrorCode(t, err, "NoSuchTagSet")
+
+	_, _, err = c.Bucket.GetPolicy(ctx)
+	testErrorCode(t, err, "NoSuchPolicy")
+	statements := []cos.BucketPolicyStatement{{
+		Principal: &cos.BucketPolicyPrincipal{QCS: cos.PolicyStringList{"qcs::cam::anyone:anyone"}},
+		Effect:    cos.BucketPolicyEffectAllow,
+		Action:    cos.PolicyStringList{"name/cos:GetObject"},
+		Resource:  cos.PolicyStringList{"qcs::cos:ap-guangzhou:uid/1250000000:test-1250000000/*"},
+	}}
+	if _, err := c.Bucket.PutPolicy(ctx, &cos.BucketPutPolicyOptions{Statement: statements}); err != nil {
+		t.Fatalf("Bucket.PutPolicy returned error: %v", err)
+	}
+	pres, resp, err := c.Bucket.GetPolicy(ctx)
+	if err != nil {
+		t.Fatalf("Bucket.GetPolicy returned error: %v", err)
+	}
+	if pres.Version != cos.BucketPolicyVersion || !reflect.DeepEqual(pres.Statement, statements) {
+		t.Errorf("Bucket.GetPolicy returned %+v", pres)
+	}
+	if v := resp.Header.Get("Content-Type"); v != "application/json" {
+		t.Errorf("Bucket.GetPolicy Content-Type is %s", v)
+	}
+	c.Bucket.DeletePolicy(ctx)
+	_, _, err = c.Bucket.GetPolicy(ctx)
+	testErrorCode(t, err, "NoSuchPolicy")
+
+	_, _, err = c.Bucket.GetWebsite(ctx)
+	testErrorCode(t, err, "NoSuchWebsiteConfiguration")
+	index := &cos.BucketWebsiteIndexDocument{Suffix: "index.html"}
+	if _, err := c.Bucket.PutWebsite(ctx, &cos.BucketPutWebsiteOptions{IndexDocument: index}); err != nil {
+		t.Fatalf("Bucket.PutWebsite returned error: %v", err)
+	}
+	wres, _, err := c.Bucket.GetWebsite(ctx)
+	if err != nil || !reflect.DeepEqual(wres.IndexDocument, index) {
+		t.Errorf("Bucket.GetWebsite returned %+v, %v", wres, err)
+	}
+
+	_, _, err = c.Bucket.GetEncryption(ctx)
+	testErrorCode(t, err, "NoSuchEncryptionConfiguration")
+	erules := []cos.BucketEncryptionRule{{
+		ApplyServerSideEncryptionByDefault: cos.BucketApplyServerSideEncryptionByDefault{SSEAlgorithm: cos.ServerSideEncryptionAES256},
+	}}
+	if _, err := c.Bucket.PutEncryption(ctx, &cos.BucketPutEncryptionOptions{Rules: erules}); err != nil {
+		t.Fatalf("Bucket.PutEncryption returned error: %v", err)
+	}
+	eres, _, err := c.Bucket.GetEncryption(ctx)
+	if err != nil || !reflect.DeepEqual(eres.Rules, erules) {
+		t.Errorf("Bucket.GetEncryption returned %+v, %v", eres, err)
+	}
+	c.Bucket.DeleteEncryption(ctx)
+	_, _, err = c.Bucket.GetEncryption(ctx)
+	testErrorCode(t, err, "NoSuchEncryptionConfiguration")
+
+	logging := &cos.BucketLoggingEnabled{TargetBucket: "logs-1250000000", TargetPrefix: "test/"}
+	_, err = c.Bucket.PutLogging(ctx, &cos.BucketPutLoggingOptions{LoggingEnabled: logging})
+	testErrorCode(t, err, "InvalidTargetBucketForLogging")
+	srv.CreateBucket(logging.TargetBucket)
+	if _, err := c.Bucket.PutLogging(ctx, &cos.BucketPutLoggingOptions{LoggingEnabled: logging}); err != nil {
+		t.Fatalf("Bucket.PutLogging returned error: %v", err)
+	}
+	gres, _, err := c.Bucket.GetLogging(ctx)
+	if err != nil || !reflect.DeepEqual(gres.LoggingEnabled, logging) {
+		t.Errorf("Bucket.GetLogging returned %+v, %v", gres, err)
+	}
+	c.Bucket.PutLogging(ctx, nil)
+	gres, _, err = c.Bucket.GetLogging(ctx)
+	if err != nil || gres.LoggingEnabled != nil {
+		t.Errorf("Bucket.GetLogging returned %+v, %v", gres, err)
+	}
+
+	lres, _, err := c.Bucket.GetLocation(ctx)
+	if err != nil || lres.Location != srv.Region {
+		t.Errorf("Bucket.GetLocation returned %+v, %v", lres, err)
+	}
+
+	if _, err := c.Bucket.PutACL(ctx, &cos.BucketPutACLOptions{
+		Header: &cos.ACLHeaderOptions{XCosACL: "public-read"},
+	}); err != nil {
+		t.Fatalf("Bucket.PutACL returned error: %v", err)
+	}
+	ares, _, err := c.Bucket.GetACL(ctx)
+	if err != nil || len(ares.AccessControlList) == 0 {
+		t.Errorf("Bucket.GetACL returned %+v, %v", ares, err)
+	}
+}
+
+func TestServer_versioning(t *testing.T) {
+	srv, c := setup(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	res, _, err := c.Bucket.GetVersioning(ctx)
+	if err != nil || res.Status != "" {
+		t.Errorf("Bucket.GetVersioning returned %+v, %v", res, err)
+	}
+	ropt := &cos.BucketPutReplicationOptions{
+		Role: "qcs::cam::uin/100000000001:uin/100000000001",
+		Rules: []cos.BucketReplicationRule{{
+			ID:          "rule1",
+			Status:      "Enabled",
+			Destination: &cos.BucketReplicationDestination{Bucket: "qcs::cos:ap-beijing::backup-1250000000"},
+		}},
+	}
+	_, err = c.Bucket.PutReplication(ctx, ropt)
+	testErrorCode(t, err, "InvalidRequest")
+	if _, err := c.Bucket.PutVersioning(ctx, &cos.BucketPutVersioningOptions{
+		Status: cos.VersioningStatusEnabled,
+	}); err != nil {
+		t.Fatalf("Bucket.PutVersioning returned error: %v", err)
+	}
+	res, _, err = c.Bucket.GetVersioning(ctx)
+	if err != nil || res.Status != cos.VersioningStatusEnabled {
+		t.Errorf("Bucket.GetVersioning returned %+v, %v", res, err)
+	}
+	if _, err := c.Bucket.PutReplication(ctx, ropt); err != nil {
+		t.Fatalf("Bucket.PutReplication returned error: %v", err)
+	}
+	rres, _, err := c.Bucket.GetReplication(ctx)
+	if err != nil || rres.Role != ropt.Role || !reflect.DeepEqual(rres.Rules, ropt.Rules) {
+		t.Errorf("Bucket.GetReplication returned %+v, %v", rres, err)
+	}
+	c.Bucket.DeleteReplication(ctx)
+	_, _, err = c.Bucket.GetReplication(ctx)
+	testErrorCode(t, err, "ReplicationConfigurationnotFoundError")
+
+	putObject(t, c, "a.txt", "a")
+	putObject(t, c, "b.txt", "b")
+	vres, _, err := c.Bucket.ListObjectVersions(ctx, &cos.BucketListObjectVersionsOptions{MaxKeys: 1})
+	if err != nil {
+		t.Fatalf("Bucket.ListObjectVersions returned error: %v", err)
+	}
+	if len(vres.Versions) != 1 || vres.Versions[0].Key != "a.txt" || !vres.IsTruncated || vres.NextKeyMarker != "a.txt" {
+		t.Errorf("Bucket.ListObjectVersions returned %+v", vres)
+	}
+}
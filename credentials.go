@@ -0,0 +1,338 @@
+package cos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials 访问 COS 使用的密钥
+type Credentials struct {
+	SecretID  string
+	SecretKey string
+	// 临时密钥: https://cloud.tencent.com/document/product/436/14048
+	SessionToken string
+	// 临时密钥的过期时间，零值表示不会过期
+	Expiration time.Time
+}
+
+// expired 密钥是否会在 window 时间内过期
+func (c *Credentials) expired(window time.Duration) bool {
+	return !c.Expiration.IsZero() && !time.Now().Add(window).Before(c.Expiration)
+}
+
+// CredentialProvider 用于获取访问 COS 使用的密钥，AuthorizationTransport 在每次发送请求前都会调用 Credentials 方法。
+//
+// 获取密钥的开销比较大时（比如需要请求 STS 或者 CVM 元数据服务），可以使用 CachedCredentialProvider 缓存获取到的密钥。
+// 实现需要支持并发调用。
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// ErrNoCredentials 没有找到可用的密钥
+var ErrNoCredentials = errors.New("cos: no valid credentials found")
+
+// StaticCredentialProvider 总是返回固定的密钥
+type StaticCredentialProvider struct {
+	SecretID     string
+	SecretKey    string
+	SessionToken string
+}
+
+// Credentials implements the CredentialProvider interface.
+func (p *StaticCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	if p.SecretID == "" || p.SecretKey == "" {
+		return nil, ErrNoCredentials
+	}
+	return &Credentials{
+		SecretID:     p.SecretID,
+		SecretKey:    p.SecretKey,
+		SessionToken: p.SessionToken,
+	}, nil
+}
+
+// EnvCredentialProvider 从环境变量中读取密钥。
+//
+// 默认使用环境变量 COS_SECRETID、COS_SECRETKEY 和 COS_SESSIONTOKEN（可选）。
+type EnvCredentialProvider struct {
+	// SecretID 的环境变量名，默认值：COS_SECRETID
+	SecretIDEnv string
+	// SecretKey 的环境变量名，默认值：COS_SECRETKEY
+	SecretKeyEnv string
+	// SessionToken 的环境变量名，默认值：COS_SESSIONTOKEN
+	SessionTokenEnv string
+}
+
+// Credentials implements the CredentialProvider interface.
+func (p *EnvCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	getenv := func(name, defaultName string) string {
+		if name == "" {
+			name = defaultName
+		}
+		return os.Getenv(name)
+	}
+	return (&StaticCredentialProvider{
+		SecretID:     getenv(p.SecretIDEnv, "COS_SECRETID"),
+		SecretKey:    getenv(p.SecretKeyEnv, "COS_SECRETKEY"),
+		SessionToken: getenv(p.SessionTokenEnv, "COS_SESSIONTOKEN"),
+	}).Credentials(ctx)
+}
+
+// FileCredentialProvider 从 INI 格式的密钥文件中读取密钥，每次调用都会重新读取文件，比如：
+//
+//	[default]
+//	secret_id = AKIDxxx
+//	secret_key = xxx
+//	# 可选
+//	session_token = xxx
+//
+//	[other]
+//	secret_id = AKIDyyy
+//	secret_key = yyy
+type FileCredentialProvider struct {
+	// 密钥文件的路径，默认值：环境变量 COS_CREDENTIALS_FILE 或 ~/.cos/credentials
+	Filename string
+	// 使用的配置名称，默认值：环境变量 COS_PROFILE 或 default
+	Profile string
+}
+
+// Credentials implements the CredentialProvider interface.
+func (p *FileCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	values, err := p.ProfileValues()
+	if err != nil {
+		return nil, err
+	}
+	c, err := (&StaticCredentialProvider{
+		SecretID:     values["secret_id"],
+		SecretKey:    values["secret_key"],
+		SessionToken: values["session_token"],
+	}).Credentials(ctx)
+	if err != nil {
+		filename, profile := p.location()
+		return nil, fmt.Errorf("cos: no valid credentials found in profile %s of %s", profile, filename)
+	}
+	return c, nil
+}
+
+// ProfileValues 返回密钥文件中 p.Profile 配置下所有的键值对，除了密钥之外还可以包含其他的配置（比如 region）。
+// 配置不存在时返回空的结果，文件不存在或者无法读取时返回错误
+func (p *FileCredentialProvider) ProfileValues() (map[string]string, error) {
+	filename, profile := p.location()
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			section = strings.TrimSpace(line[1 : len(line)-1])
+		case section == profile:
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) == 2 {
+				values[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// location 返回密钥文件的路径和使用的配置名称
+func (p *FileCredentialProvider) location() (filename, profile string) {
+	filename = p.Filename
+	if filename == "" {
+		filename = os.Getenv("COS_CREDENTIALS_FILE")
+	}
+	if filename == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			home = os.Getenv("USERPROFILE")
+		}
+		filename = filepath.Join(home, ".cos", "credentials")
+	}
+	profile = p.Profile
+	if profile == "" {
+		profile = os.Getenv("COS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	return filename, profile
+}
+
+// DefaultCVMCredentialsURL 云服务器（CVM）元数据服务中获取 CAM 角色临时密钥的地址
+const DefaultCVMCredentialsURL = "http://metadata.tencentyun.com/latest/meta-data/cam/security-credentials/"
+
+// CVMCredentialProvider 从云服务器（CVM）的元数据服务中获取绑定的 CAM 角色的临时密钥，
+// 每次调用都会请求元数据服务，一般需要配合 CachedCredentialProvider 使用。
+//
+// https://cloud.tencent.com/document/product/213/47668
+type CVMCredentialProvider struct {
+	// 元数据服务的地址，默认值：DefaultCVMCredentialsURL
+	Endpoint string
+	// CAM 角色的名称，为空时通过 Endpoint 查询云服务器绑定的角色
+	RoleName string
+	// 请求元数据服务使用的 http.Client，默认使用超时时间为 5 秒的 http.Client
+	Client *http.Client
+}
+
+// cvmCredentials 元数据服务返回的临时密钥
+type cvmCredentials struct {
+	TmpSecretID  string `json:"TmpSecretId"`
+	TmpSecretKey string `json:"TmpSecretKey"`
+	Token        string `json:"Token"`
+	ExpiredTime  int64  `json:"ExpiredTime"`
+	Code         string `json:"Code"`
+}
+
+// Credentials implements the CredentialProvider interface.
+func (p *CVMCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultCVMCredentialsURL
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/") + "/"
+	role := p.RoleName
+	if role == "" {
+		b, err := p.get(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		role = strings.TrimSpace(strings.SplitN(string(b), "\n", 2)[0])
+		if role == "" {
+			return nil, errors.New("cos: no CAM role is bound to the CVM instance")
+		}
+	}
+
+	b, err := p.get(ctx, endpoint+role)
+	if err != nil {
+		return nil, err
+	}
+	var v cvmCredentials
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("cos: invalid CVM credentials: %v", err)
+	}
+	if v.Code != "" && v.Code != "Success" {
+		return nil, fmt.Errorf("cos: failed to get CVM credentials: %s", v.Code)
+	}
+	if v.TmpSecretID == "" || v.TmpSecretKey == "" {
+		return nil, ErrNoCredentials
+	}
+	c := &Credentials{
+		SecretID:     v.TmpSecretID,
+		SecretKey:    v.TmpSecretKey,
+		SessionToken: v.Token,
+	}
+	if v.ExpiredTime > 0 {
+		c.Expiration = time.Unix(v.ExpiredTime, 0)
+	}
+	return c, nil
+}
+
+func (p *CVMCredentialProvider) get(ctx context.Context, u string) ([]byte, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cos: GET %s: %s", u, resp.Status)
+	}
+	return b, nil
+}
+
+// ChainCredentialProvider 依次调用各个 CredentialProvider，返回第一个成功获取到的密钥
+type ChainCredentialProvider []CredentialProvider
+
+// Credentials implements the CredentialProvider interface.
+func (p ChainCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	var errs []string
+	for _, provider := range p {
+		c, err := provider.Credentials(ctx)
+		if err == nil {
+			return c, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	if len(errs) == 0 {
+		return nil, ErrNoCredentials
+	}
+	return nil, fmt.Errorf("%s: %s", ErrNoCredentials, strings.Join(errs, "; "))
+}
+
+// 默认在临时密钥过期前 5 分钟刷新
+const defaultCredentialsExpiryWindow = 5 * time.Minute
+
+// CachedCredentialProvider 缓存 Provider 返回的密钥，在密钥过期前 ExpiryWindow 时间重新获取。
+// 没有过期时间的密钥会一直被缓存。
+//
+// 可以被多个 goroutine 并发使用，同一时间只会有一个 goroutine 调用 Provider 刷新密钥。
+type CachedCredentialProvider struct {
+	Provider CredentialProvider
+	// 提前多久刷新密钥，默认值：5 分钟
+	ExpiryWindow time.Duration
+
+	mu          sync.Mutex
+	credentials *Credentials
+}
+
+// Credentials implements the CredentialProvider interface.
+func (p *CachedCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	window := p.ExpiryWindow
+	if window <= 0 {
+		window = defaultCredentialsExpiryWindow
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.credentials != nil && !p.credentials.expired(window) {
+		return p.credentials, nil
+	}
+	c, err := p.Provider.Credentials(ctx)
+	if err != nil {
+		// 刷新失败时继续使用还没有过期的密钥
+		if p.credentials != nil && !p.credentials.expired(0) {
+			return p.credentials, nil
+		}
+		return nil, err
+	}
+	p.credentials = c
+	return c, nil
+}
+
+// Expire 使缓存的密钥失效，下一次调用 Credentials 时会重新获取密钥
+func (p *CachedCredentialProvider) Expire() {
+	p.mu.Lock()
+	p.credentials = nil
+	p.mu.Unlock()
+}
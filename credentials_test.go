@@ -0,0 +1,256 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStaticCredentialProvider(t *testing.T) {
+	p := &StaticCredentialProvider{SecretID: "id", SecretKey: "key", SessionToken: "token"}
+	c, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("StaticCredentialProvider.Credentials returned error: %v", err)
+	}
+	want := &Credentials{SecretID: "id", SecretKey: "key", SessionToken: "token"}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("StaticCredentialProvider.Credentials returned %+v, want %+v", c, want)
+	}
+
+	if _, err := (&StaticCredentialProvider{SecretID: "id"}).Credentials(context.Background()); err != ErrNoCredentials {
+		t.Errorf("StaticCredentialProvider.Credentials returned error %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestEnvCredentialProvider(t *testing.T) {
+	for k, v := range map[string]string{
+		"COS_SECRETID":     "id",
+		"COS_SECRETKEY":    "key",
+		"COS_SESSIONTOKEN": "",
+		"TEST_SECRETID":    "id2",
+		"TEST_SECRETKEY":   "key2",
+		"TEST_TOKEN":       "token2",
+	} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+
+	c, err := (&EnvCredentialProvider{}).Credentials(context.Background())
+	if err != nil || c.SecretID != "id" || c.SecretKey != "key" || c.SessionToken != "" {
+		t.Errorf("EnvCredentialProvider.Credentials returned %+v, %v", c, err)
+	}
+	p := &EnvCredentialProvider{SecretIDEnv: "TEST_SECRETID", SecretKeyEnv: "TEST_SECRETKEY", SessionTokenEnv: "TEST_TOKEN"}
+	c, err = p.Credentials(context.Background())
+	if err != nil || c.SecretID != "id2" || c.SecretKey != "key2" || c.SessionToken != "token2" {
+		t.Errorf("EnvCredentialProvider.Credentials returned %+v, %v", c, err)
+	}
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cos-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "credentials")
+	ioutil.WriteFile(filename, []byte(`# comment
+[default]
+secret_id = id
+secret_key = key
+
+[test]
+secret_id=id2
+secret_key=key2
+session_token = token2
+region = ap-guangzhou
+
+[empty]
+secret_id = id3
+`), 0600)
+
+	c, err := (&FileCredentialProvider{Filename: filename}).Credentials(context.Background())
+	if err != nil || c.SecretID != "id" || c.SecretKey != "key" || c.SessionToken != "" {
+		t.Errorf("FileCredentialProvider.Credentials returned %+v, %v", c, err)
+	}
+	c, err = (&FileCredentialProvider{Filename: filename, Profile: "test"}).Credentials(context.Background())
+	if err != nil || c.SecretID != "id2" || c.SecretKey != "key2" || c.SessionToken != "token2" {
+		t.Errorf("FileCredentialProvider.Credentials returned %+v, %v", c, err)
+	}
+	for _, profile := range []string{"empty", "notexist"} {
+		if _, err := (&FileCredentialProvider{Filename: filename, Profile: profile}).Credentials(context.Background()); err == nil {
+			t.Errorf("FileCredentialProvider.Credentials with profile %s should return error", profile)
+		}
+	}
+	if _, err := (&FileCredentialProvider{Filename: filepath.Join(dir, "notexist")}).Credentials(context.Background()); err == nil {
+		t.Error("FileCredentialProvider.Credentials should return error when file does not exist")
+	}
+
+	values, err := (&FileCredentialProvider{Filename: filename, Profile: "test"}).ProfileValues()
+	want := map[string]string{"secret_id": "id2", "secret_key": "key2", "session_token": "token2", "region": "ap-guangzhou"}
+	if err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("FileCredentialProvider.ProfileValues returned %v, %v, want %v", values, err, want)
+	}
+	if values, err := (&FileCredentialProvider{Filename: filename, Profile: "notexist"}).ProfileValues(); err != nil || len(values) != 0 {
+		t.Errorf("FileCredentialProvider.ProfileValues returned %v, %v", values, err)
+	}
+}
+
+func TestCVMCredentialProvider(t *testing.T) {
+	expired := time.Now().Add(time.Hour).Unix()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/security-credentials/":
+			fmt.Fprint(w, "test-role")
+		case "/security-credentials/test-role":
+			fmt.Fprintf(w, `{"TmpSecretId":"id","TmpSecretKey":"key","Token":"token","ExpiredTime":%d,"Code":"Success"}`, expired)
+		case "/security-credentials/failed-role":
+			fmt.Fprint(w, `{"Code":"Failed"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	p := &CVMCredentialProvider{Endpoint: ts.URL + "/security-credentials"}
+	c, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("CVMCredentialProvider.Credentials returned error: %v", err)
+	}
+	want := &Credentials{SecretID: "id", SecretKey: "key", SessionToken: "token", Expiration: time.Unix(expired, 0)}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("CVMCredentialProvider.Credentials returned %+v, want %+v", c, want)
+	}
+
+	for _, role := range []string{"failed-role", "notexist"} {
+		p := &CVMCredentialProvider{Endpoint: ts.URL + "/security-credentials/", RoleName: role}
+		if _, err := p.Credentials(context.Background()); err == nil {
+			t.Errorf("CVMCredentialProvider.Credentials with role %s should return error", role)
+		}
+	}
+}
+
+func TestChainCredentialProvider(t *testing.T) {
+	p := ChainCredentialProvider{
+		&StaticCredentialProvider{},
+		&StaticCredentialProvider{SecretID: "id", SecretKey: "key"},
+	}
+	c, err := p.Credentials(context.Background())
+	if err != nil || c.SecretID != "id" {
+		t.Errorf("ChainCredentialProvider.Credentials returned %+v, %v", c, err)
+	}
+	if _, err := p[:1].Credentials(context.Background()); err == nil {
+		t.Error("ChainCredentialProvider.Credentials should return error")
+	}
+}
+
+// countingCredentialProvider 每次调用都返回新的密钥
+type countingCredentialProvider struct {
+	mu     sync.Mutex
+	called int
+	expire time.Duration
+	err    error
+}
+
+func (p *countingCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.called++
+	return &Credentials{
+		SecretID:     fmt.Sprintf("id-%d", p.called),
+		SecretKey:    "key",
+		SessionToken: fmt.Sprintf("token-%d", p.called),
+		Expiration:   time.Now().Add(p.expire),
+	}, nil
+}
+
+func TestCachedCredentialProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingCredentialProvider{expire: time.Hour}
+	p := &CachedCredentialProvider{Provider: provider}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c, err := p.Credentials(ctx); err != nil || c.SecretID != "id-1" {
+				t.Errorf("CachedCredentialProvider.Credentials returned %+v, %v", c, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if provider.called != 1 {
+		t.Errorf("Provider called %d times, want 1", provider.called)
+	}
+
+	p.Expire()
+	if c, _ := p.Credentials(ctx); c.SecretID != "id-2" {
+		t.Errorf("CachedCredentialProvider.Credentials returned %+v", c)
+	}
+
+	// 在过期时间前 ExpiryWindow 刷新
+	provider.expire = 3 * time.Minute
+	p.Expire()
+	p.Credentials(ctx)
+	if c, _ := p.Credentials(ctx); c.SecretID != "id-4" {
+		t.Errorf("CachedCredentialProvider.Credentials returned %+v", c)
+	}
+
+	// 刷新失败时继续使用还没有过期的密钥
+	provider.err = fmt.Errorf("failed")
+	if c, err := p.Credentials(ctx); err != nil || c.SecretID != "id-4" {
+		t.Errorf("CachedCredentialProvider.Credentials returned %+v, %v", c, err)
+	}
+	p.Expire()
+	if _, err := p.Credentials(ctx); err != provider.err {
+		t.Errorf("CachedCredentialProvider.Credentials returned error %v, want %v", err, provider.err)
+	}
+}
+
+func TestAuthorizationTransport_CredentialProvider(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var tokens []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), "q-ak=id-") {
+			t.Errorf("Authorization header: %s", r.Header.Get("Authorization"))
+		}
+		tokens = append(tokens, r.Header.Get("x-cos-security-token"))
+	})
+
+	provider := &countingCredentialProvider{expire: time.Hour}
+	cached := &CachedCredentialProvider{Provider: provider}
+	(client.Sender).(*DefaultSender).Transport = &AuthorizationTransport{
+		SecretID:           "ignored",
+		CredentialProvider: cached,
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Service.Get(context.Background()); err != nil {
+			t.Fatalf("Service.Get returned error: %v", err)
+		}
+	}
+	cached.Expire()
+	client.Service.Get(context.Background())
+	if want := []string{"token-1", "token-1", "token-2"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("x-cos-security-token headers: %v, want %v", tokens, want)
+	}
+
+	provider.err = ErrNoCredentials
+	cached.Expire()
+	if _, _, err := client.Service.Get(context.Background()); err == nil || !strings.Contains(err.Error(), ErrNoCredentials.Error()) {
+		t.Errorf("Service.Get returned error %v, want %v", err, ErrNoCredentials)
+	}
+}
@@ -46,3 +46,11 @@ func checkResponse(r *http.Response) error {
 	}
 	return errorResponse
 }
+
+// errorStatusCode 返回 err 对应的 HTTP 状态码，err 不是 COS 返回的 ErrorResponse 时返回 0
+func errorStatusCode(err error) int {
+	if e, ok := err.(*ErrorResponse); ok && e.Response != nil {
+		return e.Response.StatusCode
+	}
+	return 0
+}
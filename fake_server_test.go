@@ -0,0 +1,431 @@
+package cos
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"hash/crc64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer 在 mux 上模拟 Object 的上传、下载、复制、分块上传以及 Get Bucket 等 API，
+// 供 Upload、Download、MultiCopy、NewReader 和 FS 等方法的测试共用。
+//
+// 处理过的请求会按照 API 名称（比如 PutObject、UploadPart）记录下来，用于检查请求的次数和参数。
+type fakeServer struct {
+	t  *testing.T
+	mu sync.Mutex
+
+	objects map[string]*fakeObject
+	uploads map[string]*fakeUpload
+	// 已经初始化的分块上传的数量，用于生成 UploadID
+	initiated int
+	log       []*fakeRequest
+
+	// 每次 Get Bucket 最多返回的条目数量，默认值：1000
+	maxKeys int
+	// 不返回 NextMarker（使用了 Delimiter 时 COS 可能不返回 NextMarker）
+	noNextMarker bool
+	// 忽略 Range 头部，返回完整的内容
+	ignoreRange bool
+	// 不为 nil 时在处理请求前调用，返回的错误不为 nil 时直接返回该错误，用于模拟请求失败
+	fail func(op string, r *http.Request) *ErrorResponse
+}
+
+type fakeObject struct {
+	data   []byte
+	etag   string
+	header http.Header
+}
+
+type fakeUpload struct {
+	key    string
+	header http.Header
+	parts  map[int][]byte
+}
+
+// fakeRequest 一次请求的 API 名称和请求头部、参数
+type fakeRequest struct {
+	op     string
+	header http.Header
+	query  url.Values
+}
+
+// fakeModTime 所有 Object 的修改时间
+var fakeModTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{
+		t:       t,
+		objects: map[string]*fakeObject{},
+		uploads: map[string]*fakeUpload{},
+	}
+	mux.HandleFunc("/", s.handle)
+	return s
+}
+
+func fakeETag(data []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(data))
+}
+
+// fakeMultipartETag 返回由 n 个分块组成的 Object 的 ETag
+func fakeMultipartETag(data []byte, n int) string {
+	return fmt.Sprintf(`"%x-%d"`, md5.Sum(data), n)
+}
+
+// putObject 直接在服务端创建 Object，header 为 Head/Get Object 时返回的额外头部
+func (s *fakeServer) putObject(key string, data []byte, header http.Header) *fakeObject {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := &fakeObject{data: data, etag: fakeETag(data), header: header}
+	s.objects[key] = o
+	return o
+}
+
+// object 返回 Object 的内容，Object 不存在时返回 nil
+func (s *fakeServer) object(key string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o := s.objects[key]; o != nil {
+		return o.data
+	}
+	return nil
+}
+
+// requests 返回 op 对应的请求
+func (s *fakeServer) requests(op string) []*fakeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var reqs []*fakeRequest
+	for _, r := range s.log {
+		if r.op == op {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// count 返回 op 对应的请求次数
+func (s *fakeServer) count(op string) int {
+	return len(s.requests(op))
+}
+
+// ranges 返回 GetObject 请求的 Range 头部
+func (s *fakeServer) ranges() []string {
+	var ranges []string
+	for _, r := range s.requests("GetObject") {
+		ranges = append(ranges, r.header.Get("Range"))
+	}
+	return ranges
+}
+
+// clearLog 清空已记录的请求
+func (s *fakeServer) clearLog() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = nil
+}
+
+// parts 返回分块上传中已上传的分块
+func (s *fakeServer) parts(uploadID string) map[int][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u := s.uploads[uploadID]; u != nil {
+		return u.parts
+	}
+	return nil
+}
+
+// failPart 让编号为 n 的 UploadPart 和 UploadPartCopy 请求返回 500 错误
+func (s *fakeServer) failPart(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = func(op string, r *http.Request) *ErrorResponse {
+		if (op == "UploadPart" || op == "UploadPartCopy") && r.URL.Query().Get("partNumber") == strconv.Itoa(n) {
+			return fakeError(http.StatusInternalServerError, "InternalError")
+		}
+		return nil
+	}
+}
+
+// failRange 让 Range 头部为 rg 的 GetObject 请求前 times 次返回 500 错误
+func (s *fakeServer) failRange(rg string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = func(op string, r *http.Request) *ErrorResponse {
+		if op == "GetObject" && r.Header.Get("Range") == rg && times > 0 {
+			times--
+			return fakeError(http.StatusInternalServerError, "InternalError")
+		}
+		return nil
+	}
+}
+
+func fakeError(status int, code string) *ErrorResponse {
+	return &ErrorResponse{
+		Response: &http.Response{StatusCode: status},
+		Code:     code,
+	}
+}
+
+// operation 返回请求对应的 API 名称
+func operation(r *http.Request) string {
+	q := r.URL.Query()
+	_, uploads := q["uploads"]
+	copySource := r.Header.Get("x-cos-copy-source") != ""
+	switch {
+	case r.URL.Path == "/" && r.Method == http.MethodGet:
+		return "GetBucket"
+	case r.Method == http.MethodPost && uploads:
+		return "InitiateMultipartUpload"
+	case r.Method == http.MethodPut && q.Get("partNumber") != "" && copySource:
+		return "UploadPartCopy"
+	case r.Method == http.MethodPut && q.Get("partNumber") != "":
+		return "UploadPart"
+	case r.Method == http.MethodPost && q.Get("uploadId") != "":
+		return "CompleteMultipartUpload"
+	case r.Method == http.MethodGet && q.Get("uploadId") != "":
+		return "ListParts"
+	case r.Method == http.MethodDelete && q.Get("uploadId") != "":
+		return "AbortMultipartUpload"
+	case r.Method == http.MethodPut && copySource:
+		return "CopyObject"
+	case r.Method == http.MethodPut:
+		return "PutObject"
+	case r.Method == http.MethodHead:
+		return "HeadObject"
+	case r.Method == http.MethodGet:
+		return "GetObject"
+	case r.Method == http.MethodDelete:
+		return "DeleteObject"
+	}
+	return ""
+}
+
+func (s *fakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	op := operation(r)
+	s.mu.Lock()
+	s.log = append(s.log, &fakeRequest{op: op, header: r.Header, query: r.URL.Query()})
+	var e *ErrorResponse
+	if s.fail != nil {
+		e = s.fail(op, r)
+	}
+	s.mu.Unlock()
+	if e != nil {
+		writeFakeError(w, e)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch op {
+	case "GetBucket":
+		e = s.list(w, r.URL.Query())
+	case "HeadObject", "GetObject":
+		e = s.get(w, r, key)
+	case "PutObject":
+		o := &fakeObject{data: body, etag: fakeETag(body)}
+		s.objects[key] = o
+		w.Header().Set("ETag", o.etag)
+	case "CopyObject":
+		src, e2 := s.copySource(r)
+		if e = e2; e == nil {
+			o := &fakeObject{data: src.data, etag: src.etag, header: src.header}
+			s.objects[key] = o
+			fmt.Fprintf(w, `<CopyObjectResult><ETag>%s</ETag></CopyObjectResult>`, o.etag)
+		}
+	case "DeleteObject":
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case "InitiateMultipartUpload":
+		s.initiated++
+		id := "upload-id"
+		if s.initiated > 1 {
+			id = fmt.Sprintf("upload-id-%d", s.initiated)
+		}
+		s.uploads[id] = &fakeUpload{key: key, header: r.Header, parts: map[int][]byte{}}
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, id)
+	default:
+		e = s.handleUpload(w, r, op, key, body)
+	}
+	if e != nil {
+		writeFakeError(w, e)
+	}
+}
+
+// handleUpload 处理 UploadID 对应的分块上传的请求
+func (s *fakeServer) handleUpload(w http.ResponseWriter, r *http.Request, op, key string, body []byte) *ErrorResponse {
+	q := r.URL.Query()
+	u := s.uploads[q.Get("uploadId")]
+	if u == nil || u.key != key {
+		return fakeError(http.StatusNotFound, "NoSuchUpload")
+	}
+	n, _ := strconv.Atoi(q.Get("partNumber"))
+	switch op {
+	case "UploadPart":
+		if int64(len(body)) != r.ContentLength {
+			s.t.Errorf("UploadPart body length is %d, want %d", len(body), r.ContentLength)
+		}
+		u.parts[n] = body
+		w.Header().Set("ETag", fakeETag(body))
+	case "UploadPartCopy":
+		src, e := s.copySource(r)
+		if e != nil {
+			return e
+		}
+		data := src.data
+		if v := r.Header.Get("x-cos-copy-source-range"); v != "" {
+			var start, end int
+			if _, err := fmt.Sscanf(v, "bytes=%d-%d", &start, &end); err != nil || end >= len(data) || start > end {
+				return fakeError(http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			}
+			data = data[start : end+1]
+		}
+		u.parts[n] = data
+		fmt.Fprintf(w, `<CopyPartResult><ETag>%s</ETag></CopyPartResult>`, fakeETag(data))
+	case "ListParts":
+		res := ObjectListPartsResult{UploadID: q.Get("uploadId")}
+		for n, b := range u.parts {
+			res.Parts = append(res.Parts, Object{PartNumber: n, ETag: fakeETag(b), Size: len(b)})
+		}
+		sort.Sort(objectsByPartNumber(res.Parts))
+		xml.NewEncoder(w).Encode(res)
+	case "CompleteMultipartUpload":
+		v := new(CompleteMultipartUploadOptions)
+		xml.NewDecoder(bytes.NewReader(body)).Decode(v)
+		var data []byte
+		for i, p := range v.Parts {
+			b, ok := u.parts[p.PartNumber]
+			if !ok || p.ETag != fakeETag(b) {
+				return fakeError(http.StatusBadRequest, "InvalidPart")
+			}
+			if i > 0 && p.PartNumber <= v.Parts[i-1].PartNumber {
+				return fakeError(http.StatusBadRequest, "InvalidPartOrder")
+			}
+			data = append(data, b...)
+		}
+		o := &fakeObject{data: data, etag: fakeMultipartETag(data, len(v.Parts))}
+		s.objects[key] = o
+		delete(s.uploads, q.Get("uploadId"))
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><ETag>%s</ETag></CompleteMultipartUploadResult>`, o.etag)
+	case "AbortMultipartUpload":
+		delete(s.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return nil
+}
+
+// copySource 返回 x-cos-copy-source 指定的源 Object，并校验 x-cos-copy-source-If-Match
+func (s *fakeServer) copySource(r *http.Request) (*fakeObject, *ErrorResponse) {
+	source := r.Header.Get("x-cos-copy-source")
+	i := strings.Index(source, "/")
+	if i < 0 {
+		return nil, fakeError(http.StatusBadRequest, "InvalidArgument")
+	}
+	key, err := url.PathUnescape(source[i+1:])
+	if err != nil {
+		return nil, fakeError(http.StatusBadRequest, "InvalidArgument")
+	}
+	src := s.objects[key]
+	if src == nil {
+		return nil, fakeError(http.StatusNotFound, "NoSuchKey")
+	}
+	if v := r.Header.Get("x-cos-copy-source-If-Match"); v != "" && v != src.etag {
+		return nil, fakeError(http.StatusPreconditionFailed, "PreconditionFailed")
+	}
+	return src, nil
+}
+
+// get 处理 Head/Get Object，支持 Range 和 If-Match
+func (s *fakeServer) get(w http.ResponseWriter, r *http.Request, key string) *ErrorResponse {
+	o := s.objects[key]
+	if o == nil {
+		return fakeError(http.StatusNotFound, "NoSuchKey")
+	}
+	if v := r.Header.Get("If-Match"); v != "" && v != o.etag {
+		return fakeError(http.StatusPreconditionFailed, "PreconditionFailed")
+	}
+	if s.ignoreRange {
+		r.Header.Del("Range")
+	}
+	h := w.Header()
+	for k, v := range o.header {
+		h[k] = v
+	}
+	h.Set("ETag", o.etag)
+	h.Set(xCosHashCRC64ECMA, strconv.FormatUint(crc64.Checksum(o.data, crc64.MakeTable(crc64.ECMA)), 10))
+	http.ServeContent(w, r, "", fakeModTime, bytes.NewReader(o.data))
+	return nil
+}
+
+// list 处理支持 Prefix/Delimiter/Marker 的 Get Bucket
+func (s *fakeServer) list(w http.ResponseWriter, q url.Values) *ErrorResponse {
+	prefix, delimiter, marker := q.Get("prefix"), q.Get("delimiter"), q.Get("marker")
+	maxKeys := s.maxKeys
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+	// Marker 是 Common Prefix 时跳过该前缀下所有的 Object
+	skipPrefix := ""
+	if delimiter != "" && strings.HasSuffix(marker, delimiter) {
+		skipPrefix = marker
+	}
+	var keys []string
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := &BucketGetResult{Prefix: prefix, Delimiter: delimiter}
+	seen := map[string]bool{}
+	n := 0
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) || k <= marker || (skipPrefix != "" && strings.HasPrefix(k, skipPrefix)) {
+			continue
+		}
+		if n == maxKeys {
+			res.IsTruncated = true
+			break
+		}
+		if i := strings.Index(k[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			p := k[:len(prefix)+i+1]
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			res.CommonPrefixes = append(res.CommonPrefixes, p)
+			res.NextMarker = p + "\xff"
+		} else {
+			res.Contents = append(res.Contents, Object{
+				Key:          k,
+				ETag:         s.objects[k].etag,
+				Size:         len(s.objects[k].data),
+				LastModified: fakeModTime.Format(time.RFC3339),
+			})
+			res.NextMarker = k
+		}
+		n++
+	}
+	if s.noNextMarker {
+		res.NextMarker = ""
+	}
+	xml.NewEncoder(w).Encode(res)
+	return nil
+}
+
+func writeFakeError(w http.ResponseWriter, e *ErrorResponse) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(e.Response.StatusCode)
+	xml.NewEncoder(w).Encode(e)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/xml"
 	"io"
+	"io/ioutil"
 	"net/http"
 )
 
@@ -32,10 +33,53 @@ type ResponseParser interface {
 // DefaultSender 是基于 http.Client 的默认 Sender 实现
 type DefaultSender struct {
 	*http.Client
+
+	// 请求失败后的重试策略，为 nil 时不进行重试。可以使用 DefaultRetryPolicy
+	RetryPolicy RetryPolicy
 }
 
-// Send 发送 http 请求
+// Send 发送 http 请求，设置了 RetryPolicy 时会按照 RetryPolicy 重试失败的请求
 func (s *DefaultSender) Send(ctx context.Context, caller Caller, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := s.send(ctx, req)
+		if s.RetryPolicy == nil {
+			return resp, err
+		}
+		// body 无法重新读取时不能重试
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+		delay, retry := s.RetryPolicy.ShouldRetry(ctx, caller, req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		var body io.ReadCloser
+		if req.GetBody != nil {
+			b, gerr := req.GetBody()
+			if gerr != nil {
+				// 无法重新读取 body 时返回最后一次请求的结果
+				return resp, err
+			}
+			body = b
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			if body != nil {
+				body.Close()
+			}
+			return nil, err
+		}
+		if body != nil {
+			req = cloneRequest(req)
+			req.Body = body
+		}
+	}
+}
+
+func (s *DefaultSender) send(ctx context.Context, req *http.Request) (*http.Response, error) {
 	resp, err := s.Do(req)
 	if err != nil {
 		// If we got an error, and the context has been canceled,
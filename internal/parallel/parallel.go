@@ -0,0 +1,72 @@
+// Package parallel 提供并发执行一组任务的辅助函数，供 go-cos 及其子包内部使用。
+package parallel
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Run 使用 concurrency 个 goroutine 并发执行任务 fn(ctx, i)，i 依次为 0, 1, ..., n-1。
+//
+// n 小于 0 表示任务的数量未知，会一直执行直到某个任务返回 io.EOF，此时不再开始新的任务，
+// io.EOF 不会被当作错误返回。
+//
+// 任意一个任务返回其他的错误时取消传给 fn 的 ctx 并且不再开始新的任务，等待正在执行的任务结束后返回第一个错误。
+// 所有任务都成功但 ctx 已被取消时返回 ctx.Err()。
+func Run(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		next     int
+		eof      bool
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	// take 返回下一个任务的编号，没有更多的任务时返回 false
+	take := func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if eof || firstErr != nil || ctx.Err() != nil || (n >= 0 && next >= n) {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i, ok := take()
+				if !ok {
+					return
+				}
+				err := fn(ctx, i)
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				if err == io.EOF && n < 0 {
+					eof = true
+				} else if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
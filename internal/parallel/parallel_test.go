@@ -0,0 +1,95 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		got     []int
+		running int32
+		max     int32
+	)
+	err := Run(context.Background(), 10, 3, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		mu.Lock()
+		if n > max {
+			max = n
+		}
+		got = append(got, i)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	sort.Ints(got)
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Run executed %v, want %v", got, want)
+	}
+	if max > 3 {
+		t.Errorf("Run executed %d tasks concurrently, want at most 3", max)
+	}
+}
+
+func TestRun_error(t *testing.T) {
+	errTask := errors.New("task error")
+	var count int32
+	err := Run(context.Background(), 100, 2, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&count, 1)
+		if i == 3 {
+			return errTask
+		}
+		if i > 3 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != errTask {
+		t.Errorf("Run returned error %v, want %v", err, errTask)
+	}
+	if count > 6 {
+		t.Errorf("Run executed %d tasks after error", count)
+	}
+}
+
+func TestRun_eof(t *testing.T) {
+	var mu sync.Mutex
+	remaining := 5
+	var count int32
+	err := Run(context.Background(), -1, 3, func(ctx context.Context, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if remaining == 0 {
+			return io.EOF
+		}
+		remaining--
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	if err != nil || count != 5 {
+		t.Errorf("Run returned %v after %d tasks, want nil after 5 tasks", err, count)
+	}
+}
+
+func TestRun_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	if err := Run(ctx, 3, 1, func(ctx context.Context, i int) error {
+		called = true
+		return nil
+	}); err != context.Canceled || called {
+		t.Errorf("Run returned %v, called: %v", err, called)
+	}
+}
@@ -40,6 +40,10 @@ type ObjectGetOptions struct {
 	IfMatch string `url:"-" header:"If-Match,omitempty"`
 	// 当 ETag 与指定的内容不一致，才返回文件。否则返回 304 (not modified)
 	IfNoneMatch string `url:"-" header:"If-None-Match,omitempty"`
+	// 指定要下载的对象的版本 ID
+	VersionID string `url:"versionId,omitempty" header:"-"`
+	// 对象使用 SSE-C 加密时需要指定上传时使用的密钥
+	*SSECustomerHeaderOptions `header:",omitempty" url:"-" xml:"-"`
 
 	// 预签名授权 URL
 	PresignedURL *url.URL `header:"-" url:"-" xml:"-"`
@@ -110,10 +114,18 @@ type ObjectPutHeaderOptions struct {
 	// 目前支持使用腾讯云 COS 主密钥对数据进行 AES-256 加密。
 	// 如果您需要对数据启用服务端加密，则需指定 XCosServerSideEncryption。
 	//
-	// 指定将对象启用服务端加密的方式。使用 COS 主密钥加密填写：AES256
+	// 指定将对象启用服务端加密的方式。使用 COS 主密钥加密填写：AES256，使用 KMS 托管的密钥加密填写：cos/kms
 	XCosServerSideEncryption string `header:"x-cos-server-side-encryption,omitempty" url:"-"`
+	// XCosServerSideEncryption 为 cos/kms 时使用的 KMS 主密钥 ID，为空时使用 COS 默认的 KMS 主密钥
+	XCosSSEKMSKeyID string `header:"x-cos-server-side-encryption-cos-kms-key-id,omitempty" url:"-"`
+	// XCosServerSideEncryption 为 cos/kms 时使用的加密上下文，base64 编码的 JSON，可以使用 EncodeSSEContext 生成
+	XCosSSEContext string `header:"x-cos-server-side-encryption-context,omitempty" url:"-"`
+	// 使用用户自定义密钥进行服务端加密（SSE-C），不能和 XCosServerSideEncryption 同时使用
+	*SSECustomerHeaderOptions `header:",omitempty" url:"-" xml:"-"`
 	// 可选值: Normal, Appendable
 	//XCosObjectType string `header:"x-cos-object-type,omitempty" url:"-"`
+	// 对象的标签，格式为 URL 查询参数，比如 key1=value1&key2=value2，可以使用 url.Values 的 Encode 方法生成
+	XCosTagging string `header:"x-cos-tagging,omitempty" url:"-"`
 }
 
 // ObjectPutOptions ...
@@ -192,14 +204,26 @@ type ObjectCopyHeaderOptions struct {
 	XCosMetaXXX *http.Header `header:"x-cos-meta-*,omitempty" url:"-"`
 	// 源文件 URL 路径，可以通过 versionid 子资源指定历史版本
 	XCosCopySource string `header:"x-cos-copy-source" url:"-" xml:"-"`
+	// 源文件使用 SSE-C 加密时需要指定源文件的密钥
+	*SSECopySourceHeaderOptions `header:",omitempty" url:"-" xml:"-"`
+	// 是否拷贝源文件的标签，枚举值：Copy, Replaced，默认值 Copy。假如标记为 Replaced，则使用 XCosTagging 作为目标文件的标签
+	XCosTaggingDirective string `header:"x-cos-tagging-directive,omitempty" url:"-" xml:"-"`
+	// 目标文件的标签，格式同 ObjectPutHeaderOptions.XCosTagging，仅在 XCosTaggingDirective 为 Replaced 时有效
+	XCosTagging string `header:"x-cos-tagging,omitempty" url:"-" xml:"-"`
 
 	// XCosServerSideEncryption 用于指定腾讯云 COS 在数据存储时，应用数据加密的保护策略。
 	// 腾讯云 COS 会帮助您在数据写入数据中心时自动加密，并在您取用该数据时自动解密。
 	// 目前支持使用腾讯云 COS 主密钥对数据进行 AES-256 加密。
 	// 如果您需要对数据启用服务端加密，则需指定 XCosServerSideEncryption。
 	//
-	// 指定将对象启用服务端加密的方式。使用 COS 主密钥加密填写：AES256
+	// 指定将对象启用服务端加密的方式。使用 COS 主密钥加密填写：AES256，使用 KMS 托管的密钥加密填写：cos/kms
 	XCosServerSideEncryption string `header:"x-cos-server-side-encryption,omitempty" url:"-"`
+	// XCosServerSideEncryption 为 cos/kms 时使用的 KMS 主密钥 ID，为空时使用 COS 默认的 KMS 主密钥
+	XCosSSEKMSKeyID string `header:"x-cos-server-side-encryption-cos-kms-key-id,omitempty" url:"-"`
+	// XCosServerSideEncryption 为 cos/kms 时使用的加密上下文，base64 编码的 JSON，可以使用 EncodeSSEContext 生成
+	XCosSSEContext string `header:"x-cos-server-side-encryption-context,omitempty" url:"-"`
+	// 使用用户自定义密钥进行服务端加密（SSE-C），不能和 XCosServerSideEncryption 同时使用
+	*SSECustomerHeaderOptions `header:",omitempty" url:"-" xml:"-"`
 }
 
 // ObjectCopyOptions ...
@@ -273,6 +297,8 @@ const MethodObjectDelete MethodName = "Object.Delete"
 // * 在 DELETE Object 请求中删除一个不存在的 Object，仍然认为是成功的，返回 204 No Content。
 // * DELETE Object 要求用户对该 Object 要有写权限。
 //
+// 启用了版本控制时会为对象创建一个删除标记，需要删除指定的版本时请使用 DeleteWithOpt 方法。
+//
 // https://cloud.tencent.com/document/product/436/7743
 func (s *ObjectService) Delete(ctx context.Context, name string) (*Response, error) {
 	sendOpt := sendOptions{
@@ -287,10 +313,45 @@ func (s *ObjectService) Delete(ctx context.Context, name string) (*Response, err
 	return resp, err
 }
 
+// MethodObjectDeleteWithOpt method name of Object.DeleteWithOpt
+const MethodObjectDeleteWithOpt MethodName = "Object.DeleteWithOpt"
+
+// ObjectDeleteOptions ...
+type ObjectDeleteOptions struct {
+	// 指定要删除的对象的版本 ID
+	VersionID string `url:"versionId,omitempty" header:"-"`
+}
+
+// DeleteWithOpt ...
+//
+// Delete 方法的补充，解决 Delete 不支持指定参数的问题。
+//
+// 启用了版本控制时，不指定 opt.VersionID 会为对象创建一个删除标记，指定 opt.VersionID 时会永久删除该版本。
+// 通过 Response.VersionID() 和 Response.Header 中的 x-cos-delete-marker 可以知道删除的版本以及是否为删除标记。
+//
+// https://cloud.tencent.com/document/product/436/7743
+func (s *ObjectService) DeleteWithOpt(ctx context.Context, name string, opt *ObjectDeleteOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/" + encodeURIComponent(name),
+		method:   http.MethodDelete,
+		optQuery: opt,
+		caller: Caller{
+			Method: MethodObjectDeleteWithOpt,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
 // ObjectHeadOptions ...
 type ObjectHeadOptions struct {
 	// 当 Object 在指定时间后被修改，则返回对应 Object 的 meta 信息，否则返回 304
 	IfModifiedSince string `url:"-" header:"If-Modified-Since,omitempty"`
+	// 指定要查询的对象的版本 ID
+	VersionID string `url:"versionId,omitempty" header:"-"`
+	// 对象使用 SSE-C 加密时需要指定上传时使用的密钥
+	*SSECustomerHeaderOptions `header:",omitempty" url:"-" xml:"-"`
 }
 
 // MethodObjectHead method name of Object.Head
@@ -306,6 +367,7 @@ func (s *ObjectService) Head(ctx context.Context, name string, opt *ObjectHeadOp
 		baseURL:   s.client.BaseURL.BucketURL,
 		uri:       "/" + encodeURIComponent(name),
 		method:    http.MethodHead,
+		optQuery:  opt,
 		optHeader: opt,
 		caller: Caller{
 			Method: MethodObjectHead,
@@ -513,4 +575,10 @@ type Object struct {
 	StorageClass string `xml:",omitempty"`
 	// Bucket 持有者信息
 	Owner *Owner `xml:",omitempty"`
+	// 对象的版本 ID，Object.DeleteMulti 时用于指定要删除的版本
+	VersionID string `xml:"VersionId,omitempty"`
+	// Object.DeleteMulti 的结果中表示删除的是删除标记或者创建了删除标记
+	DeleteMarker bool `xml:",omitempty"`
+	// Object.DeleteMulti 的结果中删除标记的版本 ID
+	DeleteMarkerVersionID string `xml:"DeleteMarkerVersionId,omitempty"`
 }
@@ -0,0 +1,261 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mozillazg/go-cos/internal/parallel"
+)
+
+const (
+	// 默认复制的分块大小: 64 MB
+	defaultCopyPartSize int64 = 64 * 1024 * 1024
+	// 复制的最大分块大小: 5 GB
+	maxCopyPartSize int64 = 5 * 1024 * 1024 * 1024
+)
+
+// ObjectMultiCopyOptions ...
+//
+// Object.MultiCopy 的参数
+type ObjectMultiCopyOptions struct {
+	*ObjectCopyHeaderOptions `header:",omitempty" url:"-" xml:"-"`
+	*ACLHeaderOptions        `header:",omitempty" url:"-" xml:"-"`
+
+	// 分块大小，单位是 Byte。默认值：64 MB，最小值：1 MB，最大值：5 GB。
+	// 按照 PartSize 切分后的分块数量超过 10000 时，会自动调大分块大小
+	PartSize int64 `header:"-" url:"-" xml:"-"`
+	// 同时复制分块的数量，默认值：3
+	Concurrency int `header:"-" url:"-" xml:"-"`
+	// 源文件大小小于等于 Threshold 时使用简单复制（Object.Copy），否则使用分块复制。
+	// 默认值：PartSize，最大值：5 GB
+	Threshold int64 `header:"-" url:"-" xml:"-"`
+}
+
+// MultiCopy 复制文件的便捷方法，会根据源文件的大小自动选择简单复制（Object.Copy）或并发分块复制。
+//
+// 先通过 HEAD 请求获取源文件的大小和元数据，源文件大小小于等于 opt.Threshold 时通过 Object.Copy 复制，
+// 否则依次调用 InitiateMultipartUpload、UploadPartCopy 和 CompleteMultipartUpload 完成分块复制，
+// 最多同时复制 opt.Concurrency 个分块。分块复制出错时会调用 AbortMultipartUpload 舍弃已复制的分块。
+// 所有的 UploadPartCopy 请求都会带上 x-cos-copy-source-If-Match 头部以保证复制的是同一个版本的源文件。
+//
+// 分块复制时，XCosMetadataDirective 为 Copy（默认值）时会将源文件的 Content-Type 等头部和 x-cos-meta-* 元数据
+// 设置到目标文件上，为 Replaced 时使用 opt 中的元数据。
+// XCosTaggingDirective 为 Replaced 时使用 XCosTagging 作为目标文件的标签，否则分块复制时不会复制源文件的标签。
+//
+// sourceURL 的格式同 Object.Copy。返回的 Response 是 Object.Copy 或 CompleteMultipartUpload 的响应。
+func (s *ObjectService) MultiCopy(ctx context.Context, name, sourceURL string, opt *ObjectMultiCopyOptions) (*ObjectCopyResult, *Response, error) {
+	c := newCopier(s, name, sourceURL, opt)
+	return c.copy(ctx)
+}
+
+type copier struct {
+	s           *ObjectService
+	name        string
+	sourceURL   string
+	opt         *ObjectMultiCopyOptions
+	header      *ObjectCopyHeaderOptions
+	partSize    int64
+	concurrency int
+	threshold   int64
+}
+
+func newCopier(s *ObjectService, name, sourceURL string, opt *ObjectMultiCopyOptions) *copier {
+	if opt == nil {
+		opt = &ObjectMultiCopyOptions{}
+	}
+	c := &copier{
+		s:           s,
+		name:        name,
+		sourceURL:   sourceURL,
+		opt:         opt,
+		header:      &ObjectCopyHeaderOptions{},
+		partSize:    opt.PartSize,
+		concurrency: opt.Concurrency,
+		threshold:   opt.Threshold,
+	}
+	if opt.ObjectCopyHeaderOptions != nil {
+		*c.header = *opt.ObjectCopyHeaderOptions
+	}
+	c.header.XCosCopySource = sourceURL
+	if c.partSize <= 0 {
+		c.partSize = defaultCopyPartSize
+	}
+	if c.partSize < minUploadPartSize {
+		c.partSize = minUploadPartSize
+	}
+	if c.partSize > maxCopyPartSize {
+		c.partSize = maxCopyPartSize
+	}
+	if c.concurrency <= 0 {
+		c.concurrency = defaultUploadConcurrency
+	}
+	if c.threshold <= 0 {
+		c.threshold = c.partSize
+	}
+	if c.threshold > maxCopyPartSize {
+		c.threshold = maxCopyPartSize
+	}
+	return c
+}
+
+func (c *copier) copy(ctx context.Context) (*ObjectCopyResult, *Response, error) {
+	resp, err := c.headSource(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, resp, fmt.Errorf("cos: invalid Content-Length of copy source: %q", resp.Header.Get("Content-Length"))
+	}
+	if size <= c.threshold {
+		return c.s.Copy(ctx, c.name, c.sourceURL, &ObjectCopyOptions{
+			ObjectCopyHeaderOptions: c.header,
+			ACLHeaderOptions:        c.opt.ACLHeaderOptions,
+		})
+	}
+
+	// 保证分块数量不超过 10000
+	if (size+c.partSize-1)/c.partSize > maxUploadParts {
+		c.partSize = (size + maxUploadParts - 1) / maxUploadParts
+	}
+	return c.copyMultipart(ctx, size, resp.Header)
+}
+
+// headSource 通过 HEAD 请求获取源文件的元数据
+func (c *copier) headSource(ctx context.Context) (*Response, error) {
+	raw := c.sourceURL
+	if !strings.Contains(raw, "://") {
+		raw = c.s.client.BaseURL.BucketURL.Scheme + "://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	sendOpt := sendOptions{
+		baseURL:   u,
+		uri:       "",
+		method:    http.MethodHead,
+		optHeader: c.header.SSECopySourceHeaderOptions.sourceHeader(),
+		caller: Caller{
+			Method: MethodObjectHead,
+		},
+	}
+	resp, err := c.s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// initiateHeader 返回初始化分块上传时使用的头部
+func (c *copier) initiateHeader(source http.Header) *ObjectPutHeaderOptions {
+	header := &ObjectPutHeaderOptions{
+		XCosStorageClass:         c.header.XCosStorageClass,
+		XCosServerSideEncryption: c.header.XCosServerSideEncryption,
+		XCosSSEKMSKeyID:          c.header.XCosSSEKMSKeyID,
+		XCosSSEContext:           c.header.XCosSSEContext,
+		SSECustomerHeaderOptions: c.header.SSECustomerHeaderOptions,
+	}
+	if strings.EqualFold(c.header.XCosTaggingDirective, "Replaced") {
+		header.XCosTagging = c.header.XCosTagging
+	}
+	if strings.EqualFold(c.header.XCosMetadataDirective, "Replaced") {
+		header.XCosMetaXXX = c.header.XCosMetaXXX
+		return header
+	}
+
+	header.CacheControl = source.Get("Cache-Control")
+	header.ContentDisposition = source.Get("Content-Disposition")
+	header.ContentEncoding = source.Get("Content-Encoding")
+	header.ContentType = source.Get("Content-Type")
+	header.Expires = source.Get("Expires")
+	meta := http.Header{}
+	for k, v := range source {
+		if strings.HasPrefix(strings.ToLower(k), "x-cos-meta-") {
+			meta[k] = v
+		}
+	}
+	if len(meta) > 0 {
+		header.XCosMetaXXX = &meta
+	}
+	return header
+}
+
+func (c *copier) copyMultipart(ctx context.Context, size int64, source http.Header) (*ObjectCopyResult, *Response, error) {
+	res, _, err := c.s.InitiateMultipartUpload(ctx, c.name, &InitiateMultipartUploadOptions{
+		ACLHeaderOptions:       c.opt.ACLHeaderOptions,
+		ObjectPutHeaderOptions: c.initiateHeader(source),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	uploadID := res.UploadID
+
+	parts, err := c.copyParts(ctx, uploadID, size, source.Get("ETag"))
+	if err != nil {
+		c.abort(uploadID)
+		return nil, nil, err
+	}
+	sort.Sort(objectsByPartNumber(parts))
+	result, resp, err := c.s.CompleteMultipartUpload(ctx, c.name, uploadID, &CompleteMultipartUploadOptions{Parts: parts})
+	if err != nil {
+		c.abort(uploadID)
+		return nil, resp, err
+	}
+	return &ObjectCopyResult{ETag: result.ETag}, resp, nil
+}
+
+// abort 舍弃分块上传。使用新的 context，避免因为 ctx 已被取消而无法舍弃
+func (c *copier) abort(uploadID string) {
+	c.s.AbortMultipartUpload(context.Background(), c.name, uploadID)
+}
+
+// copyParts 使用 c.concurrency 个 goroutine 并发复制各个分块
+func (c *copier) copyParts(ctx context.Context, uploadID string, size int64, etag string) ([]Object, error) {
+	opt := &ObjectCopyPartOptions{
+		XCosCopySourceIfModifiedSince:   c.header.XCosCopySourceIfModifiedSince,
+		XCosCopySourceIfUnmodifiedSince: c.header.XCosCopySourceIfUnmodifiedSince,
+		XCosCopySourceIfMatch:           c.header.XCosCopySourceIfMatch,
+		XCosCopySourceIfNoneMatch:       c.header.XCosCopySourceIfNoneMatch,
+		SSECopySourceHeaderOptions:      c.header.SSECopySourceHeaderOptions,
+		SSECustomerHeaderOptions:        c.header.SSECustomerHeaderOptions,
+	}
+	if opt.XCosCopySourceIfMatch == "" {
+		opt.XCosCopySourceIfMatch = etag
+	}
+
+	var (
+		mu    sync.Mutex
+		parts []Object
+	)
+	total := int((size + c.partSize - 1) / c.partSize)
+	err := parallel.Run(ctx, total, c.concurrency, func(ctx context.Context, i int) error {
+		n := i + 1
+		start := int64(i) * c.partSize
+		end := start + c.partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		o := *opt
+		o.XCosCopySourceRange = fmt.Sprintf("bytes=%d-%d", start, end)
+		res, _, err := c.s.UploadPartCopy(ctx, c.name, uploadID, n, c.sourceURL, &o)
+		if err != nil {
+			return err
+		}
+		if res.ETag == "" {
+			return errors.New("cos: missing ETag in the response of UploadPartCopy")
+		}
+		mu.Lock()
+		parts = append(parts, Object{PartNumber: n, ETag: res.ETag})
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
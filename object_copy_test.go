@@ -0,0 +1,140 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// newCopySource 创建大小为 size 的源 Object src.txt
+func newCopySource(t *testing.T, size int) (*fakeServer, *fakeObject) {
+	fs := newFakeServer(t)
+	o := fs.putObject("src.txt", testUploadData(size), http.Header{
+		"Content-Type":    {"text/plain"},
+		"X-Cos-Meta-Test": {"test"},
+	})
+	return fs, o
+}
+
+// copyRanges 返回每个 UploadPartCopy 请求复制的范围
+func copyRanges(t *testing.T, fs *fakeServer, etag string) map[int]string {
+	ranges := map[int]string{}
+	for _, r := range fs.requests("UploadPartCopy") {
+		if v := r.header.Get("x-cos-copy-source-If-Match"); v != etag {
+			t.Errorf("UploadPartCopy x-cos-copy-source-If-Match: %q, want %q", v, etag)
+		}
+		n, _ := strconv.Atoi(r.query.Get("partNumber"))
+		ranges[n] = r.header.Get("x-cos-copy-source-range")
+	}
+	return ranges
+}
+
+func TestObjectService_MultiCopy_copy(t *testing.T) {
+	setup()
+	defer teardown()
+	fs, o := newCopySource(t, 100)
+
+	sourceURL := client.BaseURL.BucketURL.Host + "/src.txt"
+	res, _, err := client.Object.MultiCopy(context.Background(), "dst.txt", sourceURL, nil)
+	if err != nil {
+		t.Fatalf("Object.MultiCopy returned error: %v", err)
+	}
+	if res.ETag != o.etag || fs.count("CopyObject") != 1 || fs.count("UploadPartCopy") != 0 {
+		t.Errorf("Object.MultiCopy returned %+v, copied %d, parts %d", res, fs.count("CopyObject"), fs.count("UploadPartCopy"))
+	}
+}
+
+func TestObjectService_MultiCopy_multipart(t *testing.T) {
+	setup()
+	defer teardown()
+	size := 2*int(minUploadPartSize) + 10
+	fs, o := newCopySource(t, size)
+
+	sourceURL := client.BaseURL.BucketURL.Host + "/src.txt"
+	opt := &ObjectMultiCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			XCosStorageClass: StorageClassStandardTA,
+		},
+		PartSize: minUploadPartSize,
+	}
+	res, _, err := client.Object.MultiCopy(context.Background(), "dst.txt", sourceURL, opt)
+	if err != nil {
+		t.Fatalf("Object.MultiCopy returned error: %v", err)
+	}
+	if res.ETag != fakeMultipartETag(o.data, 3) || fs.count("CopyObject") != 0 {
+		t.Errorf("Object.MultiCopy returned %+v, copied %d", res, fs.count("CopyObject"))
+	}
+	if !bytes.Equal(fs.object("dst.txt"), o.data) {
+		t.Errorf("Object.MultiCopy copied %d bytes, want %d", len(fs.object("dst.txt")), len(o.data))
+	}
+
+	want := map[int]string{
+		1: fmt.Sprintf("bytes=0-%d", minUploadPartSize-1),
+		2: fmt.Sprintf("bytes=%d-%d", minUploadPartSize, 2*minUploadPartSize-1),
+		3: fmt.Sprintf("bytes=%d-%d", 2*minUploadPartSize, size-1),
+	}
+	if ranges := copyRanges(t, fs, o.etag); !reflect.DeepEqual(ranges, want) {
+		t.Errorf("Object.MultiCopy copied ranges %v, want %v", ranges, want)
+	}
+	header := fs.requests("InitiateMultipartUpload")[0].header
+	// 默认复制源文件的元数据
+	for k, v := range map[string]string{
+		"Content-Type":        "text/plain",
+		"X-Cos-Meta-Test":     "test",
+		"X-Cos-Storage-Class": StorageClassStandardTA,
+	} {
+		if got := header.Get(k); got != v {
+			t.Errorf("InitiateMultipartUpload header %s: %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestObjectService_MultiCopy_replaced(t *testing.T) {
+	setup()
+	defer teardown()
+	fs, _ := newCopySource(t, 2*int(minUploadPartSize))
+
+	sourceURL := client.BaseURL.BucketURL.Host + "/src.txt"
+	opt := &ObjectMultiCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			XCosMetadataDirective: "Replaced",
+			XCosMetaXXX:           &http.Header{"X-Cos-Meta-Other": []string{"other"}},
+			XCosTaggingDirective:  "Replaced",
+			XCosTagging:           "k=v",
+		},
+		PartSize: minUploadPartSize,
+	}
+	if _, _, err := client.Object.MultiCopy(context.Background(), "dst.txt", sourceURL, opt); err != nil {
+		t.Fatalf("Object.MultiCopy returned error: %v", err)
+	}
+	header := fs.requests("InitiateMultipartUpload")[0].header
+	for k, v := range map[string]string{
+		"X-Cos-Meta-Test":  "",
+		"X-Cos-Meta-Other": "other",
+		"X-Cos-Tagging":    "k=v",
+	} {
+		if got := header.Get(k); got != v {
+			t.Errorf("InitiateMultipartUpload header %s: %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestObjectService_MultiCopy_abort(t *testing.T) {
+	setup()
+	defer teardown()
+	fs, _ := newCopySource(t, 3*int(minUploadPartSize))
+	fs.failPart(2)
+
+	sourceURL := client.BaseURL.BucketURL.Host + "/src.txt"
+	opt := &ObjectMultiCopyOptions{PartSize: minUploadPartSize}
+	if _, _, err := client.Object.MultiCopy(context.Background(), "dst.txt", sourceURL, opt); err == nil {
+		t.Fatal("Object.MultiCopy should return error")
+	}
+	if n := fs.count("AbortMultipartUpload"); n != 1 {
+		t.Errorf("Object.MultiCopy aborted %d times, want 1", n)
+	}
+}
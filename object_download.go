@@ -0,0 +1,296 @@
+package cos
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mozillazg/go-cos/internal/parallel"
+)
+
+const (
+	// 默认每个分块下载失败后的重试次数
+	defaultDownloadPartRetries = 3
+	// 断点续传文件的格式版本，格式发生不兼容的变更时需要增加该值
+	downloadCheckpointVersion = 1
+)
+
+// ObjectDownloadOptions ...
+//
+// Object.Download 的参数
+type ObjectDownloadOptions struct {
+	// 分块大小，单位是 Byte。默认值：8 MB
+	PartSize int64
+	// 同时下载分块的数量，默认值：3
+	Concurrency int
+	// 每个分块下载失败后的重试次数，默认值：3
+	PartRetries int
+	// 启用断点续传并将下载进度保存在 CheckpointFile 文件中。
+	// 下载中断后使用相同的参数和同一个 w 再次调用 Download 时只会下载缺失的分块，下载成功后会删除该文件
+	CheckpointFile string
+	// 下载完成后不校验数据的完整性
+	DisableChecksum bool
+	// Object 使用 SSE-C 加密时需要指定上传时使用的密钥
+	SSECustomerHeaderOptions *SSECustomerHeaderOptions
+}
+
+// Download 并发下载文件的便捷方法。
+//
+// 先通过 Object.Head 获取 Object 的大小和 ETag，然后按照 opt.PartSize 将 Object 切分为多个范围，
+// 最多同时使用 opt.Concurrency 个 Range 请求下载各个范围的内容并写入 w 中对应的位置。
+// 每个范围下载失败后会单独重试，所有的 Range 请求都会带上 If-Match 头部以保证下载的是同一个版本的 Object。
+//
+// 当 w 同时实现了 io.ReaderAt 时（比如 *os.File），下载完成后会根据 x-cos-hash-crc64ecma 或 ETag（MD5）
+// 校验数据的完整性，可以通过 opt.DisableChecksum 关闭校验。
+//
+// 返回的 Response 是 Object.Head 的响应。
+func (s *ObjectService) Download(ctx context.Context, name string, w io.WriterAt, opt *ObjectDownloadOptions) (*Response, error) {
+	d := newDownloader(s, name, opt)
+	return d.download(ctx, w)
+}
+
+type downloader struct {
+	s           *ObjectService
+	name        string
+	opt         *ObjectDownloadOptions
+	partSize    int64
+	concurrency int
+	retries     int
+
+	size int64
+	etag string
+}
+
+func newDownloader(s *ObjectService, name string, opt *ObjectDownloadOptions) *downloader {
+	if opt == nil {
+		opt = &ObjectDownloadOptions{}
+	}
+	d := &downloader{
+		s:           s,
+		name:        name,
+		opt:         opt,
+		partSize:    opt.PartSize,
+		concurrency: opt.Concurrency,
+		retries:     opt.PartRetries,
+	}
+	if d.partSize <= 0 {
+		d.partSize = defaultUploadPartSize
+	}
+	if d.concurrency <= 0 {
+		d.concurrency = defaultUploadConcurrency
+	}
+	if d.retries <= 0 {
+		d.retries = defaultDownloadPartRetries
+	}
+	return d
+}
+
+// downloadCheckpoint 断点续传时保存的下载进度
+type downloadCheckpoint struct {
+	Version int `json:"version"`
+	// Object 的名称、ETag 和大小，用于判断 Object 是否发生了变化
+	Key  string `json:"key"`
+	ETag string `json:"etag"`
+	Size int64  `json:"size"`
+	// 分块大小
+	PartSize int64 `json:"part_size"`
+	// 已下载完成的分块编号（从 1 开始）
+	Parts []int `json:"parts"`
+}
+
+func (d *downloader) download(ctx context.Context, w io.WriterAt) (*Response, error) {
+	resp, err := d.s.Head(ctx, d.name, &ObjectHeadOptions{
+		SSECustomerHeaderOptions: d.opt.SSECustomerHeaderOptions,
+	})
+	if err != nil {
+		return resp, err
+	}
+	d.size, err = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return resp, fmt.Errorf("cos: invalid Content-Length of object %s: %v", d.name, err)
+	}
+	d.etag = resp.Header.Get("ETag")
+
+	cp := d.loadCheckpoint()
+	done := map[int]bool{}
+	for _, n := range cp.Parts {
+		done[n] = true
+	}
+	if err := d.downloadParts(ctx, w, cp, done); err != nil {
+		return resp, err
+	}
+	if !d.opt.DisableChecksum {
+		if err := d.verify(w, resp); err != nil {
+			return resp, err
+		}
+	}
+	if d.opt.CheckpointFile != "" {
+		os.Remove(d.opt.CheckpointFile)
+	}
+	return resp, nil
+}
+
+// loadCheckpoint 读取断点续传文件，Object 或参数发生了变化时重新开始下载
+func (d *downloader) loadCheckpoint() *downloadCheckpoint {
+	cp := &downloadCheckpoint{
+		Version:  downloadCheckpointVersion,
+		Key:      d.name,
+		ETag:     d.etag,
+		Size:     d.size,
+		PartSize: d.partSize,
+	}
+	if d.opt.CheckpointFile == "" {
+		return cp
+	}
+	b, err := ioutil.ReadFile(d.opt.CheckpointFile)
+	if err != nil {
+		return cp
+	}
+	old := new(downloadCheckpoint)
+	if err := json.Unmarshal(b, old); err != nil {
+		return cp
+	}
+	if old.Version != cp.Version || old.Key != cp.Key || old.ETag != cp.ETag ||
+		old.Size != cp.Size || old.PartSize != cp.PartSize {
+		return cp
+	}
+	return old
+}
+
+func (d *downloader) saveCheckpoint(cp *downloadCheckpoint) error {
+	if d.opt.CheckpointFile == "" {
+		return nil
+	}
+	return writeCheckpointFile(d.opt.CheckpointFile, cp)
+}
+
+// downloadParts 使用 d.concurrency 个 goroutine 并发下载 done 之外的分块，并在每个分块下载完成后更新断点续传文件
+func (d *downloader) downloadParts(ctx context.Context, w io.WriterAt, cp *downloadCheckpoint, done map[int]bool) error {
+	var numbers []int
+	total := int((d.size + d.partSize - 1) / d.partSize)
+	for n := 1; n <= total; n++ {
+		if !done[n] {
+			numbers = append(numbers, n)
+		}
+	}
+
+	var mu sync.Mutex
+	return parallel.Run(ctx, len(numbers), d.concurrency, func(ctx context.Context, i int) error {
+		n := numbers[i]
+		if err := d.downloadPartWithRetry(ctx, w, n); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		cp.Parts = append(cp.Parts, n)
+		return d.saveCheckpoint(cp)
+	})
+}
+
+func (d *downloader) downloadPartWithRetry(ctx context.Context, w io.WriterAt, n int) error {
+	var err error
+	for i := 0; i <= d.retries; i++ {
+		if i > 0 {
+			if err := sleepContext(ctx, backoffDelay(defaultRetryBaseDelay, defaultRetryMaxDelay, i)); err != nil {
+				return err
+			}
+		}
+		err = d.downloadPart(ctx, w, n)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		// Object 已经发生了变化（If-Match 不满足）或者没有权限之类的错误，重试也不会成功
+		if code := errorStatusCode(err); code != 0 && code < http.StatusInternalServerError {
+			return err
+		}
+	}
+	return err
+}
+
+func (d *downloader) downloadPart(ctx context.Context, w io.WriterAt, n int) error {
+	start := int64(n-1) * d.partSize
+	end := start + d.partSize - 1
+	if end >= d.size {
+		end = d.size - 1
+	}
+	opt := &ObjectGetOptions{
+		Range:                    fmt.Sprintf("bytes=%d-%d", start, end),
+		IfMatch:                  d.etag,
+		SSECustomerHeaderOptions: d.opt.SSECustomerHeaderOptions,
+	}
+	resp, err := d.s.Get(ctx, d.name, opt)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// 服务端或代理忽略了 Range 时会返回 200 和完整的内容，直接写入会覆盖其他分块的数据
+	if resp.StatusCode != http.StatusPartialContent && (start > 0 || end < d.size-1) {
+		return fmt.Errorf("cos: range %s of object %s is not satisfied: %s", opt.Range, d.name, resp.Status)
+	}
+
+	written, err := io.Copy(&offsetWriter{w: w, offset: start}, resp.Body)
+	if err != nil {
+		return err
+	}
+	if want := end - start + 1; written != want {
+		return fmt.Errorf("cos: downloaded %d bytes of range %s, want %d", written, opt.Range, want)
+	}
+	return nil
+}
+
+// verify 校验下载的数据的完整性，w 没有实现 io.ReaderAt 或者 Object 没有可用的校验值时跳过校验
+func (d *downloader) verify(w io.WriterAt, resp *Response) error {
+	r, ok := w.(io.ReaderAt)
+	if !ok {
+		return nil
+	}
+
+	var h hash.Hash
+	var want string
+	if v := resp.HashCRC64ECMA(); v != "" {
+		h = crc64.New(crc64.MakeTable(crc64.ECMA))
+		want = v
+	} else if v := strings.Trim(d.etag, `"`); len(v) == md5.Size*2 && !resp.encryptedETag() {
+		// 非分块上传且未加密的 Object 的 ETag 为其内容的 MD5 值
+		h = md5.New()
+		want = strings.ToLower(v)
+	} else {
+		return nil
+	}
+
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, d.size)); err != nil {
+		return err
+	}
+	var got string
+	if h64, ok := h.(hash.Hash64); ok {
+		got = strconv.FormatUint(h64.Sum64(), 10)
+	} else {
+		got = fmt.Sprintf("%x", h.Sum(nil))
+	}
+	if got != want {
+		return fmt.Errorf("cos: checksum of downloaded object %s is %s, want %s", d.name, got, want)
+	}
+	return nil
+}
+
+// offsetWriter 从 offset 开始按顺序写入 w
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
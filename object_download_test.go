@@ -0,0 +1,162 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/crc64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTempFile(t *testing.T) (*os.File, func()) {
+	f, err := ioutil.TempFile("", "cos-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, func() {
+		f.Close()
+		os.Remove(f.Name())
+		os.Remove(f.Name() + ".cp")
+	}
+}
+
+func testFileContent(t *testing.T, f *os.File, want []byte) {
+	got, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("downloaded file size is %d, want %d", len(got), len(want))
+	}
+}
+
+func TestObjectService_Download(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	fs := newFakeServer(t)
+	o := fs.putObject("hello.bin", data, nil)
+	fs.failRange("bytes=300-599", 2)
+	f, cleanup := testTempFile(t)
+	defer cleanup()
+
+	opt := &ObjectDownloadOptions{
+		PartSize:    300,
+		Concurrency: 2,
+	}
+	resp, err := client.Object.Download(context.Background(), "hello.bin", f, opt)
+	if err != nil {
+		t.Fatalf("Object.Download returned error: %v", err)
+	}
+	if resp.Header.Get("ETag") != o.etag {
+		t.Errorf("Object.Download returned response %+v", resp.Header)
+	}
+	testFileContent(t, f, data)
+	if ranges := fs.ranges(); len(ranges) != 6 {
+		t.Errorf("Object.Download requested %v, want 4 ranges and 2 retries", ranges)
+	}
+	for _, r := range fs.requests("GetObject") {
+		if v := r.header.Get("If-Match"); v != o.etag {
+			t.Errorf("Object.Download sent If-Match %s, want %s", v, o.etag)
+		}
+	}
+}
+
+func TestObjectService_Download_resume(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	fs := newFakeServer(t)
+	o := fs.putObject("hello.bin", data, nil)
+	f, cleanup := testTempFile(t)
+	defer cleanup()
+
+	// 第 1 个分块已经下载完成
+	f.WriteAt(data[:400], 0)
+	cp := fmt.Sprintf(`{"version":1,"key":"hello.bin","etag":%q,"size":1000,"part_size":400,"parts":[1]}`, o.etag)
+	if err := ioutil.WriteFile(f.Name()+".cp", []byte(cp), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := &ObjectDownloadOptions{
+		PartSize:       400,
+		Concurrency:    1,
+		CheckpointFile: f.Name() + ".cp",
+	}
+	_, err := client.Object.Download(context.Background(), "hello.bin", f, opt)
+	if err != nil {
+		t.Fatalf("Object.Download returned error: %v", err)
+	}
+	testFileContent(t, f, data)
+	want := []string{"bytes=400-799", "bytes=800-999"}
+	if ranges := fs.ranges(); fmt.Sprint(ranges) != fmt.Sprint(want) {
+		t.Errorf("Object.Download requested %v, want %v", ranges, want)
+	}
+	if _, err := os.Stat(opt.CheckpointFile); !os.IsNotExist(err) {
+		t.Errorf("checkpoint file should be removed, got %v", err)
+	}
+}
+
+func TestObjectService_Download_range_ignored(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	fs := newFakeServer(t)
+	fs.putObject("hello.bin", data, nil)
+	fs.ignoreRange = true
+	f, cleanup := testTempFile(t)
+	defer cleanup()
+
+	_, err := client.Object.Download(context.Background(), "hello.bin", f, &ObjectDownloadOptions{
+		PartSize:    300,
+		Concurrency: 1,
+		PartRetries: 1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "not satisfied") {
+		t.Fatalf("Object.Download returned error %v, want range not satisfied", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != 0 {
+		t.Errorf("Object.Download should not write the full content, file size is %d", fi.Size())
+	}
+}
+
+func TestObjectService_Download_checksum(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	crc := crc64.Checksum(data, crc64.MakeTable(crc64.ECMA))
+	mux.HandleFunc("/hello.bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag-2"`)
+		w.Header().Set(xCosHashCRC64ECMA, strconv.FormatUint(crc+1, 10))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+	f, cleanup := testTempFile(t)
+	defer cleanup()
+
+	_, err := client.Object.Download(context.Background(), "hello.bin", f, nil)
+	if err == nil {
+		t.Fatal("Object.Download should return checksum error")
+	}
+	testFileContent(t, f, data)
+
+	_, err = client.Object.Download(context.Background(), "hello.bin", f, &ObjectDownloadOptions{
+		DisableChecksum: true,
+	})
+	if err != nil {
+		t.Fatalf("Object.Download returned error: %v", err)
+	}
+}
@@ -53,11 +53,13 @@ func (s *ObjectService) InitiateMultipartUpload(ctx context.Context, name string
 type ObjectUploadPartOptions struct {
 	// RFC 2616 中定义的 HTTP 请求内容长度（字节）
 	Expect          string `header:"Expect,omitempty" url:"-"`
-	XCosContentSHA1 string `header:"x-cos-content-sha1" url:"-"`
+	XCosContentSHA1 string `header:"x-cos-content-sha1,omitempty" url:"-"`
 	// RFC 1864 中定义的经过Base64编码的128-bit 内容 MD5 校验值。此头部用来校验文件内容是否发生变化
-	ContentMD5 string `header:"Content-MD5" url:"-"`
+	ContentMD5 string `header:"Content-MD5,omitempty" url:"-"`
 	// RFC 2616 中定义的 HTTP 请求内容长度（字节）
 	ContentLength int `header:"Content-Length,omitempty" url:"-"`
+	// 初始化分块上传时使用了 SSE-C 加密时，需要指定相同的密钥
+	*SSECustomerHeaderOptions `header:",omitempty" url:"-" xml:"-"`
 }
 
 // MethodObjectUploadPart method name of Object.UploadPart
@@ -93,6 +95,72 @@ func (s *ObjectService) UploadPart(ctx context.Context, name, uploadID string, p
 	return resp, err
 }
 
+// ObjectCopyPartOptions ...
+//
+// https://cloud.tencent.com/document/product/436/8287
+type ObjectCopyPartOptions struct {
+	// 源文件 URL 路径，可以通过 versionid 子资源指定历史版本
+	XCosCopySource string `header:"x-cos-copy-source" url:"-" xml:"-"`
+	// 源文件的字节范围，格式为 bytes=first-last，比如 bytes=0-9 表示源文件的前 10 个字节，为空时复制整个源文件
+	XCosCopySourceRange string `header:"x-cos-copy-source-range,omitempty" url:"-" xml:"-"`
+	// 当 Object 在指定时间后被修改，则执行操作，否则返回 412
+	XCosCopySourceIfModifiedSince string `header:"x-cos-copy-source-If-Modified-Since,omitempty" url:"-" xml:"-"`
+	// 当 Object 在指定时间后未被修改，则执行操作，否则返回 412
+	XCosCopySourceIfUnmodifiedSince string `header:"x-cos-copy-source-If-Unmodified-Since,omitempty" url:"-" xml:"-"`
+	// 当 Object 的 Etag 和给定一致时，则执行操作，否则返回 412
+	XCosCopySourceIfMatch string `header:"x-cos-copy-source-If-Match,omitempty" url:"-" xml:"-"`
+	// 当 Object 的 Etag 和给定不一致时，则执行操作，否则返回 412
+	XCosCopySourceIfNoneMatch string `header:"x-cos-copy-source-If-None-Match,omitempty" url:"-" xml:"-"`
+	// 源文件使用 SSE-C 加密时需要指定源文件的密钥
+	*SSECopySourceHeaderOptions `header:",omitempty" url:"-" xml:"-"`
+	// 初始化分块上传时使用了 SSE-C 加密时，需要指定相同的密钥
+	*SSECustomerHeaderOptions `header:",omitempty" url:"-" xml:"-"`
+}
+
+// CopyPartResult ...
+type CopyPartResult struct {
+	XMLName xml.Name `xml:"CopyPartResult"`
+	// 分块的 ETag，CompleteMultipartUpload 时需要使用该值
+	ETag string `xml:"ETag,omitempty"`
+	// 分块最后修改时间
+	LastModified string `xml:"LastModified,omitempty"`
+}
+
+// MethodObjectUploadPartCopy method name of Object.UploadPartCopy
+const MethodObjectUploadPartCopy MethodName = "Object.UploadPartCopy"
+
+// UploadPartCopy ...
+//
+// Upload Part - Copy 请求实现将一个文件的分块内容从源路径复制到目标路径，
+// 通过 opt.XCosCopySourceRange 指定复制的字节范围，用于复制超过 5 GB 的文件。
+// 每个分块大小为 1 MB 到 5 GB ，最后一个分块可以小于 1 MB。
+//
+// sourceURL 的格式同 Object.Copy，opt 中的 XCosCopySource 会被忽略。
+//
+// https://cloud.tencent.com/document/product/436/8287
+func (s *ObjectService) UploadPartCopy(ctx context.Context, name, uploadID string, partNumber int, sourceURL string, opt *ObjectCopyPartOptions) (*CopyPartResult, *Response, error) {
+	header := &ObjectCopyPartOptions{}
+	if opt != nil {
+		*header = *opt
+	}
+	header.XCosCopySource = sourceURL
+
+	var res CopyPartResult
+	u := fmt.Sprintf("/%s?partNumber=%d&uploadId=%s", encodeURIComponent(name), partNumber, uploadID)
+	sendOpt := sendOptions{
+		baseURL:   s.client.BaseURL.BucketURL,
+		uri:       u,
+		method:    http.MethodPut,
+		optHeader: header,
+		result:    &res,
+		caller: Caller{
+			Method: MethodObjectUploadPartCopy,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
+
 // ObjectListPartsOptions ...
 type ObjectListPartsOptions struct {
 	// 规定返回值的编码方式
@@ -118,6 +118,53 @@ func TestObjectService_UploadPart(t *testing.T) {
 
 }
 
+func TestObjectService_UploadPartCopy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &ObjectCopyPartOptions{
+		XCosCopySource:        "ignored",
+		XCosCopySourceRange:   "bytes=0-9",
+		XCosCopySourceIfMatch: `"etag"`,
+	}
+	sourceURL := "test-1253846586.cos.ap-guangzhou.myqcloud.com/test.txt"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"uploadId":   "xxxxx",
+			"partNumber": "2",
+		}
+		testFormValues(t, r, vs)
+		testHeader(t, r, "x-cos-copy-source", sourceURL)
+		testHeader(t, r, "x-cos-copy-source-range", "bytes=0-9")
+		testHeader(t, r, "x-cos-copy-source-If-Match", `"etag"`)
+
+		fmt.Fprint(w, `<CopyPartResult>
+	<ETag>"ba82b57cfdfda8bd17ad4e5879ebb4fe"</ETag>
+	<LastModified>2017-09-04T09:42:01Z</LastModified>
+</CopyPartResult>`)
+	})
+
+	ref, _, err := client.Object.UploadPartCopy(context.Background(),
+		"test/hello.txt", "xxxxx", 2, sourceURL, opt)
+	if err != nil {
+		t.Fatalf("Object.UploadPartCopy returned error: %v", err)
+	}
+
+	want := &CopyPartResult{
+		XMLName:      xml.Name{Local: "CopyPartResult"},
+		ETag:         `"ba82b57cfdfda8bd17ad4e5879ebb4fe"`,
+		LastModified: "2017-09-04T09:42:01Z",
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Object.UploadPartCopy returned %+v, want %+v", ref, want)
+	}
+	if opt.XCosCopySource != "ignored" {
+		t.Errorf("Object.UploadPartCopy should not modify opt")
+	}
+}
+
 func TestObjectService_ListParts(t *testing.T) {
 	setup()
 	defer teardown()
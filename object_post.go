@@ -0,0 +1,266 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ObjectPostPolicyOptions ...
+//
+// https://cloud.tencent.com/document/product/436/14690
+type ObjectPostPolicyOptions struct {
+	// 上传的对象的名称必须等于 Key
+	Key string
+	// 上传的对象的名称必须以 KeyPrefix 开头，比如 uploads/ 。Key 和 KeyPrefix 都为空时允许上传任意名称的对象
+	KeyPrefix string
+	// 上传的文件大小的范围，单位是 Byte，MaxContentLength 为 0 时不限制文件大小
+	MinContentLength int64
+	MaxContentLength int64
+	// 上传的文件的 Content-Type 必须等于 ContentType
+	ContentType string
+	// 上传的文件的 Content-Type 必须以 ContentTypePrefix 开头，比如 image/
+	ContentTypePrefix string
+	// 上传成功时返回的状态码，枚举值：200，201，204，为 0 时使用 COS 的默认值 204
+	SuccessActionStatus int
+	// 临时密钥的 token，使用临时密钥签名时需要指定
+	SessionToken string
+	// 其他条件，每个条件是 map[string]string（精确匹配）或者 []interface{}，比如：
+	//
+	//	[]interface{}{"starts-with", "$Cache-Control", "max-age="}
+	Conditions []interface{}
+
+	authTime *AuthTime
+}
+
+// ObjectPostPolicyResult ...
+type ObjectPostPolicyResult struct {
+	// 表单的提交地址
+	URL *url.URL
+	// 策略的 JSON 原文
+	Policy string
+	// 需要添加到表单中的字段，包括 policy 和签名相关的字段
+	Fields map[string]string
+}
+
+// postPolicyDocument POST Object 的策略
+type postPolicyDocument struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// PostPolicy 生成 POST Object 表单上传所需的策略和签名，可用于浏览器等无需知道 SecretID 和 SecretKey 就可以上传文件的场景。
+//
+// 策略在 auth.Expire 后过期（默认是 time.Hour），
+// 返回的 Fields 需要和文件一起作为 multipart/form-data 表单提交到 URL（文件需要是表单的最后一个字段），
+// 也可以作为 ObjectPostOptions.Fields 使用 Object.PostObject 上传。
+// 生成策略不需要发送请求，ctx 目前不会被使用，保留该参数是为了和 PresignedURL 保持一致。
+//
+// https://cloud.tencent.com/document/product/436/14690
+func (s *ObjectService) PostPolicy(ctx context.Context, auth Auth, opt *ObjectPostPolicyOptions) (*ObjectPostPolicyResult, error) {
+	if opt == nil {
+		opt = &ObjectPostPolicyOptions{}
+	}
+	authTime := opt.authTime
+	if authTime == nil {
+		authTime = NewAuthTime(auth.Expire)
+	}
+	keyTime := authTime.keyString()
+
+	conditions := []interface{}{
+		map[string]string{"q-sign-algorithm": sha1SignAlgorithm},
+		map[string]string{"q-ak": auth.SecretID},
+		map[string]string{"q-sign-time": keyTime},
+	}
+	if bucket := bucketNameFromURL(s.client.BaseURL.BucketURL); bucket != "" {
+		conditions = append(conditions, map[string]string{"bucket": bucket})
+	}
+	fields := map[string]string{}
+	if opt.Key != "" {
+		fields["key"] = opt.Key
+		conditions = append(conditions, map[string]string{"key": opt.Key})
+	} else if opt.KeyPrefix != "" {
+		conditions = append(conditions, []interface{}{"starts-with", "$key", opt.KeyPrefix})
+	}
+	if opt.MaxContentLength > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", opt.MinContentLength, opt.MaxContentLength})
+	}
+	if opt.ContentType != "" {
+		fields["Content-Type"] = opt.ContentType
+		conditions = append(conditions, map[string]string{"Content-Type": opt.ContentType})
+	} else if opt.ContentTypePrefix != "" {
+		conditions = append(conditions, []interface{}{"starts-with", "$Content-Type", opt.ContentTypePrefix})
+	}
+	if opt.SuccessActionStatus != 0 {
+		status := strconv.Itoa(opt.SuccessActionStatus)
+		fields["success_action_status"] = status
+		conditions = append(conditions, map[string]string{"success_action_status": status})
+	}
+	if opt.SessionToken != "" {
+		fields["x-cos-security-token"] = opt.SessionToken
+		conditions = append(conditions, map[string]string{"x-cos-security-token": opt.SessionToken})
+	}
+	conditions = append(conditions, opt.Conditions...)
+
+	policy, err := json.Marshal(&postPolicyDocument{
+		Expiration: authTime.KeyEndTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// StringToSign 是策略原文的 SHA1 值
+	signKey := calSignKey(auth.SecretKey, keyTime)
+	signature := calSignature(signKey, fmt.Sprintf("%x", sha1.Sum(policy)))
+	fields["policy"] = base64.StdEncoding.EncodeToString(policy)
+	fields["q-sign-algorithm"] = sha1SignAlgorithm
+	fields["q-ak"] = auth.SecretID
+	fields["q-key-time"] = keyTime
+	fields["q-signature"] = signature
+
+	u := *s.client.BaseURL.BucketURL
+	u.Path = "/"
+	return &ObjectPostPolicyResult{
+		URL:    &u,
+		Policy: string(policy),
+		Fields: fields,
+	}, nil
+}
+
+// bucketNameFromURL 从形如 https://test-1253846586.cos.ap-beijing.myqcloud.com 的 BucketURL 中提取 Bucket 的名称
+func bucketNameFromURL(u *url.URL) string {
+	if i := strings.Index(u.Host, ".cos."); i > 0 {
+		return u.Host[:i]
+	}
+	return ""
+}
+
+// ObjectPostOptions ...
+type ObjectPostOptions struct {
+	*ACLHeaderOptions       `header:",omitempty" url:"-" xml:"-"`
+	*ObjectPutHeaderOptions `header:",omitempty" url:"-" xml:"-"`
+
+	// 额外的表单字段，比如 Object.PostPolicy 返回的 Fields
+	Fields map[string]string `header:"-" url:"-" xml:"-"`
+	// 表单中文件的文件名，默认使用对象名称中最后一个 / 之后的部分
+	FileName string `header:"-" url:"-" xml:"-"`
+}
+
+// objectPostHeaderOptions POST Object 请求的头部
+type objectPostHeaderOptions struct {
+	ContentType   string `header:"Content-Type"`
+	ContentLength int64  `header:"Content-Length,omitempty"`
+}
+
+// 不能作为表单字段的头部
+var postObjectSkipHeaders = map[string]bool{
+	"Content-Length": true,
+	"Expect":         true,
+}
+
+// MethodObjectPostObject method name of Object.PostObject
+const MethodObjectPostObject MethodName = "Object.PostObject"
+
+// PostObject ...
+//
+// Post Object 接口请求可以通过 multipart/form-data 表单的方式将文件上传至指定 Bucket，
+// 用于不支持 PUT 请求的客户端。opt 中的头部选项会被转换为同名的表单字段，
+// 没有使用 Authorization header 签名的 Client 可以通过 opt.Fields 指定 Object.PostPolicy 生成的策略和签名。
+//
+// r 的大小已知时（比如 *bytes.Reader 或者 *os.File）会设置请求的 Content-Length，否则使用 chunked 编码上传。
+// 当 r 是个 io.ReadCloser 时 PostObject 方法不会自动调用 r.Close()。
+//
+// https://cloud.tencent.com/document/product/436/14690
+func (s *ObjectService) PostObject(ctx context.Context, name string, r io.Reader, opt *ObjectPostOptions) (*Response, error) {
+	if opt == nil {
+		opt = &ObjectPostOptions{}
+	}
+	fields := map[string]string{}
+	for k, v := range opt.Fields {
+		fields[k] = v
+	}
+	h, err := addHeaderOptions(http.Header{}, opt)
+	if err != nil {
+		return nil, err
+	}
+	for k := range h {
+		if postObjectSkipHeaders[k] {
+			continue
+		}
+		// 表单字段只能有一个值，多个值（比如 XCosMetaXXX 中重复的 header）使用逗号连接
+		v := strings.Join(h[k], ",")
+		if strings.HasPrefix(strings.ToLower(k), privateHeaderPrefix) {
+			fields[strings.ToLower(k)] = v
+		} else {
+			fields[k] = v
+		}
+	}
+	delete(fields, "key")
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	// key 需要在文件字段之前
+	w.WriteField("key", name)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.WriteField(k, fields[k])
+	}
+	fileName := opt.FileName
+	if fileName == "" {
+		fileName = name[strings.LastIndex(name, "/")+1:]
+	}
+	if _, err := w.CreateFormFile("file", fileName); err != nil {
+		return nil, err
+	}
+	n := buf.Len()
+	w.Close()
+	head := buf.Bytes()[:n]
+	tail := buf.Bytes()[n:]
+
+	header := &objectPostHeaderOptions{
+		ContentType: w.FormDataContentType(),
+	}
+	if size, ok := postObjectReaderSize(r); ok {
+		header.ContentLength = int64(len(head)) + size + int64(len(tail))
+	}
+	sendOpt := sendOptions{
+		baseURL:   s.client.BaseURL.BucketURL,
+		uri:       "/",
+		method:    http.MethodPost,
+		body:      io.MultiReader(bytes.NewReader(head), r, bytes.NewReader(tail)),
+		optHeader: header,
+		caller: Caller{
+			Method: MethodObjectPostObject,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// postObjectReaderSize 返回 r 中剩余的数据的大小
+func postObjectReaderSize(r io.Reader) (int64, bool) {
+	if l, ok := r.(interface {
+		Len() int
+	}); ok {
+		return int64(l.Len()), true
+	}
+	if _, size, err := readerOffsetSize(r); err == nil {
+		return size, true
+	}
+	return 0, false
+}
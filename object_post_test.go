@@ -0,0 +1,152 @@
+package cos
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObjectService_PostPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	u, _ := url.Parse("https://test-1253846586.cos.ap-beijing.myqcloud.com")
+	client.BaseURL.BucketURL = u
+	startTime := time.Unix(1480932292, 0)
+	authTime := &AuthTime{
+		SignStartTime: startTime,
+		SignEndTime:   startTime.Add(time.Hour),
+		KeyStartTime:  startTime,
+		KeyEndTime:    startTime.Add(time.Hour),
+	}
+	auth := Auth{SecretID: "QmFzZTY0IGlzIGEgZ2VuZXJp", SecretKey: "AKIDZfbOA78asKUYBcXFrJD0a1ICvR98JM"}
+	opt := &ObjectPostPolicyOptions{
+		KeyPrefix:           "uploads/",
+		MaxContentLength:    1024,
+		ContentTypePrefix:   "image/",
+		SuccessActionStatus: 201,
+		SessionToken:        "token",
+		Conditions: []interface{}{
+			[]interface{}{"starts-with", "$Cache-Control", "max-age="},
+		},
+		authTime: authTime,
+	}
+
+	res, err := client.Object.PostPolicy(context.Background(), auth, opt)
+	if err != nil {
+		t.Fatalf("Object.PostPolicy returned error: %v", err)
+	}
+
+	wantPolicy := `{"expiration":"2016-12-05T11:04:52.000Z","conditions":[` +
+		`{"q-sign-algorithm":"sha1"},{"q-ak":"QmFzZTY0IGlzIGEgZ2VuZXJp"},{"q-sign-time":"1480932292;1480935892"},` +
+		`{"bucket":"test-1253846586"},["starts-with","$key","uploads/"],["content-length-range",0,1024],` +
+		`["starts-with","$Content-Type","image/"],{"success_action_status":"201"},{"x-cos-security-token":"token"},` +
+		`["starts-with","$Cache-Control","max-age="]]}`
+	if res.Policy != wantPolicy {
+		t.Errorf("Object.PostPolicy returned policy %s, want %s", res.Policy, wantPolicy)
+	}
+	if got := res.URL.String(); got != "https://test-1253846586.cos.ap-beijing.myqcloud.com/" {
+		t.Errorf("Object.PostPolicy returned URL %s", got)
+	}
+
+	signKey := fmt.Sprintf("%x", calHMACDigest(auth.SecretKey, "1480932292;1480935892", "sha1"))
+	stringToSign := fmt.Sprintf("%x", calSHA1Digest([]byte(wantPolicy)))
+	want := map[string]string{
+		"policy":                base64.StdEncoding.EncodeToString([]byte(wantPolicy)),
+		"q-sign-algorithm":      "sha1",
+		"q-ak":                  "QmFzZTY0IGlzIGEgZ2VuZXJp",
+		"q-key-time":            "1480932292;1480935892",
+		"q-signature":           fmt.Sprintf("%x", calHMACDigest(signKey, stringToSign, "sha1")),
+		"success_action_status": "201",
+		"x-cos-security-token":  "token",
+	}
+	if !reflect.DeepEqual(res.Fields, want) {
+		t.Errorf("Object.PostPolicy returned fields %+v, want %+v", res.Fields, want)
+	}
+}
+
+func TestObjectService_PostObject(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &ObjectPostOptions{
+		ACLHeaderOptions: &ACLHeaderOptions{
+			XCosACL: "public-read",
+		},
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType:   "text/plain",
+			ContentLength: 5,
+			XCosMetaXXX:   &http.Header{"X-Cos-Meta-Test": []string{"test"}, "X-Cos-Meta-Tags": []string{"a", "b"}},
+		},
+		Fields: map[string]string{
+			"key":         "ignored",
+			"policy":      "eyJleHBpcmF0aW9uIjoiIn0=",
+			"q-signature": "sign",
+		},
+	}
+	body := "hello"
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data; boundary=") {
+			t.Errorf("Object.PostObject request Content-Type: %s", r.Header.Get("Content-Type"))
+		}
+		if r.ContentLength <= int64(len(body)) {
+			t.Errorf("Object.PostObject request Content-Length: %d", r.ContentLength)
+		}
+
+		mr, err := r.MultipartReader()
+		if err != nil {
+			t.Fatalf("MultipartReader returned error: %v", err)
+		}
+		var names []string
+		fields := map[string]string{}
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			b, _ := ioutil.ReadAll(p)
+			names = append(names, p.FormName())
+			if p.FormName() == "file" && (p.FileName() != "hello.txt" || string(b) != body) {
+				t.Errorf("Object.PostObject file part: %s %q", p.FileName(), b)
+			}
+			fields[p.FormName()] = string(b)
+		}
+		delete(fields, "file")
+
+		wantNames := []string{"key", "Content-Type", "policy", "q-signature", "x-cos-acl", "x-cos-meta-tags", "x-cos-meta-test", "file"}
+		if !reflect.DeepEqual(names, wantNames) {
+			t.Errorf("Object.PostObject form fields: %v, want %v", names, wantNames)
+		}
+		want := map[string]string{
+			"key":             "test/hello.txt",
+			"Content-Type":    "text/plain",
+			"policy":          "eyJleHBpcmF0aW9uIjoiIn0=",
+			"q-signature":     "sign",
+			"x-cos-acl":       "public-read",
+			"x-cos-meta-tags": "a,b",
+			"x-cos-meta-test": "test",
+		}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("Object.PostObject form values: %+v, want %+v", fields, want)
+		}
+		w.Header().Set("ETag", `"5d41402abc4b2a76b9719d911017c592"`)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := client.Object.PostObject(context.Background(), "test/hello.txt", strings.NewReader(body), opt)
+	if err != nil {
+		t.Fatalf("Object.PostObject returned error: %v", err)
+	}
+	if resp.Header.Get("ETag") != `"5d41402abc4b2a76b9719d911017c592"` {
+		t.Errorf("Object.PostObject returned ETag %s", resp.Header.Get("ETag"))
+	}
+}
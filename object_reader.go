@@ -0,0 +1,315 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+const (
+	// 默认每次 Range 请求读取 1 MB
+	defaultReaderBlockSize = 1024 * 1024
+	// 默认缓存 4 个块
+	defaultReaderCacheBlocks = 4
+)
+
+var (
+	// ErrObjectChanged 创建 ObjectReader 后 Object 被覆盖（If-Match 不满足）或删除了
+	ErrObjectChanged = errors.New("cos: object has been changed since the reader was created")
+
+	errReaderClosed = errors.New("cos: read on closed ObjectReader")
+)
+
+// ObjectReaderOptions ...
+//
+// Object.NewReader 的参数
+type ObjectReaderOptions struct {
+	// 每次 Range 请求读取的块大小，单位是 Byte。默认值：1 MB
+	BlockSize int64
+	// 最多缓存的块数量，默认值：4。小于 ReadAhead + 2 时使用 ReadAhead + 2
+	CacheBlocks int
+	// 通过 Read 顺序读取时在后台预读的块数量，默认不预读
+	ReadAhead int
+	// 指定要读取的对象的版本 ID
+	VersionID string
+	// Object 使用 SSE-C 加密时需要指定上传时使用的密钥
+	SSECustomerHeaderOptions *SSECustomerHeaderOptions
+}
+
+// ObjectReader 通过 Object.NewReader 创建的随机读取 Object 的 reader，实现了 io.ReaderAt、io.ReadSeeker 和 io.Closer，
+// 可以用于 archive/zip 之类需要随机读取的场景。
+//
+// ObjectReader 按照 opt.BlockSize 将 Object 切分为多个块，每个块通过一个 Range 请求读取并缓存最近使用的 opt.CacheBlocks 个块。
+// 所有的 Range 请求都会带上创建时获取的 ETag（If-Match），Object 被覆盖或删除后读取会返回 ErrObjectChanged，
+// 不会读到不同版本混合在一起的数据。
+//
+// ReadAt 可以被多个 goroutine 并发调用，Read 和 Seek 不能被并发调用。
+type ObjectReader struct {
+	s    *ObjectService
+	name string
+	opt  *ObjectReaderOptions
+	// NewReader 的 ctx 和 Close 时会被取消的 ctx
+	parent context.Context
+	ctx    context.Context
+	stop   context.CancelFunc
+
+	blockSize   int64
+	cacheBlocks int
+	size        int64
+	etag        string
+	resp        *Response
+
+	// Read 和 Seek 使用的偏移量
+	offset int64
+
+	mu sync.Mutex
+	// 缓存的块，包括正在读取的块
+	blocks map[int64]*readerBlock
+	// 缓存的块编号，最近使用的在最后
+	lru []int64
+}
+
+// readerBlock 一个块的数据，done 关闭后 data 和 err 才有效
+type readerBlock struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewReader 返回一个随机读取 name 的 ObjectReader。
+//
+// NewReader 先通过 Object.Head 获取 Object 的大小和 ETag，之后的读取都是针对这个版本的 Object。
+// ctx 用于之后所有的 Range 请求，ctx 被取消或者调用了 Close 后读取会返回错误。
+func (s *ObjectService) NewReader(ctx context.Context, name string, opt *ObjectReaderOptions) (*ObjectReader, error) {
+	if opt == nil {
+		opt = &ObjectReaderOptions{}
+	}
+	resp, err := s.Head(ctx, name, &ObjectHeadOptions{
+		VersionID:                opt.VersionID,
+		SSECustomerHeaderOptions: opt.SSECustomerHeaderOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cos: invalid Content-Length of object %s: %v", name, err)
+	}
+
+	r := &ObjectReader{
+		s:           s,
+		name:        name,
+		opt:         opt,
+		parent:      ctx,
+		blockSize:   opt.BlockSize,
+		cacheBlocks: opt.CacheBlocks,
+		size:        size,
+		etag:        resp.Header.Get("ETag"),
+		resp:        resp,
+		blocks:      map[int64]*readerBlock{},
+	}
+	r.ctx, r.stop = context.WithCancel(ctx)
+	if r.blockSize <= 0 {
+		r.blockSize = defaultReaderBlockSize
+	}
+	if r.cacheBlocks <= 0 {
+		r.cacheBlocks = defaultReaderCacheBlocks
+	}
+	// 保证预读的块不会在使用前被淘汰
+	if r.cacheBlocks < opt.ReadAhead+2 {
+		r.cacheBlocks = opt.ReadAhead + 2
+	}
+	return r, nil
+}
+
+// Size 返回 Object 的大小
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+// ETag 返回创建 ObjectReader 时 Object 的 ETag
+func (r *ObjectReader) ETag() string {
+	return r.etag
+}
+
+// Response 返回创建 ObjectReader 时 Object.Head 的响应
+func (r *ObjectReader) Response() *Response {
+	return r.resp
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("cos: ObjectReader.ReadAt: negative offset")
+	}
+	n := 0
+	for n < len(p) && off < r.size {
+		i := off / r.blockSize
+		data, err := r.block(i)
+		if err != nil {
+			return n, err
+		}
+		c := copy(p[n:], data[off-i*r.blockSize:])
+		n += c
+		off += int64(c)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read implements the io.Reader interface.
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	if err == nil {
+		r.prefetch((r.offset-1)/r.blockSize + 1)
+	}
+	return n, err
+}
+
+// Seek implements the io.Seeker interface.
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("cos: ObjectReader.Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("cos: ObjectReader.Seek: negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close 取消正在进行的 Range 请求并释放缓存的块，之后的读取都会返回错误
+func (r *ObjectReader) Close() error {
+	r.stop()
+	r.mu.Lock()
+	r.blocks = map[int64]*readerBlock{}
+	r.lru = nil
+	r.mu.Unlock()
+	return nil
+}
+
+// prefetch 在后台读取从 i 开始的 opt.ReadAhead 个块
+func (r *ObjectReader) prefetch(i int64) {
+	for end := i + int64(r.opt.ReadAhead); i < end && i*r.blockSize < r.size; i++ {
+		if b, fetch := r.getBlock(i); fetch {
+			go r.fetch(i, b)
+		}
+	}
+}
+
+// block 返回第 i 个块的数据，块不在缓存中时读取该块
+func (r *ObjectReader) block(i int64) ([]byte, error) {
+	if r.ctx.Err() != nil {
+		return nil, r.closedErr()
+	}
+	b, fetch := r.getBlock(i)
+	if fetch {
+		r.fetch(i, b)
+	}
+	select {
+	case <-b.done:
+	case <-r.ctx.Done():
+		return nil, r.closedErr()
+	}
+	return b.data, b.err
+}
+
+// closedErr 返回 ctx 被取消或者调用了 Close 之后读取时的错误
+func (r *ObjectReader) closedErr() error {
+	if err := r.parent.Err(); err != nil {
+		return err
+	}
+	return errReaderClosed
+}
+
+// getBlock 返回缓存中的第 i 个块，不在缓存中时加入一个新的块，fetch 为 true 表示调用方需要读取该块
+func (r *ObjectReader) getBlock(i int64) (b *readerBlock, fetch bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for j, k := range r.lru {
+		if k == i {
+			r.lru = append(append(r.lru[:j:j], r.lru[j+1:]...), i)
+			break
+		}
+	}
+	if b, ok := r.blocks[i]; ok {
+		return b, false
+	}
+	b = &readerBlock{done: make(chan struct{})}
+	r.blocks[i] = b
+	r.lru = append(r.lru, i)
+	// 淘汰最久没有使用的块，正在读取这些块的调用方不受影响
+	for len(r.lru) > r.cacheBlocks {
+		delete(r.blocks, r.lru[0])
+		r.lru = r.lru[1:]
+	}
+	return b, true
+}
+
+// fetch 通过 Range 请求读取第 i 个块，出错时将该块移出缓存以便下次重新读取
+func (r *ObjectReader) fetch(i int64, b *readerBlock) {
+	b.data, b.err = r.readRange(i*r.blockSize, (i+1)*r.blockSize)
+	if b.err != nil {
+		r.mu.Lock()
+		if r.blocks[i] == b {
+			delete(r.blocks, i)
+			for j, k := range r.lru {
+				if k == i {
+					r.lru = append(r.lru[:j:j], r.lru[j+1:]...)
+					break
+				}
+			}
+		}
+		r.mu.Unlock()
+	}
+	close(b.done)
+}
+
+// readRange 读取 [start, end) 范围的数据
+func (r *ObjectReader) readRange(start, end int64) ([]byte, error) {
+	if end > r.size {
+		end = r.size
+	}
+	opt := &ObjectGetOptions{
+		Range:                    fmt.Sprintf("bytes=%d-%d", start, end-1),
+		IfMatch:                  r.etag,
+		VersionID:                r.opt.VersionID,
+		SSECustomerHeaderOptions: r.opt.SSECustomerHeaderOptions,
+	}
+	resp, err := r.s.Get(r.ctx, r.name, opt)
+	if err != nil {
+		// NewReader 时 Object 是存在的，404 说明 Object 已被删除
+		if code := errorStatusCode(err); code == http.StatusPreconditionFailed || code == http.StatusNotFound {
+			return nil, ErrObjectChanged
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent && start > 0 {
+		return nil, fmt.Errorf("cos: range %s of object %s is not satisfied: %s", opt.Range, r.name, resp.Status)
+	}
+	data := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
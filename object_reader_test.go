@@ -0,0 +1,262 @@
+package cos
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestObjectService_NewReader(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	fs := newFakeServer(t)
+	o := fs.putObject("test/hello.txt", data, nil)
+
+	r, err := client.Object.NewReader(context.Background(), "test/hello.txt", &ObjectReaderOptions{
+		BlockSize:   100,
+		CacheBlocks: 2,
+	})
+	if err != nil {
+		t.Fatalf("Object.NewReader returned error: %v", err)
+	}
+	defer r.Close()
+	if r.Size() != int64(len(data)) || r.ETag() != o.etag {
+		t.Errorf("ObjectReader size %d, etag %s, want %d, %s", r.Size(), r.ETag(), len(data), o.etag)
+	}
+
+	// 跨越多个块读取
+	p := make([]byte, 150)
+	if n, err := r.ReadAt(p, 80); n != len(p) || err != nil || !bytes.Equal(p, data[80:230]) {
+		t.Errorf("ObjectReader.ReadAt returned %d, %v", n, err)
+	}
+	want := []string{"bytes=0-99", "bytes=100-199", "bytes=200-299"}
+	if ranges := fs.ranges(); !reflect.DeepEqual(ranges, want) {
+		t.Errorf("Range headers: %v, want %v", ranges, want)
+	}
+	// 命中缓存
+	fs.clearLog()
+	r.ReadAt(p[:50], 250)
+	if ranges := fs.ranges(); len(ranges) != 0 || !bytes.Equal(p[:50], data[250:300]) {
+		t.Errorf("ObjectReader.ReadAt should read from cache, got Range headers: %v", ranges)
+	}
+	// 第一个块已经被淘汰
+	r.ReadAt(p[:10], 0)
+	if ranges, want := fs.ranges(), []string{"bytes=0-99"}; !reflect.DeepEqual(ranges, want) {
+		t.Errorf("Range headers: %v, want %v", ranges, want)
+	}
+
+	// 读取到结尾
+	if n, err := r.ReadAt(p, 900); n != 100 || err != io.EOF || !bytes.Equal(p[:n], data[900:]) {
+		t.Errorf("ObjectReader.ReadAt returned %d, %v, want 100, EOF", n, err)
+	}
+	if n, err := r.ReadAt(p, 1000); n != 0 || err != io.EOF {
+		t.Errorf("ObjectReader.ReadAt returned %d, %v, want 0, EOF", n, err)
+	}
+}
+
+func TestObjectReader_ReadSeek(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	newFakeServer(t).putObject("test/hello.txt", data, nil)
+
+	r, err := client.Object.NewReader(context.Background(), "test/hello.txt", &ObjectReaderOptions{BlockSize: 128})
+	if err != nil {
+		t.Fatalf("Object.NewReader returned error: %v", err)
+	}
+	defer r.Close()
+
+	if pos, err := r.Seek(-100, io.SeekEnd); pos != 900 || err != nil {
+		t.Errorf("ObjectReader.Seek returned %d, %v", pos, err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil || !bytes.Equal(got, data[900:]) {
+		t.Errorf("ObjectReader.Read returned %d bytes, %v", len(got), err)
+	}
+	r.Seek(0, io.SeekStart)
+	r.Seek(10, io.SeekCurrent)
+	got, err = ioutil.ReadAll(r)
+	if err != nil || !bytes.Equal(got, data[10:]) {
+		t.Errorf("ObjectReader.Read returned %d bytes, %v", len(got), err)
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Error("ObjectReader.Seek should return error for negative position")
+	}
+}
+
+func TestObjectReader_readAhead(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	fs := newFakeServer(t)
+	fs.putObject("test/hello.txt", data, nil)
+
+	r, err := client.Object.NewReader(context.Background(), "test/hello.txt", &ObjectReaderOptions{
+		BlockSize: 100,
+		ReadAhead: 2,
+	})
+	if err != nil {
+		t.Fatalf("Object.NewReader returned error: %v", err)
+	}
+	defer r.Close()
+
+	p := make([]byte, 10)
+	r.Read(p)
+	// 等待后台预读完成
+	for i := 1; i <= 2; i++ {
+		r.mu.Lock()
+		b := r.blocks[int64(i)]
+		r.mu.Unlock()
+		if b == nil {
+			t.Fatalf("block %d is not prefetched", i)
+		}
+		<-b.done
+	}
+	if ranges := fs.ranges(); len(ranges) != 3 {
+		t.Errorf("got %d Range requests, want 3: %v", len(ranges), ranges)
+	}
+
+	// 读取预读的块时不再发送请求
+	got := make([]byte, 250)
+	io.ReadFull(r, got)
+	if !bytes.Equal(got, data[10:260]) {
+		t.Error("ObjectReader.Read returned wrong data")
+	}
+	ranges := fs.ranges()
+	seen := map[string]bool{}
+	for _, rg := range ranges {
+		if seen[rg] {
+			t.Errorf("Range %s is requested more than once: %v", rg, ranges)
+		}
+		seen[rg] = true
+	}
+}
+
+func TestObjectReader_concurrent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(10000)
+	newFakeServer(t).putObject("test/hello.txt", data, nil)
+
+	r, err := client.Object.NewReader(context.Background(), "test/hello.txt", &ObjectReaderOptions{BlockSize: 256})
+	if err != nil {
+		t.Fatalf("Object.NewReader returned error: %v", err)
+	}
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(off int64) {
+			defer wg.Done()
+			p := make([]byte, 1000)
+			if n, err := r.ReadAt(p, off); n != len(p) || err != nil || !bytes.Equal(p, data[off:off+1000]) {
+				t.Errorf("ObjectReader.ReadAt(%d) returned %d, %v", off, n, err)
+			}
+		}(int64(i * 900))
+	}
+	wg.Wait()
+}
+
+func TestObjectReader_objectChanged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := testUploadData(1000)
+	etag := `"v1"`
+	deleted := false
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		if deleted {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+
+	r, err := client.Object.NewReader(context.Background(), "test/hello.txt", &ObjectReaderOptions{BlockSize: 100})
+	if err != nil {
+		t.Fatalf("Object.NewReader returned error: %v", err)
+	}
+	p := make([]byte, 10)
+	if _, err := r.ReadAt(p, 0); err != nil {
+		t.Fatalf("ObjectReader.ReadAt returned error: %v", err)
+	}
+
+	// Object 被覆盖
+	etag = `"v2"`
+	if _, err := r.ReadAt(p, 500); err != ErrObjectChanged {
+		t.Errorf("ObjectReader.ReadAt returned error %v, want %v", err, ErrObjectChanged)
+	}
+	// 缓存的块仍然可以读取
+	if _, err := r.ReadAt(p, 0); err != nil {
+		t.Errorf("ObjectReader.ReadAt returned error: %v", err)
+	}
+
+	// Object 被删除
+	deleted = true
+	if _, err := r.ReadAt(p, 800); err != ErrObjectChanged {
+		t.Errorf("ObjectReader.ReadAt returned error %v, want %v", err, ErrObjectChanged)
+	}
+
+	r.Close()
+	if _, err := r.ReadAt(p, 0); err != errReaderClosed {
+		t.Errorf("ObjectReader.ReadAt returned error %v, want %v", err, errReaderClosed)
+	}
+}
+
+func TestObjectReader_zip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string][]byte{
+		"a.txt": []byte("hello"),
+		"b.txt": testUploadData(5000),
+	}
+	for name, content := range files {
+		f, _ := zw.Create(name)
+		f.Write(content)
+	}
+	zw.Close()
+	newFakeServer(t).putObject("test/hello.zip", buf.Bytes(), nil)
+
+	r, err := client.Object.NewReader(context.Background(), "test/hello.zip", &ObjectReaderOptions{BlockSize: 512})
+	if err != nil {
+		t.Fatalf("Object.NewReader returned error: %v", err)
+	}
+	defer r.Close()
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		t.Fatalf("zip.NewReader returned error: %v", err)
+	}
+	if len(zr.File) != len(files) {
+		t.Fatalf("zip file contains %d files, want %d", len(zr.File), len(files))
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("zip.File.Open returned error: %v", err)
+		}
+		got, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil || !bytes.Equal(got, files[f.Name]) {
+			t.Errorf("content of %s: %d bytes, %v", f.Name, len(got), err)
+		}
+	}
+}
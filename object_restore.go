@@ -0,0 +1,160 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// RestoreTierExpedited 恢复模式: 极速模式，恢复任务在 1 - 5 分钟内完成
+	RestoreTierExpedited string = "Expedited"
+	// RestoreTierStandard 恢复模式: 标准模式，恢复任务在 3 - 5 小时内完成
+	RestoreTierStandard string = "Standard"
+	// RestoreTierBulk 恢复模式: 批量模式，恢复任务在 5 - 12 小时内完成
+	RestoreTierBulk string = "Bulk"
+)
+
+// ObjectRestoreJobParameters ...
+type ObjectRestoreJobParameters struct {
+	// 恢复模式，枚举值：Expedited，Standard，Bulk，默认值：Standard
+	Tier string `xml:"Tier"`
+}
+
+// ObjectPostRestoreOptions ...
+//
+// https://cloud.tencent.com/document/product/436/12633
+type ObjectPostRestoreOptions struct {
+	XMLName xml.Name `xml:"RestoreRequest" url:"-"`
+	// 恢复出的临时副本的过期时间，单位是天
+	Days int `xml:"Days" url:"-"`
+	// 恢复任务的参数
+	CASJobParameters *ObjectRestoreJobParameters `xml:"CASJobParameters,omitempty" url:"-"`
+	// 指定要恢复的对象的版本 ID
+	VersionID string `xml:"-" url:"versionId,omitempty"`
+}
+
+// MethodObjectPostRestore method name of Object.PostRestore
+const MethodObjectPostRestore MethodName = "Object.PostRestore"
+
+// PostRestore ...
+//
+// Post Object Restore 接口可以对一个归档存储（ARCHIVE）类型的对象进行恢复，
+// 恢复出的临时副本可以在 opt.Days 天内读取，过期后会被删除。
+//
+// 第一次发起恢复时返回 202 Accepted，临时副本已经存在时返回 200 OK 并延长临时副本的过期时间。
+// 通过 Object.Head 返回的 Response.Restore() 可以查询恢复任务的状态，WaitRestored 会一直等待恢复任务完成。
+//
+// https://cloud.tencent.com/document/product/436/12633
+func (s *ObjectService) PostRestore(ctx context.Context, name string, opt *ObjectPostRestoreOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/" + encodeURIComponent(name) + "?restore",
+		method:   http.MethodPost,
+		optQuery: opt,
+		body:     opt,
+		caller: Caller{
+			Method: MethodObjectPostRestore,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// ObjectRestoreStatus x-cos-restore 头部表示的归档对象的恢复状态
+type ObjectRestoreStatus struct {
+	// 恢复任务是否还在进行中
+	OngoingRequest bool
+	// 恢复任务完成后临时副本的过期时间，恢复任务进行中时为零值
+	ExpiryDate time.Time
+}
+
+// Restore 归档对象的恢复状态，没有发起过恢复或者不是归档对象时返回 nil
+func (resp *Response) Restore() *ObjectRestoreStatus {
+	v := resp.Header.Get(xCosRestore)
+	if v == "" {
+		return nil
+	}
+	// 格式：ongoing-request="false", expiry-date="Wed, 21 Aug 2019 00:00:00 GMT"
+	status := &ObjectRestoreStatus{}
+	for v != "" {
+		var kv string
+		if i := strings.Index(v, `",`); i >= 0 {
+			kv, v = v[:i+1], v[i+2:]
+		} else {
+			kv, v = v, ""
+		}
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(kv[:i])
+		value := strings.Trim(strings.TrimSpace(kv[i+1:]), `"`)
+		switch strings.ToLower(key) {
+		case "ongoing-request":
+			status.OngoingRequest = value == "true"
+		case "expiry-date":
+			status.ExpiryDate, _ = http.ParseTime(value)
+		}
+	}
+	return status
+}
+
+// ErrRestoreNotRequested 归档对象没有发起过恢复时 WaitRestored 返回的错误
+var ErrRestoreNotRequested = errors.New("cos: restore has not been requested for the archived object")
+
+// ObjectWaitRestoredOptions ...
+type ObjectWaitRestoredOptions struct {
+	// 第一次查询前的等待时间，之后每次的等待时间翻倍，默认值：30s
+	MinInterval time.Duration
+	// 两次查询之间的最长等待时间，默认值：10min
+	MaxInterval time.Duration
+	// 指定要等待的对象的版本 ID
+	VersionID string
+}
+
+const (
+	defaultWaitRestoredMinInterval = 30 * time.Second
+	defaultWaitRestoredMaxInterval = 10 * time.Minute
+)
+
+// WaitRestored 使用 Object.Head 轮询归档对象的恢复状态，直到恢复任务完成、对象可以读取，
+// 返回最后一次 Object.Head 的结果。两次查询之间使用带随机抖动的指数退避算法等待，
+// 可以通过 ctx 设置最长的等待时间。
+//
+// 对象不是归档对象时立即返回，归档对象没有发起过恢复时返回 ErrRestoreNotRequested 。
+func (s *ObjectService) WaitRestored(ctx context.Context, name string, opt *ObjectWaitRestoredOptions) (*Response, error) {
+	var o ObjectWaitRestoredOptions
+	if opt != nil {
+		o = *opt
+	}
+	if o.MinInterval <= 0 {
+		o.MinInterval = defaultWaitRestoredMinInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultWaitRestoredMaxInterval
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := s.Head(ctx, name, &ObjectHeadOptions{VersionID: o.VersionID})
+		if err != nil {
+			return resp, err
+		}
+		status := resp.Restore()
+		if status == nil {
+			if strings.EqualFold(resp.StorageClass(), StorageClassArchive) {
+				return resp, ErrRestoreNotRequested
+			}
+			return resp, nil
+		}
+		if !status.OngoingRequest {
+			return resp, nil
+		}
+		if err := sleepContext(ctx, backoffDelay(o.MinInterval, o.MaxInterval, attempt)); err != nil {
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestObjectService_PostRestore(t *testing.T) {
+	setup()
+	defer teardown()
+
+	name := "test/hello.txt"
+	opt := &ObjectPostRestoreOptions{
+		Days:             3,
+		CASJobParameters: &ObjectRestoreJobParameters{Tier: RestoreTierExpedited},
+		VersionID:        "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+	}
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		v := new(ObjectPostRestoreOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPost)
+		vs := values{
+			"restore":   "",
+			"versionId": "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+		}
+		testFormValues(t, r, vs)
+
+		want := &ObjectPostRestoreOptions{
+			XMLName:          xml.Name{Local: "RestoreRequest"},
+			Days:             3,
+			CASJobParameters: &ObjectRestoreJobParameters{Tier: RestoreTierExpedited},
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Object.PostRestore request body: %+v, want %+v", v, want)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	resp, err := client.Object.PostRestore(context.Background(), name, opt)
+	if err != nil {
+		t.Fatalf("Object.PostRestore returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Object.PostRestore returned status %d", resp.StatusCode)
+	}
+}
+
+func TestResponse_Restore(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *ObjectRestoreStatus
+	}{
+		{"", nil},
+		{`ongoing-request="true"`, &ObjectRestoreStatus{OngoingRequest: true}},
+		{
+			`ongoing-request="false", expiry-date="Wed, 21 Aug 2019 00:00:00 GMT"`,
+			&ObjectRestoreStatus{ExpiryDate: time.Date(2019, 8, 21, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		resp := newResponse(&http.Response{Header: http.Header{}})
+		if tt.header != "" {
+			resp.Header.Set(xCosRestore, tt.header)
+		}
+		if got := resp.Restore(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Response.Restore() with %q returned %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestObjectService_WaitRestored(t *testing.T) {
+	setup()
+	defer teardown()
+
+	heads := 0
+	mux.HandleFunc("/archived.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodHead)
+		heads++
+		w.Header().Set(xCosStorageClass, StorageClassArchive)
+		if heads < 3 {
+			w.Header().Set(xCosRestore, `ongoing-request="true"`)
+		} else {
+			w.Header().Set(xCosRestore, `ongoing-request="false", expiry-date="Wed, 21 Aug 2019 00:00:00 GMT"`)
+		}
+	})
+
+	opt := &ObjectWaitRestoredOptions{MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+	resp, err := client.Object.WaitRestored(context.Background(), "archived.txt", opt)
+	if err != nil {
+		t.Fatalf("Object.WaitRestored returned error: %v", err)
+	}
+	if heads != 3 || resp.Restore() == nil || resp.Restore().OngoingRequest {
+		t.Errorf("Object.WaitRestored returned after %d requests with %+v", heads, resp.Restore())
+	}
+}
+
+func TestObjectService_WaitRestored_notRequested(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/archived.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(xCosStorageClass, StorageClassArchive)
+	})
+	mux.HandleFunc("/standard.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(xCosStorageClass, StorageClassStandard)
+	})
+
+	ctx := context.Background()
+	if _, err := client.Object.WaitRestored(ctx, "archived.txt", nil); err != ErrRestoreNotRequested {
+		t.Errorf("Object.WaitRestored returned error %v, want %v", err, ErrRestoreNotRequested)
+	}
+	if _, err := client.Object.WaitRestored(ctx, "standard.txt", nil); err != nil {
+		t.Errorf("Object.WaitRestored returned error: %v", err)
+	}
+}
+
+func TestObjectService_WaitRestored_contextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/archived.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(xCosRestore, `ongoing-request="true"`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Object.WaitRestored(ctx, "archived.txt", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Object.WaitRestored returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,323 @@
+package cos
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+)
+
+// SelectCSVInput 输入对象为 CSV 格式时的配置
+type SelectCSVInput struct {
+	// 文件是否包含表头，枚举值：NONE（没有表头），USE（使用表头中的列名），IGNORE（忽略表头），默认值：NONE
+	FileHeaderInfo string `xml:"FileHeaderInfo,omitempty"`
+	// 记录分隔符，默认值：\n
+	RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+	// 列分隔符，默认值：,
+	FieldDelimiter string `xml:"FieldDelimiter,omitempty"`
+	// 引号字符，默认值："
+	QuoteCharacter string `xml:"QuoteCharacter,omitempty"`
+	// 引号转义字符，默认值："
+	QuoteEscapeCharacter string `xml:"QuoteEscapeCharacter,omitempty"`
+	// 注释行的前缀字符，以该字符开头的行会被忽略
+	Comments string `xml:"Comments,omitempty"`
+	// 列中是否可以包含被引号括起来的记录分隔符，枚举值：TRUE，FALSE，默认值：FALSE
+	AllowQuotedRecordDelimiter string `xml:"AllowQuotedRecordDelimiter,omitempty"`
+}
+
+// SelectJSONInput 输入对象为 JSON 格式时的配置
+type SelectJSONInput struct {
+	// JSON 文件的类型，枚举值：DOCUMENT（整个文件是一个 JSON 对象），LINES（每行一个 JSON 对象）
+	Type string `xml:"Type"`
+}
+
+// SelectInputSerialization 输入对象的格式，CSV 和 JSON 只能指定一个
+type SelectInputSerialization struct {
+	// 输入对象的压缩格式，枚举值：NONE，GZIP，BZIP2，默认值：NONE
+	CompressionType string           `xml:"CompressionType,omitempty"`
+	CSV             *SelectCSVInput  `xml:"CSV,omitempty"`
+	JSON            *SelectJSONInput `xml:"JSON,omitempty"`
+}
+
+// SelectCSVOutput 以 CSV 格式返回查询结果时的配置
+type SelectCSVOutput struct {
+	// 是否给所有的列加上引号，枚举值：ASNEEDED（需要时才加），ALWAYS，默认值：ASNEEDED
+	QuoteFields string `xml:"QuoteFields,omitempty"`
+	// 记录分隔符，默认值：\n
+	RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+	// 列分隔符，默认值：,
+	FieldDelimiter string `xml:"FieldDelimiter,omitempty"`
+	// 引号字符，默认值："
+	QuoteCharacter string `xml:"QuoteCharacter,omitempty"`
+	// 引号转义字符，默认值："
+	QuoteEscapeCharacter string `xml:"QuoteEscapeCharacter,omitempty"`
+}
+
+// SelectJSONOutput 以 JSON 格式返回查询结果时的配置
+type SelectJSONOutput struct {
+	// 记录分隔符，默认值：\n
+	RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+}
+
+// SelectOutputSerialization 查询结果的格式，CSV 和 JSON 只能指定一个
+type SelectOutputSerialization struct {
+	CSV  *SelectCSVOutput  `xml:"CSV,omitempty"`
+	JSON *SelectJSONOutput `xml:"JSON,omitempty"`
+}
+
+// SelectRequestProgress ...
+type SelectRequestProgress struct {
+	// 是否定期返回查询进度（Progress 事件），枚举值：TRUE，FALSE，默认值：FALSE
+	Enabled string `xml:"Enabled"`
+}
+
+// ObjectSelectOptions ...
+//
+// https://cloud.tencent.com/document/product/436/37641
+type ObjectSelectOptions struct {
+	XMLName xml.Name `xml:"SelectRequest"`
+	// SQL 表达式，比如：Select * from COSObject s where s.age > 30
+	Expression string `xml:"Expression"`
+	// 表达式的类型，目前只支持 SQL
+	ExpressionType      string                     `xml:"ExpressionType"`
+	InputSerialization  *SelectInputSerialization  `xml:"InputSerialization"`
+	OutputSerialization *SelectOutputSerialization `xml:"OutputSerialization"`
+	RequestProgress     *SelectRequestProgress     `xml:"RequestProgress,omitempty"`
+}
+
+const (
+	// SelectEventRecords 事件类型: 查询结果，Payload 为查询结果的一部分
+	SelectEventRecords string = "Records"
+	// SelectEventStats 事件类型: 查询结束时的统计信息
+	SelectEventStats string = "Stats"
+	// SelectEventProgress 事件类型: 查询进度
+	SelectEventProgress string = "Progress"
+	// SelectEventCont 事件类型: 保持连接的心跳事件
+	SelectEventCont string = "Cont"
+	// SelectEventEnd 事件类型: 查询结束，这是最后一个事件
+	SelectEventEnd string = "End"
+)
+
+// SelectStats Stats 和 Progress 事件中的统计信息
+type SelectStats struct {
+	// 已扫描的字节数（压缩后）
+	BytesScanned int64
+	// 已处理的字节数（解压后）
+	BytesProcessed int64
+	// 已返回的查询结果字节数
+	BytesReturned int64
+}
+
+// SelectEvent Select 返回的事件流中的一个事件
+type SelectEvent struct {
+	// 事件类型，枚举值：Records，Stats，Progress，Cont，End
+	Type string
+	// 事件的原始内容，Records 事件为查询结果，Stats 和 Progress 事件为 XML 格式的统计信息
+	Payload []byte
+	// Stats 和 Progress 事件解析后的统计信息，其他事件为 nil
+	Stats *SelectStats
+}
+
+// ErrSelectChecksumMismatch 事件流中的消息校验失败时返回的错误
+var ErrSelectChecksumMismatch = errors.New("cos: select event stream checksum mismatch")
+
+// 事件流中单个消息的最大长度
+const selectMaxMessageLength = 16 * 1024 * 1024
+
+// SelectEventStream 解码 Select 返回的事件流：
+//
+//	stream, _, err := c.Object.Select(ctx, name, opt)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//		e := stream.Event()
+//		if e.Type == cos.SelectEventRecords {
+//			os.Stdout.Write(e.Payload)
+//		}
+//	}
+//	if err := stream.Err(); err != nil {
+//		panic(err)
+//	}
+//
+// 每个消息都会校验 CRC32。查询过程中服务端返回的错误事件会以 *ErrorResponse 的形式由 Err 返回，
+// 没有收到 End 事件就结束的事件流被认为是不完整的，Err 返回 io.ErrUnexpectedEOF 。
+type SelectEventStream struct {
+	resp  *Response
+	body  io.ReadCloser
+	event *SelectEvent
+	end   bool
+	err   error
+}
+
+// Next 读取下一个事件，事件流结束或者出错时返回 false
+func (s *SelectEventStream) Next() bool {
+	if s.err != nil || s.end {
+		s.event = nil
+		return false
+	}
+	e, err := s.readEvent()
+	if err != nil {
+		s.event, s.err = nil, err
+		return false
+	}
+	s.event = e
+	s.end = e.Type == SelectEventEnd
+	return true
+}
+
+// Event 返回 Next 读取到的事件
+func (s *SelectEventStream) Event() *SelectEvent {
+	return s.event
+}
+
+// Err 返回读取事件流时遇到的错误
+func (s *SelectEventStream) Err() error {
+	return s.err
+}
+
+// Close 关闭事件流，没有读取完所有事件时也需要调用
+func (s *SelectEventStream) Close() error {
+	return s.body.Close()
+}
+
+// ReadRecords 将所有 Records 事件的内容写入 w，返回 Stats 事件中的统计信息（没有 Stats 事件时为 nil）
+func (s *SelectEventStream) ReadRecords(w io.Writer) (*SelectStats, error) {
+	var stats *SelectStats
+	for s.Next() {
+		e := s.Event()
+		switch e.Type {
+		case SelectEventRecords:
+			if _, err := w.Write(e.Payload); err != nil {
+				return stats, err
+			}
+		case SelectEventStats:
+			stats = e.Stats
+		}
+	}
+	return stats, s.Err()
+}
+
+// readEvent 读取一个消息，消息的格式为：
+//
+//	total length (4) | headers length (4) | prelude crc (4) | headers | payload | message crc (4)
+func (s *SelectEventStream) readEvent() (*SelectEvent, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(s.body, prelude[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, ErrSelectChecksumMismatch
+	}
+	if totalLength > selectMaxMessageLength || uint64(headersLength)+16 > uint64(totalLength) {
+		return nil, fmt.Errorf("cos: invalid select event message length %d (headers %d)", totalLength, headersLength)
+	}
+
+	msg := make([]byte, totalLength)
+	copy(msg, prelude[:])
+	if _, err := io.ReadFull(s.body, msg[12:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	crcOffset := totalLength - 4
+	if crc32.ChecksumIEEE(msg[:crcOffset]) != binary.BigEndian.Uint32(msg[crcOffset:]) {
+		return nil, ErrSelectChecksumMismatch
+	}
+
+	headers, err := parseSelectHeaders(msg[12 : 12+headersLength])
+	if err != nil {
+		return nil, err
+	}
+	payload := msg[12+headersLength : crcOffset]
+
+	switch headers[":message-type"] {
+	case "error":
+		return nil, &ErrorResponse{
+			Response:  s.resp.Response,
+			Code:      headers[":error-code"],
+			Message:   headers[":error-message"],
+			RequestID: s.resp.RequestID(),
+		}
+	case "event":
+	default:
+		return nil, fmt.Errorf("cos: unknown select message type %q", headers[":message-type"])
+	}
+
+	e := &SelectEvent{Type: headers[":event-type"], Payload: payload}
+	if e.Type == SelectEventStats || e.Type == SelectEventProgress {
+		e.Stats = &SelectStats{}
+		if err := xml.Unmarshal(payload, e.Stats); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// parseSelectHeaders 解析消息的头部，头部的格式为：
+//
+//	name length (1) | name | value type (1) | value length (2) | value
+//
+// 目前只有字符串类型（7）的头部
+func parseSelectHeaders(b []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	for len(b) > 0 {
+		n := int(b[0])
+		if len(b) < 1+n+3 {
+			return nil, errors.New("cos: invalid select event header")
+		}
+		name := string(b[1 : 1+n])
+		b = b[1+n:]
+		if b[0] != 7 {
+			return nil, fmt.Errorf("cos: unsupported select event header type %d", b[0])
+		}
+		l := int(binary.BigEndian.Uint16(b[1:3]))
+		b = b[3:]
+		if len(b) < l {
+			return nil, errors.New("cos: invalid select event header")
+		}
+		headers[name] = string(b[:l])
+		b = b[l:]
+	}
+	return headers, nil
+}
+
+// MethodObjectSelect method name of Object.Select
+const MethodObjectSelect MethodName = "Object.Select"
+
+// Select ...
+//
+// Select Object Content 接口可以使用 SQL 语句从 CSV 或者 JSON 格式（可以是 GZIP 或者 BZIP2 压缩的）的对象中
+// 检索部分数据，只返回需要的内容，可以减少传输的数据量。
+//
+// 查询结果以事件流的形式返回，使用返回的 SelectEventStream 读取，读取完后需要调用 Close 。
+//
+// https://cloud.tencent.com/document/product/436/37641
+func (s *ObjectService) Select(ctx context.Context, name string, opt *ObjectSelectOptions) (*SelectEventStream, *Response, error) {
+	sendOpt := sendOptions{
+		baseURL:          s.client.BaseURL.BucketURL,
+		uri:              "/" + encodeURIComponent(name) + "?select&select-type=2",
+		method:           http.MethodPost,
+		body:             opt,
+		disableCloseBody: true,
+		caller: Caller{
+			Method: MethodObjectSelect,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &SelectEventStream{resp: resp, body: resp.Body}, resp, nil
+}
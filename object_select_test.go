@@ -0,0 +1,182 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// selectMessage 按照事件流的格式编码一个消息
+func selectMessage(headers [][2]string, payload string) []byte {
+	var h bytes.Buffer
+	for _, kv := range headers {
+		h.WriteByte(byte(len(kv[0])))
+		h.WriteString(kv[0])
+		h.WriteByte(7)
+		binary.Write(&h, binary.BigEndian, uint16(len(kv[1])))
+		h.WriteString(kv[1])
+	}
+	var m bytes.Buffer
+	binary.Write(&m, binary.BigEndian, uint32(12+h.Len()+len(payload)+4))
+	binary.Write(&m, binary.BigEndian, uint32(h.Len()))
+	binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	m.Write(h.Bytes())
+	m.WriteString(payload)
+	binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	return m.Bytes()
+}
+
+func selectEvent(eventType, payload string) []byte {
+	return selectMessage([][2]string{
+		{":message-type", "event"},
+		{":event-type", eventType},
+	}, payload)
+}
+
+func TestObjectService_Select(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &ObjectSelectOptions{
+		Expression:     "Select s._1 from COSObject s where s._2 > 30",
+		ExpressionType: "SQL",
+		InputSerialization: &SelectInputSerialization{
+			CompressionType: "GZIP",
+			CSV:             &SelectCSVInput{FileHeaderInfo: "NONE"},
+		},
+		OutputSerialization: &SelectOutputSerialization{
+			JSON: &SelectJSONOutput{RecordDelimiter: "\n"},
+		},
+		RequestProgress: &SelectRequestProgress{Enabled: "TRUE"},
+	}
+	stats := `<Stats><BytesScanned>100</BytesScanned><BytesProcessed>200</BytesProcessed><BytesReturned>14</BytesReturned></Stats>`
+
+	mux.HandleFunc("/test.csv.gz", func(w http.ResponseWriter, r *http.Request) {
+		v := new(ObjectSelectOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPost)
+		vs := values{
+			"select":      "",
+			"select-type": "2",
+		}
+		testFormValues(t, r, vs)
+
+		want := opt
+		want.XMLName = xml.Name{Local: "SelectRequest"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Object.Select request body: %+v, want %+v", v, want)
+		}
+
+		w.Write(selectEvent(SelectEventRecords, `{"_1":"a"}`+"\n"))
+		w.Write(selectEvent(SelectEventCont, ""))
+		w.Write(selectEvent(SelectEventProgress, `<Progress><BytesScanned>50</BytesScanned></Progress>`))
+		w.Write(selectEvent(SelectEventRecords, `{"_1":"b"}`+"\n"))
+		w.Write(selectEvent(SelectEventStats, stats))
+		w.Write(selectEvent(SelectEventEnd, ""))
+	})
+
+	stream, _, err := client.Object.Select(context.Background(), "test.csv.gz", opt)
+	if err != nil {
+		t.Fatalf("Object.Select returned error: %v", err)
+	}
+	defer stream.Close()
+
+	var types []string
+	var records bytes.Buffer
+	for stream.Next() {
+		e := stream.Event()
+		types = append(types, e.Type)
+		if e.Type == SelectEventRecords {
+			records.Write(e.Payload)
+		}
+		if e.Type == SelectEventProgress && !reflect.DeepEqual(e.Stats, &SelectStats{BytesScanned: 50}) {
+			t.Errorf("Progress event stats: %+v", e.Stats)
+		}
+		if e.Type == SelectEventStats && string(e.Payload) != stats {
+			t.Errorf("Stats event payload: %s", e.Payload)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("SelectEventStream returned error: %v", err)
+	}
+	want := []string{SelectEventRecords, SelectEventCont, SelectEventProgress, SelectEventRecords, SelectEventStats, SelectEventEnd}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("SelectEventStream returned events %v, want %v", types, want)
+	}
+	if got := records.String(); got != "{\"_1\":\"a\"}\n{\"_1\":\"b\"}\n" {
+		t.Errorf("SelectEventStream returned records %q", got)
+	}
+	if stream.Next() || stream.Event() != nil {
+		t.Error("SelectEventStream.Next should return false after End event")
+	}
+}
+
+func testSelectStream(body []byte) *SelectEventStream {
+	resp := newResponse(&http.Response{Header: http.Header{}, Request: &http.Request{}})
+	return &SelectEventStream{resp: resp, body: ioutil.NopCloser(bytes.NewReader(body))}
+}
+
+func TestSelectEventStream_ReadRecords(t *testing.T) {
+	var body []byte
+	body = append(body, selectEvent(SelectEventRecords, "a,1\n")...)
+	body = append(body, selectEvent(SelectEventRecords, "b,2\n")...)
+	body = append(body, selectEvent(SelectEventStats, `<Stats><BytesReturned>8</BytesReturned></Stats>`)...)
+	body = append(body, selectEvent(SelectEventEnd, "")...)
+
+	var w bytes.Buffer
+	stats, err := testSelectStream(body).ReadRecords(&w)
+	if err != nil {
+		t.Fatalf("SelectEventStream.ReadRecords returned error: %v", err)
+	}
+	if w.String() != "a,1\nb,2\n" || !reflect.DeepEqual(stats, &SelectStats{BytesReturned: 8}) {
+		t.Errorf("SelectEventStream.ReadRecords returned %q, %+v", w.String(), stats)
+	}
+}
+
+func TestSelectEventStream_error(t *testing.T) {
+	records := selectEvent(SelectEventRecords, "a,1\n")
+
+	// 服务端返回的错误事件
+	body := append(append([]byte(nil), records...), selectMessage([][2]string{
+		{":message-type", "error"},
+		{":error-code", "CSVParsingError"},
+		{":error-message", "invalid csv"},
+	}, "")...)
+	s := testSelectStream(body)
+	if !s.Next() || s.Next() {
+		t.Fatal("SelectEventStream.Next should return the Records event and stop at the error event")
+	}
+	if e, ok := s.Err().(*ErrorResponse); !ok || e.Code != "CSVParsingError" || e.Message != "invalid csv" {
+		t.Errorf("SelectEventStream.Err returned %#v", s.Err())
+	}
+
+	// 消息内容被修改
+	corrupted := append([]byte(nil), records...)
+	corrupted[len(corrupted)-6] ^= 0xff
+	s = testSelectStream(corrupted)
+	if s.Next() || s.Err() != ErrSelectChecksumMismatch {
+		t.Errorf("SelectEventStream.Err returned %v, want %v", s.Err(), ErrSelectChecksumMismatch)
+	}
+
+	// 没有 End 事件
+	s = testSelectStream(records)
+	for s.Next() {
+	}
+	if s.Err() != io.ErrUnexpectedEOF {
+		t.Errorf("SelectEventStream.Err returned %v, want %v", s.Err(), io.ErrUnexpectedEOF)
+	}
+
+	// 消息被截断
+	s = testSelectStream(records[:len(records)-2])
+	if s.Next() || s.Err() != io.ErrUnexpectedEOF {
+		t.Errorf("SelectEventStream.Err returned %v, want %v", s.Err(), io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,140 @@
+package cos
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	xCosSSECustomerAlgorithm        = "x-cos-server-side-encryption-customer-algorithm"
+	xCosSSECustomerKey              = "x-cos-server-side-encryption-customer-key"
+	xCosSSECustomerKeyMD5           = "x-cos-server-side-encryption-customer-key-MD5"
+	xCosCopySourceSSECustomerKey    = "x-cos-copy-source-server-side-encryption-customer-key"
+	xCosCopySourceSSECustomerKeyMD5 = "x-cos-copy-source-server-side-encryption-customer-key-MD5"
+	xCosSSEKMSKeyID                 = "x-cos-server-side-encryption-cos-kms-key-id"
+)
+
+// ErrSSECustomerKeyOverHTTP 不允许通过 HTTP 发送用户自定义的服务端加密密钥（SSE-C），需要使用 HTTPS
+var ErrSSECustomerKeyOverHTTP = errors.New("cos: server-side encryption customer key must be sent over HTTPS")
+
+// SSECustomerHeaderOptions ...
+//
+// 使用用户自定义密钥的服务端加密（SSE-C）相关的头部，上传、下载以及查询使用 SSE-C 加密的对象时都需要指定相同的密钥。
+// 可以使用 NewSSECustomerHeaderOptions 创建。
+//
+// https://cloud.tencent.com/document/product/436/7728
+type SSECustomerHeaderOptions struct {
+	// 服务端加密算法，目前仅支持 AES256
+	XCosSSECustomerAlgorithm string `header:"x-cos-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
+	// base64 编码的 32 字节的密钥
+	XCosSSECustomerKey string `header:"x-cos-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
+	// base64 编码的密钥的 MD5 值，为空时会根据 XCosSSECustomerKey 自动计算
+	XCosSSECustomerKeyMD5 string `header:"x-cos-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+}
+
+// NewSSECustomerHeaderOptions 返回使用 key（32 字节）通过 AES256 进行服务端加密的 SSECustomerHeaderOptions
+func NewSSECustomerHeaderOptions(key []byte) *SSECustomerHeaderOptions {
+	return &SSECustomerHeaderOptions{
+		XCosSSECustomerAlgorithm: ServerSideEncryptionAES256,
+		XCosSSECustomerKey:       base64.StdEncoding.EncodeToString(key),
+		XCosSSECustomerKeyMD5:    sseCustomerKeyMD5(key),
+	}
+}
+
+// SSECopySourceHeaderOptions ...
+//
+// 复制使用 SSE-C 加密的源文件时，指定源文件的密钥。可以使用 NewSSECopySourceHeaderOptions 创建。
+//
+// https://cloud.tencent.com/document/product/436/10881
+type SSECopySourceHeaderOptions struct {
+	// 源文件的服务端加密算法，目前仅支持 AES256
+	XCosCopySourceSSECustomerAlgorithm string `header:"x-cos-copy-source-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
+	// base64 编码的源文件的密钥
+	XCosCopySourceSSECustomerKey string `header:"x-cos-copy-source-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
+	// base64 编码的源文件的密钥的 MD5 值，为空时会根据 XCosCopySourceSSECustomerKey 自动计算
+	XCosCopySourceSSECustomerKeyMD5 string `header:"x-cos-copy-source-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+}
+
+// NewSSECopySourceHeaderOptions 返回使用 key（32 字节）解密源文件的 SSECopySourceHeaderOptions
+func NewSSECopySourceHeaderOptions(key []byte) *SSECopySourceHeaderOptions {
+	return &SSECopySourceHeaderOptions{
+		XCosCopySourceSSECustomerAlgorithm: ServerSideEncryptionAES256,
+		XCosCopySourceSSECustomerKey:       base64.StdEncoding.EncodeToString(key),
+		XCosCopySourceSSECustomerKeyMD5:    sseCustomerKeyMD5(key),
+	}
+}
+
+// sourceHeader 返回使用相同密钥访问源文件时的 SSECustomerHeaderOptions
+func (o *SSECopySourceHeaderOptions) sourceHeader() *SSECustomerHeaderOptions {
+	if o == nil {
+		return nil
+	}
+	return &SSECustomerHeaderOptions{
+		XCosSSECustomerAlgorithm: o.XCosCopySourceSSECustomerAlgorithm,
+		XCosSSECustomerKey:       o.XCosCopySourceSSECustomerKey,
+		XCosSSECustomerKeyMD5:    o.XCosCopySourceSSECustomerKeyMD5,
+	}
+}
+
+// EncodeSSEContext 将 KMS 服务端加密的加密上下文编码为 XCosSSEContext 头部的值（base64 编码的 JSON）
+func EncodeSSEContext(encryptionContext map[string]string) (string, error) {
+	b, err := json.Marshal(encryptionContext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func sseCustomerKeyMD5(key []byte) string {
+	sum := md5.Sum(key)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// setSSECustomerKeyMD5 在请求包含 SSE-C 密钥时检查请求是否使用 HTTPS，并自动计算缺失的密钥 MD5 头部
+func setSSECustomerKeyMD5(req *http.Request) error {
+	for _, h := range [][2]string{
+		{xCosSSECustomerKey, xCosSSECustomerKeyMD5},
+		{xCosCopySourceSSECustomerKey, xCosCopySourceSSECustomerKeyMD5},
+	} {
+		v := req.Header.Get(h[0])
+		if v == "" {
+			continue
+		}
+		if req.URL.Scheme != "https" {
+			return ErrSSECustomerKeyOverHTTP
+		}
+		if req.Header.Get(h[1]) != "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return fmt.Errorf("cos: invalid %s: %v", h[0], err)
+		}
+		req.Header.Set(h[1], sseCustomerKeyMD5(key))
+	}
+	return nil
+}
+
+// SSECustomerAlgorithm 对象使用 SSE-C 加密时所使用的加密算法
+func (resp *Response) SSECustomerAlgorithm() string {
+	return resp.Header.Get(xCosSSECustomerAlgorithm)
+}
+
+// SSECustomerKeyMD5 对象使用 SSE-C 加密时所使用的密钥的 MD5 值（base64 编码）
+func (resp *Response) SSECustomerKeyMD5() string {
+	return resp.Header.Get(xCosSSECustomerKeyMD5)
+}
+
+// SSEKMSKeyID 对象使用 KMS 服务端加密时所使用的 KMS 主密钥 ID
+func (resp *Response) SSEKMSKeyID() string {
+	return resp.Header.Get(xCosSSEKMSKeyID)
+}
+
+// encryptedETag 对象是否使用了 SSE-C 或者 KMS 服务端加密，此时 ETag 不是对象内容的 MD5 值
+func (resp *Response) encryptedETag() bool {
+	return resp.SSECustomerAlgorithm() != "" || resp.ServerSideEncryption() == ServerSideEncryptionKMS
+}
@@ -0,0 +1,181 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// setupTLS 同 setup，但是使用 HTTPS 的测试服务器，用于测试 SSE-C
+func setupTLS() {
+	mux = http.NewServeMux()
+	server = httptest.NewTLSServer(mux)
+
+	u, _ := url.Parse(server.URL)
+	client = NewClient(&BaseURL{u, u}, &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	})
+}
+
+var testSSECustomerKey = []byte("0123456789abcdef0123456789abcdef")
+
+const (
+	testSSECustomerKeyBase64 = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	testSSECustomerKeyMD5    = "hRasmdxgYDKV3nvbahU1MA=="
+)
+
+func testSSECustomerHeader(t *testing.T, r *http.Request, prefix string) {
+	testHeader(t, r, prefix+"-algorithm", "AES256")
+	testHeader(t, r, prefix+"-key", testSSECustomerKeyBase64)
+	testHeader(t, r, prefix+"-key-MD5", testSSECustomerKeyMD5)
+}
+
+func TestObjectService_Put_SSECustomer(t *testing.T) {
+	setupTLS()
+	defer teardown()
+
+	mux.HandleFunc("/test.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		testSSECustomerHeader(t, r, "x-cos-server-side-encryption-customer")
+		w.Header().Set("x-cos-server-side-encryption-customer-algorithm", "AES256")
+		w.Header().Set("x-cos-server-side-encryption-customer-key-MD5", testSSECustomerKeyMD5)
+	})
+
+	// 没有指定密钥的 MD5 时自动计算
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			SSECustomerHeaderOptions: &SSECustomerHeaderOptions{
+				XCosSSECustomerAlgorithm: "AES256",
+				XCosSSECustomerKey:       testSSECustomerKeyBase64,
+			},
+		},
+	}
+	resp, err := client.Object.Put(context.Background(), "test.txt", bytes.NewReader([]byte("test")), opt)
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if resp.SSECustomerAlgorithm() != "AES256" || resp.SSECustomerKeyMD5() != testSSECustomerKeyMD5 {
+		t.Errorf("Object.Put returned header %v", resp.Header)
+	}
+}
+
+func TestObjectService_Get_SSECustomer(t *testing.T) {
+	setupTLS()
+	defer teardown()
+
+	mux.HandleFunc("/test.txt", func(w http.ResponseWriter, r *http.Request) {
+		testSSECustomerHeader(t, r, "x-cos-server-side-encryption-customer")
+		testFormValues(t, r, values{"versionId": "1"})
+	})
+
+	key := NewSSECustomerHeaderOptions(testSSECustomerKey)
+	resp, err := client.Object.Get(context.Background(), "test.txt", &ObjectGetOptions{
+		VersionID:                "1",
+		SSECustomerHeaderOptions: key,
+	})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := client.Object.Head(context.Background(), "test.txt", &ObjectHeadOptions{
+		VersionID:                "1",
+		SSECustomerHeaderOptions: key,
+	}); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+}
+
+func TestObjectService_Copy_SSECustomer(t *testing.T) {
+	setupTLS()
+	defer teardown()
+
+	mux.HandleFunc("/test.go.copy", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		testSSECustomerHeader(t, r, "x-cos-copy-source-server-side-encryption-customer")
+		testHeader(t, r, "x-cos-server-side-encryption", "cos/kms")
+		testHeader(t, r, "x-cos-server-side-encryption-cos-kms-key-id", "kms-key")
+		testHeader(t, r, "x-cos-server-side-encryption-context", "eyJhIjoiYiJ9")
+		testHeader(t, r, "x-cos-server-side-encryption-customer-key", "")
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+	})
+
+	sseContext, err := EncodeSSEContext(map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatalf("EncodeSSEContext returned error: %v", err)
+	}
+	sourceURL := client.BaseURL.BucketURL.Host + "/test.src"
+	opt := &ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			SSECopySourceHeaderOptions: NewSSECopySourceHeaderOptions(testSSECustomerKey),
+			XCosServerSideEncryption:   ServerSideEncryptionKMS,
+			XCosSSEKMSKeyID:            "kms-key",
+			XCosSSEContext:             sseContext,
+		},
+	}
+	if _, _, err := client.Object.Copy(context.Background(), "test.go.copy", sourceURL, opt); err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+}
+
+func TestObjectService_multipart_SSECustomer(t *testing.T) {
+	setupTLS()
+	defer teardown()
+
+	mux.HandleFunc("/test.txt", func(w http.ResponseWriter, r *http.Request) {
+		testSSECustomerHeader(t, r, "x-cos-server-side-encryption-customer")
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>id</UploadId></InitiateMultipartUploadResult>`)
+			return
+		}
+		if r.URL.Query().Get("partNumber") == "2" {
+			testSSECustomerHeader(t, r, "x-cos-copy-source-server-side-encryption-customer")
+			fmt.Fprint(w, `<CopyPartResult><ETag>"etag-2"</ETag></CopyPartResult>`)
+		}
+	})
+
+	key := NewSSECustomerHeaderOptions(testSSECustomerKey)
+	ctx := context.Background()
+	if _, _, err := client.Object.InitiateMultipartUpload(ctx, "test.txt", &InitiateMultipartUploadOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{SSECustomerHeaderOptions: key},
+	}); err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+	if _, err := client.Object.UploadPart(ctx, "test.txt", "id", 1, bytes.NewReader([]byte("test")), &ObjectUploadPartOptions{
+		SSECustomerHeaderOptions: key,
+	}); err != nil {
+		t.Fatalf("Object.UploadPart returned error: %v", err)
+	}
+	if _, _, err := client.Object.UploadPartCopy(ctx, "test.txt", "id", 2, client.BaseURL.BucketURL.Host+"/test.src", &ObjectCopyPartOptions{
+		SSECopySourceHeaderOptions: NewSSECopySourceHeaderOptions(testSSECustomerKey),
+		SSECustomerHeaderOptions:   key,
+	}); err != nil {
+		t.Fatalf("Object.UploadPartCopy returned error: %v", err)
+	}
+}
+
+func TestObjectService_SSECustomer_http(t *testing.T) {
+	setup()
+	defer teardown()
+
+	called := false
+	mux.HandleFunc("/test.txt", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	_, err := client.Object.Get(context.Background(), "test.txt", &ObjectGetOptions{
+		SSECustomerHeaderOptions: NewSSECustomerHeaderOptions(testSSECustomerKey),
+	})
+	if err != ErrSSECustomerKeyOverHTTP {
+		t.Errorf("Object.Get returned error %v, want %v", err, ErrSSECustomerKeyOverHTTP)
+	}
+	if called {
+		t.Error("customer key should not be sent over HTTP")
+	}
+}
@@ -0,0 +1,109 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+// ObjectPutTaggingOptions ...
+//
+// https://cloud.tencent.com/document/product/436/42997
+type ObjectPutTaggingOptions struct {
+	XMLName xml.Name           `xml:"Tagging" url:"-"`
+	TagSet  []BucketTaggingTag `xml:"TagSet>Tag,omitempty" url:"-"`
+	// 指定要设置标签的对象的版本 ID
+	VersionID string `xml:"-" url:"versionId,omitempty"`
+}
+
+// MethodObjectPutTagging method name of Object.PutTagging
+const MethodObjectPutTagging MethodName = "Object.PutTagging"
+
+// PutTagging ...
+//
+// Put Object Tagging 接口用于为已存在的对象设置标签，会覆盖对象已有的标签。
+// 每个对象最多可以设置 10 个标签，同一个对象的标签键不能重复。
+//
+// 上传和复制对象时也可以通过 ObjectPutHeaderOptions.XCosTagging 和 ObjectCopyHeaderOptions.XCosTagging 设置标签。
+//
+// https://cloud.tencent.com/document/product/436/42997
+func (s *ObjectService) PutTagging(ctx context.Context, name string, opt *ObjectPutTaggingOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/" + encodeURIComponent(name) + "?tagging",
+		method:   http.MethodPut,
+		optQuery: opt,
+		body:     opt,
+		caller: Caller{
+			Method: MethodObjectPutTagging,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
+
+// ObjectGetTaggingOptions ...
+type ObjectGetTaggingOptions struct {
+	// 指定要查询标签的对象的版本 ID
+	VersionID string `url:"versionId,omitempty"`
+}
+
+// ObjectGetTaggingResult ...
+//
+// https://cloud.tencent.com/document/product/436/42998
+type ObjectGetTaggingResult struct {
+	XMLName xml.Name           `xml:"Tagging"`
+	TagSet  []BucketTaggingTag `xml:"TagSet>Tag,omitempty"`
+}
+
+// MethodObjectGetTagging method name of Object.GetTagging
+const MethodObjectGetTagging MethodName = "Object.GetTagging"
+
+// GetTagging ...
+//
+// Get Object Tagging 接口用于查询指定对象下已有的对象标签。
+//
+// https://cloud.tencent.com/document/product/436/42998
+func (s *ObjectService) GetTagging(ctx context.Context, name string, opt *ObjectGetTaggingOptions) (*ObjectGetTaggingResult, *Response, error) {
+	var res ObjectGetTaggingResult
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/" + encodeURIComponent(name) + "?tagging",
+		method:   http.MethodGet,
+		optQuery: opt,
+		result:   &res,
+		caller: Caller{
+			Method: MethodObjectGetTagging,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return &res, resp, err
+}
+
+// ObjectDeleteTaggingOptions ...
+type ObjectDeleteTaggingOptions struct {
+	// 指定要删除标签的对象的版本 ID
+	VersionID string `url:"versionId,omitempty"`
+}
+
+// MethodObjectDeleteTagging method name of Object.DeleteTagging
+const MethodObjectDeleteTagging MethodName = "Object.DeleteTagging"
+
+// DeleteTagging ...
+//
+// Delete Object Tagging 接口用于删除指定对象下已有的对象标签。
+//
+// https://cloud.tencent.com/document/product/436/42999
+func (s *ObjectService) DeleteTagging(ctx context.Context, name string, opt *ObjectDeleteTaggingOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL:  s.client.BaseURL.BucketURL,
+		uri:      "/" + encodeURIComponent(name) + "?tagging",
+		method:   http.MethodDelete,
+		optQuery: opt,
+		caller: Caller{
+			Method: MethodObjectDeleteTagging,
+		},
+	}
+	resp, err := s.client.send(ctx, &sendOpt)
+	return resp, err
+}
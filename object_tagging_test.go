@@ -0,0 +1,155 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestObjectService_PutTagging(t *testing.T) {
+	setup()
+	defer teardown()
+
+	opt := &ObjectPutTaggingOptions{
+		TagSet: []BucketTaggingTag{
+			{Key: "env", Value: "test"},
+			{Key: "team", Value: "storage"},
+		},
+		VersionID: "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+	}
+	name := "test/hello.txt"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		v := new(ObjectPutTaggingOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+
+		testMethod(t, r, http.MethodPut)
+		vs := values{
+			"tagging":   "",
+			"versionId": "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+		}
+		testFormValues(t, r, vs)
+
+		want := &ObjectPutTaggingOptions{
+			XMLName: xml.Name{Local: "Tagging"},
+			TagSet:  opt.TagSet,
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Object.PutTagging request body: %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Object.PutTagging(context.Background(), name, opt)
+	if err != nil {
+		t.Fatalf("Object.PutTagging returned error: %v", err)
+	}
+}
+
+func TestObjectService_GetTagging(t *testing.T) {
+	setup()
+	defer teardown()
+
+	name := "test/hello.txt"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		vs := values{
+			"tagging": "",
+		}
+		testFormValues(t, r, vs)
+		fmt.Fprint(w, `<Tagging>
+	<TagSet>
+		<Tag>
+			<Key>env</Key>
+			<Value>test</Value>
+		</Tag>
+	</TagSet>
+</Tagging>`)
+	})
+
+	ref, _, err := client.Object.GetTagging(context.Background(), name, nil)
+	if err != nil {
+		t.Fatalf("Object.GetTagging returned error: %v", err)
+	}
+
+	want := &ObjectGetTaggingResult{
+		XMLName: xml.Name{Local: "Tagging"},
+		TagSet:  []BucketTaggingTag{{Key: "env", Value: "test"}},
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("Object.GetTagging returned %+v, want %+v", ref, want)
+	}
+}
+
+func TestObjectService_DeleteTagging(t *testing.T) {
+	setup()
+	defer teardown()
+
+	name := "test/hello.txt"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		vs := values{
+			"tagging":   "",
+			"versionId": "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+		}
+		testFormValues(t, r, vs)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Object.DeleteTagging(context.Background(), name, &ObjectDeleteTaggingOptions{
+		VersionID: "MTg0NDUxNTc1NjIzMTQ1MDAwODg",
+	})
+	if err != nil {
+		t.Fatalf("Object.DeleteTagging returned error: %v", err)
+	}
+}
+
+func TestObjectService_taggingHeader(t *testing.T) {
+	setup()
+	defer teardown()
+
+	tagging := url.Values{"env": {"test"}, "team": {"storage & ops"}}.Encode()
+
+	mux.HandleFunc("/test.txt", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "x-cos-tagging", tagging)
+	})
+	mux.HandleFunc("/test.txt.copy", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "x-cos-tagging", tagging)
+		testHeader(t, r, "x-cos-tagging-directive", "Replaced")
+		fmt.Fprint(w, `<CopyObjectResult></CopyObjectResult>`)
+	})
+	mux.HandleFunc("/test.bin", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "x-cos-tagging", tagging)
+		fmt.Fprint(w, `<InitiateMultipartUploadResult></InitiateMultipartUploadResult>`)
+	})
+
+	ctx := context.Background()
+	putHeader := &ObjectPutHeaderOptions{XCosTagging: tagging}
+	_, err := client.Object.Put(ctx, "test.txt", strings.NewReader("test"), &ObjectPutOptions{
+		ObjectPutHeaderOptions: putHeader,
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	_, _, err = client.Object.Copy(ctx, "test.txt.copy", "test-1253846586.cn-north.myqcloud.com/test.txt", &ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			XCosTaggingDirective: "Replaced",
+			XCosTagging:          tagging,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+	_, _, err = client.Object.InitiateMultipartUpload(ctx, "test.bin", &InitiateMultipartUploadOptions{
+		ObjectPutHeaderOptions: putHeader,
+	})
+	if err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+}
@@ -218,6 +218,68 @@ func TestObjectService_Delete(t *testing.T) {
 	}
 }
 
+func TestObjectService_versionID(t *testing.T) {
+	setup()
+	defer teardown()
+	name := "test/hello.txt"
+	versionID := "MTg0NDUxNTc1NjIzMTQ1MDAwODg"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"versionId": versionID})
+		w.Header().Set("x-cos-version-id", versionID)
+	})
+	ctx := context.Background()
+
+	resp, err := client.Object.Get(ctx, name, &ObjectGetOptions{VersionID: versionID})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.VersionID() != versionID {
+		t.Errorf("Object.Get returned version %s, want %s", resp.VersionID(), versionID)
+	}
+	if _, err := client.Object.Head(ctx, name, &ObjectHeadOptions{VersionID: versionID}); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if _, err := client.Object.DeleteWithOpt(ctx, name, &ObjectDeleteOptions{VersionID: versionID}); err != nil {
+		t.Fatalf("Object.DeleteWithOpt returned error: %v", err)
+	}
+}
+
+func TestObjectService_DeleteMulti_versionID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		b, _ := ioutil.ReadAll(r.Body)
+		want := `<Delete><Quiet>false</Quiet><Object><Key>test1</Key><VersionId>v1</VersionId></Object></Delete>`
+		if string(b) != want {
+			t.Errorf("Object.DeleteMulti request body %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `<DeleteResult>
+	<Deleted>
+		<Key>test1</Key>
+		<VersionId>v1</VersionId>
+		<DeleteMarker>true</DeleteMarker>
+		<DeleteMarkerVersionId>v1</DeleteMarkerVersionId>
+	</Deleted>
+</DeleteResult>`)
+	})
+
+	opt := &ObjectDeleteMultiOptions{
+		Objects: []Object{{Key: "test1", VersionID: "v1"}},
+	}
+	ref, _, err := client.Object.DeleteMulti(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Object.DeleteMulti returned error: %v", err)
+	}
+	want := []Object{{Key: "test1", VersionID: "v1", DeleteMarker: true, DeleteMarkerVersionID: "v1"}}
+	if !reflect.DeepEqual(ref.DeletedObjects, want) {
+		t.Errorf("Object.DeleteMulti returned %+v, want %+v", ref.DeletedObjects, want)
+	}
+}
+
 func TestObjectService_Head(t *testing.T) {
 	setup()
 	defer teardown()
@@ -0,0 +1,511 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mozillazg/go-cos/internal/parallel"
+)
+
+const (
+	// 默认分块大小: 8 MB
+	defaultUploadPartSize int64 = 8 * 1024 * 1024
+	// 最小分块大小（最后一个分块除外）: 1 MB
+	minUploadPartSize int64 = 1024 * 1024
+	// 单个分块上传最多支持 10000 个分块
+	maxUploadParts = 10000
+	// 默认并发上传分块的数量
+	defaultUploadConcurrency = 3
+)
+
+// ObjectUploadOptions ...
+//
+// Object.Upload 的参数
+type ObjectUploadOptions struct {
+	*ACLHeaderOptions       `header:",omitempty" url:"-" xml:"-"`
+	*ObjectPutHeaderOptions `header:",omitempty" url:"-" xml:"-"`
+
+	// 分块大小，单位是 Byte。默认值：8 MB，最小值：1 MB。
+	// 当数据大小已知且按照 PartSize 切分后的分块数量超过 10000 时，会自动调大分块大小
+	PartSize int64 `header:"-" url:"-" xml:"-"`
+	// 同时上传分块的数量，默认值：3
+	Concurrency int `header:"-" url:"-" xml:"-"`
+	// 数据大小小于等于 Threshold 时使用简单上传（Object.Put），否则使用分块上传。默认值：PartSize
+	Threshold int64 `header:"-" url:"-" xml:"-"`
+	// 启用断点续传并将上传进度保存在 CheckpointFile 文件中，只在 r 同时实现了 io.ReaderAt 和 io.Seeker 时有效。
+	// 上传中断后使用相同的参数再次调用 Upload 时只会上传缺失的分块，上传成功后会删除该文件
+	CheckpointFile string `header:"-" url:"-" xml:"-"`
+}
+
+// ObjectUploadResult ...
+//
+// Object.Upload 的结果
+type ObjectUploadResult struct {
+	// Object 的名称
+	Key string
+	// 上传后 Object 的 ETag
+	ETag string
+	// 分块上传时使用的 UploadID，简单上传时为空
+	UploadID string
+	// 分块上传时各个分块的编号和 ETag，简单上传时为空
+	Parts []Object
+}
+
+// Upload 上传文件的便捷方法，会根据数据大小自动选择简单上传（Object.Put）或并发分块上传。
+//
+// 数据大小小于等于 opt.Threshold 时通过 Object.Put 上传，否则依次调用
+// InitiateMultipartUpload、UploadPart 和 CompleteMultipartUpload 完成分块上传，
+// 最多同时上传 opt.Concurrency 个分块。分块上传出错时会调用 AbortMultipartUpload 舍弃已上传的分块。
+//
+// 当 r 同时实现了 io.ReaderAt 和 io.Seeker 时（比如 *os.File），各个分块会直接从 r 中并发读取，
+// 否则会按顺序从 r 中读取数据并按分块缓存在内存中，此时最多占用 (opt.Concurrency + 1) * opt.PartSize 的内存；
+// opt.Threshold 大于 opt.Concurrency * opt.PartSize 时，开始分块上传前会先缓存 opt.Threshold 字节的数据。
+//
+// 指定 opt.CheckpointFile 时启用断点续传：上传进度会保存在该文件中，上传中断后再次调用 Upload 时
+// 会通过 ListPartsWithOpt 与服务端核对已上传的分块，只上传缺失的分块。数据源的大小或修改时间发生变化时
+// 会舍弃原有的分块上传重新开始。
+//
+// 当 r 是个 io.ReadCloser 时 Upload 方法不会自动调用 r.Close()，用户需要自行选择合适的时机去调用 r.Close() 方法对 r 进行资源回收
+func (s *ObjectService) Upload(ctx context.Context, name string, r io.Reader, opt *ObjectUploadOptions) (*ObjectUploadResult, *Response, error) {
+	u := newUploader(s, name, opt)
+	return u.upload(ctx, r)
+}
+
+type uploader struct {
+	s           *ObjectService
+	name        string
+	opt         *ObjectUploadOptions
+	partSize    int64
+	concurrency int
+	threshold   int64
+	pool        *bufferPool
+	// 数据源的修改时间，用于判断断点续传时数据源是否发生了变化
+	modTime time.Time
+}
+
+func newUploader(s *ObjectService, name string, opt *ObjectUploadOptions) *uploader {
+	if opt == nil {
+		opt = &ObjectUploadOptions{}
+	}
+	u := &uploader{
+		s:           s,
+		name:        name,
+		opt:         opt,
+		partSize:    opt.PartSize,
+		concurrency: opt.Concurrency,
+		threshold:   opt.Threshold,
+	}
+	if u.partSize <= 0 {
+		u.partSize = defaultUploadPartSize
+	}
+	if u.partSize < minUploadPartSize {
+		u.partSize = minUploadPartSize
+	}
+	if u.concurrency <= 0 {
+		u.concurrency = defaultUploadConcurrency
+	}
+	if u.threshold <= 0 {
+		u.threshold = u.partSize
+	}
+	return u
+}
+
+// uploadChunk 待上传的一个分块
+type uploadChunk struct {
+	number int
+	size   int64
+	body   io.ReadSeeker
+	// 需要放回 bufferPool 的 buffer
+	buf *[]byte
+}
+
+func (u *uploader) upload(ctx context.Context, r io.Reader) (*ObjectUploadResult, *Response, error) {
+	if f, ok := r.(interface {
+		Stat() (os.FileInfo, error)
+	}); ok {
+		if fi, err := f.Stat(); err == nil {
+			u.modTime = fi.ModTime()
+		}
+	}
+	if ra, ok := r.(io.ReaderAt); ok {
+		if offset, size, err := readerOffsetSize(r); err == nil {
+			return u.uploadReaderAt(ctx, ra, offset, size)
+		}
+	}
+	return u.uploadReader(ctx, r)
+}
+
+// uploadReaderAt 数据大小已知，各个分块直接从 r 中并发读取
+func (u *uploader) uploadReaderAt(ctx context.Context, r io.ReaderAt, offset, size int64) (*ObjectUploadResult, *Response, error) {
+	if size <= u.threshold {
+		return u.put(ctx, io.NewSectionReader(r, offset, size), size)
+	}
+
+	// 保证分块数量不超过 10000
+	if (size+u.partSize-1)/u.partSize > maxUploadParts {
+		u.partSize = (size + maxUploadParts - 1) / maxUploadParts
+	}
+	if u.opt.CheckpointFile != "" {
+		return u.uploadResumable(ctx, r, offset, size)
+	}
+	return u.uploadMultipart(ctx, u.sectionChunks(r, offset, size, nil))
+}
+
+// sectionChunks 按照 u.partSize 将 r 中从 offset 开始的 size 字节切分为多个分块，跳过 skip 中的分块
+func (u *uploader) sectionChunks(r io.ReaderAt, offset, size int64, skip map[int]bool) func() (*uploadChunk, error) {
+	total := int((size + u.partSize - 1) / u.partSize)
+	number := 0
+	return func() (*uploadChunk, error) {
+		for number < total {
+			number++
+			if skip[number] {
+				continue
+			}
+			start := int64(number-1) * u.partSize
+			n := u.partSize
+			if start+n > size {
+				n = size - start
+			}
+			return &uploadChunk{
+				number: number,
+				size:   n,
+				body:   io.NewSectionReader(r, offset+start, n),
+			}, nil
+		}
+		return nil, io.EOF
+	}
+}
+
+// uploadReader 数据大小未知，按顺序读取 r 中的数据
+func (u *uploader) uploadReader(ctx context.Context, r io.Reader) (*ObjectUploadResult, *Response, error) {
+	u.pool = newBufferPool(int(u.partSize))
+	number := 0
+	newChunk := func(buf *[]byte, n int) (*uploadChunk, error) {
+		if number >= maxUploadParts {
+			u.pool.put(buf)
+			return nil, fmt.Errorf("cos: data is too large, exceeds %d parts of %d bytes", maxUploadParts, u.partSize)
+		}
+		number++
+		return &uploadChunk{
+			number: number,
+			size:   int64(n),
+			body:   bytes.NewReader((*buf)[:n]),
+			buf:    buf,
+		}, nil
+	}
+	// fill 继续读取数据直到填满 buf，buf 中已经有 n 字节的数据
+	fill := func(buf *[]byte, n int) (*uploadChunk, error) {
+		m, err := io.ReadFull(r, (*buf)[n:])
+		n += m
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if n == 0 {
+				u.pool.put(buf)
+				return nil, io.EOF
+			}
+			err = nil
+		}
+		if err != nil {
+			u.pool.put(buf)
+			return nil, err
+		}
+		return newChunk(buf, n)
+	}
+
+	// 先读取 threshold+1 字节的数据用于判断是否需要分块上传，读取到的数据会作为最先上传的分块
+	var head []*uploadChunk
+	var size int64
+	var partial *[]byte
+	var partialSize int
+	for partial == nil {
+		buf := u.pool.get()
+		want := u.threshold + 1 - size
+		if want > u.partSize {
+			want = u.partSize
+		}
+		n, err := io.ReadFull(r, (*buf)[:want])
+		size += int64(n)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// 数据大小不超过 threshold，使用简单上传
+			if n == 0 {
+				u.pool.put(buf)
+				return u.putChunks(ctx, head, size)
+			}
+			c, err := newChunk(buf, n)
+			if err != nil {
+				u.releaseChunks(head)
+				return nil, nil, err
+			}
+			return u.putChunks(ctx, append(head, c), size)
+		}
+		if err != nil {
+			u.pool.put(buf)
+			u.releaseChunks(head)
+			return nil, nil, err
+		}
+		if size <= u.threshold {
+			c, err := newChunk(buf, n)
+			if err != nil {
+				u.releaseChunks(head)
+				return nil, nil, err
+			}
+			head = append(head, c)
+			continue
+		}
+		// 超过了 threshold，buf 中剩余的部分在上传时再读取
+		partial, partialSize = buf, n
+	}
+
+	next := func() (*uploadChunk, error) {
+		if len(head) > 0 {
+			c := head[0]
+			head = head[1:]
+			return c, nil
+		}
+		if partial != nil {
+			buf := partial
+			partial = nil
+			return fill(buf, partialSize)
+		}
+		return fill(u.pool.get(), 0)
+	}
+	return u.uploadMultipart(ctx, next)
+}
+
+// putChunks 通过简单上传上传 chunks 中的数据，上传完成后把 buffer 放回 bufferPool
+func (u *uploader) putChunks(ctx context.Context, chunks []*uploadChunk, size int64) (*ObjectUploadResult, *Response, error) {
+	defer u.releaseChunks(chunks)
+	var body io.Reader
+	switch len(chunks) {
+	case 0:
+		body = bytes.NewReader(nil)
+	case 1:
+		body = chunks[0].body
+	default:
+		data := make([]byte, 0, size)
+		for _, c := range chunks {
+			data = append(data, (*c.buf)[:c.size]...)
+		}
+		body = bytes.NewReader(data)
+	}
+	return u.put(ctx, body, size)
+}
+
+func (u *uploader) releaseChunks(chunks []*uploadChunk) {
+	for _, c := range chunks {
+		u.pool.put(c.buf)
+	}
+}
+
+func (u *uploader) put(ctx context.Context, r io.Reader, size int64) (*ObjectUploadResult, *Response, error) {
+	header := u.putHeaderOptions()
+	header.ContentLength = int(size)
+	opt := &ObjectPutOptions{
+		ACLHeaderOptions:       u.opt.ACLHeaderOptions,
+		ObjectPutHeaderOptions: header,
+	}
+	resp, err := u.s.Put(ctx, u.name, r, opt)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &ObjectUploadResult{
+		Key:  u.name,
+		ETag: resp.Header.Get("ETag"),
+	}, resp, nil
+}
+
+// putHeaderOptions 返回 opt.ObjectPutHeaderOptions 的副本
+func (u *uploader) putHeaderOptions() *ObjectPutHeaderOptions {
+	header := &ObjectPutHeaderOptions{}
+	if u.opt.ObjectPutHeaderOptions != nil {
+		*header = *u.opt.ObjectPutHeaderOptions
+	}
+	return header
+}
+
+func (u *uploader) initiate(ctx context.Context) (string, error) {
+	header := u.putHeaderOptions()
+	// 初始化分块上传的请求不包含 body
+	header.ContentLength = 0
+	opt := &InitiateMultipartUploadOptions{
+		ACLHeaderOptions:       u.opt.ACLHeaderOptions,
+		ObjectPutHeaderOptions: header,
+	}
+	res, _, err := u.s.InitiateMultipartUpload(ctx, u.name, opt)
+	if err != nil {
+		return "", err
+	}
+	return res.UploadID, nil
+}
+
+func (u *uploader) uploadMultipart(ctx context.Context, next func() (*uploadChunk, error)) (*ObjectUploadResult, *Response, error) {
+	uploadID, err := u.initiate(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts, err := u.uploadChunks(ctx, uploadID, next, nil)
+	if err != nil {
+		u.abort(uploadID)
+		return nil, nil, err
+	}
+	result, resp, err := u.complete(ctx, uploadID, parts)
+	if err != nil {
+		u.abort(uploadID)
+	}
+	return result, resp, err
+}
+
+func (u *uploader) complete(ctx context.Context, uploadID string, parts []Object) (*ObjectUploadResult, *Response, error) {
+	sort.Sort(objectsByPartNumber(parts))
+	opt := &CompleteMultipartUploadOptions{}
+	for _, p := range parts {
+		opt.Parts = append(opt.Parts, Object{
+			PartNumber: p.PartNumber,
+			ETag:       p.ETag,
+		})
+	}
+	res, resp, err := u.s.CompleteMultipartUpload(ctx, u.name, uploadID, opt)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &ObjectUploadResult{
+		Key:      u.name,
+		ETag:     res.ETag,
+		UploadID: uploadID,
+		Parts:    opt.Parts,
+	}, resp, nil
+}
+
+// abort 舍弃分块上传。使用新的 context，避免因为 ctx 已被取消而无法舍弃
+func (u *uploader) abort(uploadID string) {
+	u.s.AbortMultipartUpload(context.Background(), u.name, uploadID)
+}
+
+// uploadChunks 使用 u.concurrency 个 goroutine 并发上传 next 返回的分块，直到 next 返回 io.EOF。
+// next 和 done 不会被并发调用，每个分块上传成功后都会调用一次 done（如果 done 不为 nil）。
+func (u *uploader) uploadChunks(ctx context.Context, uploadID string, next func() (*uploadChunk, error), done func(part Object, md5sum []byte)) ([]Object, error) {
+	var (
+		mu     sync.Mutex
+		parts  []Object
+		nextMu sync.Mutex
+	)
+	err := parallel.Run(ctx, -1, u.concurrency, func(ctx context.Context, _ int) error {
+		// 按顺序读取分块
+		nextMu.Lock()
+		c, err := next()
+		nextMu.Unlock()
+		if err != nil {
+			return err
+		}
+		etag, sum, err := u.uploadChunk(ctx, uploadID, c)
+		if c.buf != nil {
+			u.pool.put(c.buf)
+		}
+		if err != nil {
+			return err
+		}
+		part := Object{PartNumber: c.number, ETag: etag, Size: int(c.size)}
+		mu.Lock()
+		parts = append(parts, part)
+		if done != nil {
+			done(part, sum)
+		}
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+func (u *uploader) uploadChunk(ctx context.Context, uploadID string, c *uploadChunk) (string, []byte, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, c.body); err != nil {
+		return "", nil, err
+	}
+	if _, err := c.body.Seek(0, io.SeekStart); err != nil {
+		return "", nil, err
+	}
+	sum := h.Sum(nil)
+	opt := &ObjectUploadPartOptions{
+		ContentLength: int(c.size),
+		ContentMD5:    base64.StdEncoding.EncodeToString(sum),
+	}
+	if u.opt.ObjectPutHeaderOptions != nil {
+		opt.SSECustomerHeaderOptions = u.opt.SSECustomerHeaderOptions
+	}
+	resp, err := u.s.UploadPart(ctx, u.name, uploadID, c.number, c.body, opt)
+	if err != nil {
+		return "", nil, err
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", nil, errors.New("cos: missing ETag in the response of UploadPart")
+	}
+	// 非加密的分块 ETag 为分块内容的 MD5 值，用于校验上传的内容
+	if v := strings.Trim(etag, `"`); len(v) == md5.Size*2 && !resp.encryptedETag() && !strings.EqualFold(v, fmt.Sprintf("%x", sum)) {
+		return "", nil, fmt.Errorf("cos: ETag of part %d is %s, want %x", c.number, etag, sum)
+	}
+	return etag, sum, nil
+}
+
+// readerOffsetSize 通过 io.Seeker 获取 r 当前的读取位置以及剩余数据的大小
+func readerOffsetSize(r io.Reader) (offset, size int64, err error) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return 0, 0, errors.New("cos: reader is not an io.Seeker")
+	}
+	offset, err = seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	if _, err = seeker.Seek(offset, io.SeekStart); err != nil {
+		return
+	}
+	return offset, end - offset, nil
+}
+
+type objectsByPartNumber []Object
+
+func (o objectsByPartNumber) Len() int           { return len(o) }
+func (o objectsByPartNumber) Less(i, j int) bool { return o[i].PartNumber < o[j].PartNumber }
+func (o objectsByPartNumber) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+
+// bufferPool 可重复使用的固定大小的 buffer 池
+type bufferPool struct {
+	pool sync.Pool
+}
+
+func newBufferPool(size int) *bufferPool {
+	return &bufferPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				b := make([]byte, size)
+				return &b
+			},
+		},
+	}
+}
+
+func (p *bufferPool) get() *[]byte {
+	return p.pool.Get().(*[]byte)
+}
+
+func (p *bufferPool) put(b *[]byte) {
+	p.pool.Put(b)
+}
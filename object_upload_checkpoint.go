@@ -0,0 +1,202 @@
+package cos
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 断点续传文件的格式版本，格式发生不兼容的变更时需要增加该值
+const uploadCheckpointVersion = 1
+
+// uploadCheckpoint 断点续传时保存的上传进度
+type uploadCheckpoint struct {
+	Version int `json:"version"`
+	// Object 的名称
+	Key string `json:"key"`
+	// 分块上传的 UploadID
+	UploadID string `json:"upload_id"`
+	// 数据源的大小和修改时间（UnixNano），用于判断数据源是否发生了变化
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mod_time"`
+	// 分块大小
+	PartSize int64 `json:"part_size"`
+	// 已上传成功的分块
+	Parts []checkpointPart `json:"parts"`
+}
+
+type checkpointPart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+	// 分块内容的 MD5 值（hex 编码）
+	MD5 string `json:"md5"`
+}
+
+// loadUploadCheckpoint 读取断点续传文件，文件不存在或内容无效时返回 nil
+func loadUploadCheckpoint(path string) *uploadCheckpoint {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	cp := new(uploadCheckpoint)
+	if err := json.Unmarshal(b, cp); err != nil || cp.Version != uploadCheckpointVersion {
+		return nil
+	}
+	return cp
+}
+
+func (cp *uploadCheckpoint) save(path string) error {
+	return writeCheckpointFile(path, cp)
+}
+
+// writeCheckpointFile 将 v 以 JSON 格式写入断点续传文件 path。
+// 先写入临时文件再重命名，避免程序中断时留下不完整的文件
+func writeCheckpointFile(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// uploadResumable 支持断点续传的分块上传
+func (u *uploader) uploadResumable(ctx context.Context, r io.ReaderAt, offset, size int64) (*ObjectUploadResult, *Response, error) {
+	path := u.opt.CheckpointFile
+	cp, err := u.resumeCheckpoint(ctx, loadUploadCheckpoint(path), size)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cp == nil {
+		uploadID, err := u.initiate(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		cp = &uploadCheckpoint{
+			Version:  uploadCheckpointVersion,
+			Key:      u.name,
+			UploadID: uploadID,
+			Size:     size,
+			ModTime:  u.modTime.UnixNano(),
+			PartSize: u.partSize,
+		}
+	}
+	if err := cp.save(path); err != nil {
+		return nil, nil, err
+	}
+
+	var parts []Object
+	skip := map[int]bool{}
+	for _, p := range cp.Parts {
+		skip[p.PartNumber] = true
+		parts = append(parts, Object{PartNumber: p.PartNumber, ETag: p.ETag, Size: int(p.Size)})
+	}
+
+	var saveErr error
+	done := func(part Object, md5sum []byte) {
+		cp.Parts = append(cp.Parts, checkpointPart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+			Size:       int64(part.Size),
+			MD5:        hex.EncodeToString(md5sum),
+		})
+		if err := cp.save(path); err != nil && saveErr == nil {
+			saveErr = err
+		}
+	}
+	uploaded, err := u.uploadChunks(ctx, cp.UploadID, u.sectionChunks(r, offset, size, skip), done)
+	if err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		// 保留已上传的分块和断点续传文件，以便下次继续上传
+		return nil, nil, err
+	}
+
+	result, resp, err := u.complete(ctx, cp.UploadID, append(parts, uploaded...))
+	if err != nil {
+		return nil, resp, err
+	}
+	os.Remove(path)
+	return result, resp, nil
+}
+
+// resumeCheckpoint 检查断点续传文件是否仍然有效，并通过 ListPartsWithOpt 与服务端已上传的分块进行核对。
+// 数据源或参数发生了变化时会舍弃原有的分块上传，UploadID 已不存在时返回 nil，需要重新开始上传。
+// 其他的错误（比如网络错误、5xx 错误）会直接返回，保留断点续传文件以便下次继续上传
+func (u *uploader) resumeCheckpoint(ctx context.Context, cp *uploadCheckpoint, size int64) (*uploadCheckpoint, error) {
+	if cp == nil {
+		return nil, nil
+	}
+	if cp.Key != u.name || cp.UploadID == "" || cp.Size != size ||
+		cp.ModTime != u.modTime.UnixNano() || cp.PartSize != u.partSize {
+		// 舍弃原有的分块上传，避免已上传的分块一直占用存储空间
+		if cp.UploadID != "" && cp.Key != "" {
+			u.s.AbortMultipartUpload(context.Background(), cp.Key, cp.UploadID)
+		}
+		return nil, nil
+	}
+
+	uploaded, err := u.listParts(ctx, cp.UploadID)
+	if err != nil {
+		// UploadID 已被舍弃、已完成或已过期，重新开始上传
+		if isNoSuchUpload(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var parts []checkpointPart
+	for _, p := range cp.Parts {
+		if o, ok := uploaded[p.PartNumber]; ok && int64(o.Size) == p.Size &&
+			strings.EqualFold(strings.Trim(o.ETag, `"`), strings.Trim(p.ETag, `"`)) {
+			parts = append(parts, p)
+		}
+	}
+	cp.Parts = parts
+	return cp, nil
+}
+
+// isNoSuchUpload 判断 err 是否是 UploadID 不存在时返回的 404 NoSuchUpload 错误
+func isNoSuchUpload(err error) bool {
+	e, ok := err.(*ErrorResponse)
+	return ok && e.Response != nil && e.Response.StatusCode == http.StatusNotFound && e.Code == "NoSuchUpload"
+}
+
+// listParts 列出 uploadID 下所有已上传的分块
+func (u *uploader) listParts(ctx context.Context, uploadID string) (map[int]Object, error) {
+	parts := map[int]Object{}
+	opt := &ObjectListPartsOptions{MaxParts: 1000}
+	for {
+		res, _, err := u.s.ListPartsWithOpt(ctx, u.name, uploadID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range res.Parts {
+			parts[p.PartNumber] = p
+		}
+		if !res.IsTruncated || res.NextPartNumberMarker <= opt.PartNumberMarker {
+			break
+		}
+		opt.PartNumberMarker = res.NextPartNumberMarker
+	}
+	return parts, nil
+}
@@ -0,0 +1,159 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testCheckpointFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cos-checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "upload.cp"), func() { os.RemoveAll(dir) }
+}
+
+func TestObjectService_Upload_resume(t *testing.T) {
+	setup()
+	defer teardown()
+	path, cleanup := testCheckpointFile(t)
+	defer cleanup()
+
+	fs := newFakeServer(t)
+	data := testUploadData(int(minUploadPartSize)*3 + 10)
+	opt := &ObjectUploadOptions{
+		PartSize:       minUploadPartSize,
+		Concurrency:    1,
+		CheckpointFile: path,
+	}
+
+	// 第一次上传时第 3 个分块上传失败
+	fs.failPart(3)
+	_, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err == nil {
+		t.Fatal("Object.Upload should return error")
+	}
+	if fs.count("AbortMultipartUpload") != 0 {
+		t.Error("Object.Upload should not abort the multipart upload when checkpoint is enabled")
+	}
+	cp := loadUploadCheckpoint(path)
+	if cp == nil || cp.UploadID != "upload-id" || len(cp.Parts) != 2 {
+		t.Fatalf("checkpoint is %+v, want 2 parts of upload-id", cp)
+	}
+	if cp.Parts[0].MD5 == "" || cp.PartSize != minUploadPartSize || cp.Size != int64(len(data)) {
+		t.Errorf("checkpoint is %+v", cp)
+	}
+
+	// 服务端丢失了第 2 个分块，继续上传时需要重新上传第 2 个分块
+	delete(fs.parts("upload-id"), 2)
+	fs.fail = nil
+	res, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if n := fs.count("InitiateMultipartUpload"); n != 1 {
+		t.Errorf("Object.Upload initiated %d multipart uploads, want 1", n)
+	}
+	if res.UploadID != "upload-id" || len(res.Parts) != 4 || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("Object.Upload returned %+v, object size %d", res, len(fs.object("hello.txt")))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint file should be removed, got %v", err)
+	}
+}
+
+func TestObjectService_Upload_resume_source_changed(t *testing.T) {
+	setup()
+	defer teardown()
+	path, cleanup := testCheckpointFile(t)
+	defer cleanup()
+
+	fs := newFakeServer(t)
+	data := testUploadData(int(minUploadPartSize)*2 + 10)
+	old := &uploadCheckpoint{
+		Version:  uploadCheckpointVersion,
+		Key:      "hello.txt",
+		UploadID: "old-upload-id",
+		Size:     int64(len(data)) + 1,
+		PartSize: minUploadPartSize,
+		Parts:    []checkpointPart{{PartNumber: 1, ETag: `"xxx"`, Size: minUploadPartSize}},
+	}
+	if err := old.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := &ObjectUploadOptions{
+		PartSize:       minUploadPartSize,
+		CheckpointFile: path,
+	}
+	res, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if aborted, initiated := fs.count("AbortMultipartUpload"), fs.count("InitiateMultipartUpload"); aborted != 1 || initiated != 1 {
+		t.Errorf("Object.Upload should abort the old upload and restart, aborted: %d, initiated: %d",
+			aborted, initiated)
+	}
+	if res.UploadID != "upload-id" || fs.count("UploadPart") != 3 || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("Object.Upload returned %+v, object size %d", res, len(fs.object("hello.txt")))
+	}
+}
+
+func TestObjectService_Upload_resume_list_parts_error(t *testing.T) {
+	setup()
+	defer teardown()
+	path, cleanup := testCheckpointFile(t)
+	defer cleanup()
+
+	fs := newFakeServer(t)
+	data := testUploadData(int(minUploadPartSize)*2 + 10)
+	old := &uploadCheckpoint{
+		Version:  uploadCheckpointVersion,
+		Key:      "hello.txt",
+		UploadID: "old-upload-id",
+		Size:     int64(len(data)),
+		ModTime:  time.Time{}.UnixNano(),
+		PartSize: minUploadPartSize,
+	}
+	if err := old.save(path); err != nil {
+		t.Fatal(err)
+	}
+	opt := &ObjectUploadOptions{
+		PartSize:       minUploadPartSize,
+		CheckpointFile: path,
+	}
+
+	// 其他错误时保留断点续传文件，不重新开始上传
+	fs.fail = func(op string, r *http.Request) *ErrorResponse {
+		if op == "ListParts" {
+			return fakeError(http.StatusForbidden, "AccessDenied")
+		}
+		return nil
+	}
+	_, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if e, ok := err.(*ErrorResponse); !ok || e.Code != "AccessDenied" {
+		t.Fatalf("Object.Upload returned error %v, want AccessDenied", err)
+	}
+	if aborted, initiated := fs.count("AbortMultipartUpload"), fs.count("InitiateMultipartUpload"); aborted != 0 || initiated != 0 {
+		t.Errorf("Object.Upload should keep the old upload, aborted: %d, initiated: %d", aborted, initiated)
+	}
+	if cp := loadUploadCheckpoint(path); cp == nil || cp.UploadID != "old-upload-id" {
+		t.Errorf("checkpoint is %+v, want old-upload-id", cp)
+	}
+
+	// UploadID 不存在时重新开始上传
+	fs.fail = nil
+	res, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if n := fs.count("InitiateMultipartUpload"); n != 1 || res.UploadID != "upload-id" || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("Object.Upload returned %+v, initiated: %d", res, n)
+	}
+}
@@ -0,0 +1,143 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func testUploadData(size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestObjectService_Upload_put(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	data := []byte("hello")
+
+	opt := &ObjectUploadOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType: "text/plain",
+		},
+	}
+	res, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if fs.count("PutObject") != 1 || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("Object.Upload should use Object.Put, got %d puts, body %q", fs.count("PutObject"), fs.object("hello.txt"))
+	}
+	if res.ETag != fakeETag(data) || res.UploadID != "" {
+		t.Errorf("Object.Upload returned %+v", res)
+	}
+}
+
+func TestObjectService_Upload_multipart_readerAt(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	data := testUploadData(int(minUploadPartSize)*2 + 10)
+
+	opt := &ObjectUploadOptions{
+		PartSize:    minUploadPartSize,
+		Concurrency: 2,
+	}
+	res, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if fs.count("PutObject") != 0 || fs.count("UploadPart") != 3 || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("Object.Upload uploaded %d parts, object size %d, want 3 parts, size %d",
+			fs.count("UploadPart"), len(fs.object("hello.txt")), len(data))
+	}
+	if res.UploadID != "upload-id" || res.ETag != fakeMultipartETag(data, 3) || len(res.Parts) != 3 {
+		t.Errorf("Object.Upload returned %+v", res)
+	}
+}
+
+func TestObjectService_Upload_multipart_reader(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	data := testUploadData(int(minUploadPartSize)*3 + 10)
+
+	opt := &ObjectUploadOptions{
+		PartSize:    minUploadPartSize,
+		Concurrency: 2,
+	}
+	// 隐藏 bytes.Reader 的 io.ReaderAt 和 io.Seeker 方法
+	r := struct{ io.Reader }{bytes.NewReader(data)}
+	_, _, err := client.Object.Upload(context.Background(), "hello.txt", r, opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	if fs.count("PutObject") != 0 || fs.count("UploadPart") != 4 || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("Object.Upload uploaded %d parts, object size %d, want 4 parts, size %d",
+			fs.count("UploadPart"), len(fs.object("hello.txt")), len(data))
+	}
+}
+
+func TestObjectService_Upload_reader_threshold(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	// Threshold 大于 PartSize，前面读取的分块会用于简单上传或者作为最先上传的分块
+	opt := &ObjectUploadOptions{
+		PartSize:  minUploadPartSize,
+		Threshold: minUploadPartSize*2 + 10,
+	}
+	for _, tt := range []struct {
+		size, puts, parts int
+	}{
+		{0, 1, 0},
+		{int(minUploadPartSize), 1, 0},
+		{int(minUploadPartSize)*2 + 10, 1, 0},
+		{int(minUploadPartSize)*2 + 11, 0, 3},
+		{int(minUploadPartSize)*3 + 10, 0, 4},
+	} {
+		fs.clearLog()
+		data := testUploadData(tt.size)
+		r := struct{ io.Reader }{bytes.NewReader(data)}
+		if _, _, err := client.Object.Upload(context.Background(), "hello.txt", r, opt); err != nil {
+			t.Fatalf("Object.Upload returned error: %v", err)
+		}
+		if puts, parts := fs.count("PutObject"), fs.count("UploadPart"); puts != tt.puts || parts != tt.parts || !bytes.Equal(fs.object("hello.txt"), data) {
+			t.Errorf("Object.Upload %d bytes: got %d puts, %d parts, object size %d, want %d puts, %d parts",
+				tt.size, puts, parts, len(fs.object("hello.txt")), tt.puts, tt.parts)
+		}
+	}
+}
+
+func TestObjectService_Upload_abort(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	fs.failPart(2)
+	data := testUploadData(int(minUploadPartSize)*3 + 10)
+
+	opt := &ObjectUploadOptions{
+		PartSize: minUploadPartSize,
+	}
+	_, _, err := client.Object.Upload(context.Background(), "hello.txt", bytes.NewReader(data), opt)
+	if err == nil {
+		t.Fatal("Object.Upload should return error")
+	}
+	if e, ok := err.(*ErrorResponse); !ok || e.Response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Object.Upload returned error %v, want 500 ErrorResponse", err)
+	}
+	if fs.count("AbortMultipartUpload") != 1 || fs.parts("upload-id") != nil {
+		t.Error("Object.Upload should abort the multipart upload")
+	}
+}
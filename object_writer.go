@@ -0,0 +1,85 @@
+package cos
+
+import (
+	"context"
+	"io"
+)
+
+// ObjectWriter 通过 Object.NewWriter 创建的流式上传 Object 的 io.WriteCloser。
+//
+// 写入的数据会按照分块大小缓存在可重复使用的 buffer 中，每填满一个分块就在后台通过 UploadPart 上传，
+// 调用 Close 后等待所有分块上传完成并合并分块。写入的数据总大小不超过 opt.Threshold 时会在 Close 时通过 Object.Put 上传。
+//
+// ObjectWriter 不能被多个 goroutine 并发调用。
+type ObjectWriter struct {
+	ctx  context.Context
+	pw   *io.PipeWriter
+	done chan struct{}
+
+	result *ObjectUploadResult
+	resp   *Response
+	err    error
+}
+
+// NewWriter 返回一个上传数据到 name 的 ObjectWriter，用于上传数据大小未知的数据（比如 gzip 压缩后的数据）。
+//
+// opt 的含义同 Object.Upload，其中 CheckpointFile 会被忽略。最多同时上传 opt.Concurrency 个分块，
+// 最多占用 (opt.Concurrency + 1) * opt.PartSize 的内存（opt.Threshold 较大时见 Object.Upload 的说明）。
+//
+// 上传出错后 Write 和 Close 都会返回该错误，并舍弃已上传的分块。
+// ctx 被取消或者调用了 CloseWithError 时会中断上传并舍弃已上传的分块，不会生成 Object。
+func (s *ObjectService) NewWriter(ctx context.Context, name string, opt *ObjectUploadOptions) *ObjectWriter {
+	pr, pw := io.Pipe()
+	w := &ObjectWriter{
+		ctx:  ctx,
+		pw:   pw,
+		done: make(chan struct{}),
+	}
+	u := newUploader(s, name, opt)
+	go func() {
+		defer close(w.done)
+		w.result, w.resp, w.err = u.uploadReader(ctx, pr)
+		// 上传结束后 Write 不再阻塞，上传出错时返回对应的错误
+		if w.err != nil {
+			pr.CloseWithError(w.err)
+		} else {
+			pr.Close()
+		}
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			pw.CloseWithError(ctx.Err())
+		case <-w.done:
+		}
+	}()
+	return w
+}
+
+// Write implements the io.Writer interface.
+//
+// 当前分块填满后，Write 会阻塞直到有空闲的 goroutine 可以上传该分块。
+func (w *ObjectWriter) Write(p []byte) (int, error) {
+	n, err := w.pw.Write(p)
+	if err == io.ErrClosedPipe && w.ctx.Err() != nil {
+		err = w.ctx.Err()
+	}
+	return n, err
+}
+
+// Close 结束写入，等待上传完成并返回上传过程中出现的错误
+func (w *ObjectWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError 结束写入并等待上传结束。err 不为 nil 时会中断上传并舍弃已上传的分块，返回 err
+func (w *ObjectWriter) CloseWithError(err error) error {
+	w.pw.CloseWithError(err)
+	<-w.done
+	return w.err
+}
+
+// Result 返回上传的结果以及 Object.Put 或 CompleteMultipartUpload 的响应，只在 Close 成功返回后有效
+func (w *ObjectWriter) Result() (*ObjectUploadResult, *Response) {
+	return w.result, w.resp
+}
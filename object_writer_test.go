@@ -0,0 +1,123 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestObjectService_NewWriter_put(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+
+	w := client.Object.NewWriter(context.Background(), "hello.txt", nil)
+	w.Write([]byte("hello "))
+	w.Write([]byte("world"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("ObjectWriter.Close returned error: %v", err)
+	}
+	puts, initiated := fs.count("PutObject"), fs.count("InitiateMultipartUpload")
+	if puts != 1 || initiated != 0 || string(fs.object("hello.txt")) != "hello world" {
+		t.Errorf("ObjectWriter should use Object.Put, got %d puts, body %q", puts, fs.object("hello.txt"))
+	}
+	if res, resp := w.Result(); res.ETag != fakeETag([]byte("hello world")) || resp == nil {
+		t.Errorf("ObjectWriter.Result returned %+v, %v", res, resp)
+	}
+}
+
+func TestObjectService_NewWriter_multipart(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	data := testUploadData(int(minUploadPartSize)*3 + 10)
+
+	opt := &ObjectUploadOptions{
+		PartSize:    minUploadPartSize,
+		Concurrency: 2,
+	}
+	w := client.Object.NewWriter(context.Background(), "hello.txt", opt)
+	// 每次写入的数据大小和分块大小无关
+	for b := data; len(b) > 0; {
+		n := 100 * 1000
+		if n > len(b) {
+			n = len(b)
+		}
+		if _, err := w.Write(b[:n]); err != nil {
+			t.Fatalf("ObjectWriter.Write returned error: %v", err)
+		}
+		b = b[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("ObjectWriter.Close returned error: %v", err)
+	}
+	if fs.count("PutObject") != 0 || fs.count("UploadPart") != 4 || !bytes.Equal(fs.object("hello.txt"), data) {
+		t.Errorf("ObjectWriter uploaded %d parts, object size %d, want 4 parts, size %d",
+			fs.count("UploadPart"), len(fs.object("hello.txt")), len(data))
+	}
+	if res, _ := w.Result(); res.UploadID != "upload-id" || len(res.Parts) != 4 {
+		t.Errorf("ObjectWriter.Result returned %+v", res)
+	}
+}
+
+func TestObjectService_NewWriter_uploadError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	fs.failPart(1)
+	data := testUploadData(int(minUploadPartSize)*3 + 10)
+
+	w := client.Object.NewWriter(context.Background(), "hello.txt", &ObjectUploadOptions{PartSize: minUploadPartSize})
+	w.Write(data)
+	err := w.Close()
+	if e, ok := err.(*ErrorResponse); !ok || e.Response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("ObjectWriter.Close returned error %v, want 500 ErrorResponse", err)
+	}
+	if _, werr := w.Write(data); werr == nil {
+		t.Error("ObjectWriter.Write should return error after the upload failed")
+	}
+	if fs.count("AbortMultipartUpload") != 1 || fs.object("hello.txt") != nil {
+		t.Error("ObjectWriter should abort the multipart upload")
+	}
+}
+
+func TestObjectService_NewWriter_abort(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fs := newFakeServer(t)
+	// 超过 Threshold 后会初始化分块上传，第一个分块还没有填满
+	data := testUploadData(int(minUploadPartSize) + 1)
+	opt := &ObjectUploadOptions{PartSize: minUploadPartSize}
+
+	w := client.Object.NewWriter(context.Background(), "hello.txt", opt)
+	w.Write(data)
+	abortErr := errors.New("abort")
+	if err := w.CloseWithError(abortErr); err != abortErr {
+		t.Errorf("ObjectWriter.CloseWithError returned error %v, want %v", err, abortErr)
+	}
+	if fs.count("InitiateMultipartUpload") != 1 || fs.count("AbortMultipartUpload") != 1 || fs.object("hello.txt") != nil {
+		t.Error("ObjectWriter should abort the multipart upload")
+	}
+
+	// 取消 ctx
+	fs.clearLog()
+	ctx, cancel := context.WithCancel(context.Background())
+	w = client.Object.NewWriter(ctx, "hello.txt", opt)
+	w.Write(data)
+	cancel()
+	if _, err := w.Write(data); err != context.Canceled {
+		t.Errorf("ObjectWriter.Write returned error %v, want %v", err, context.Canceled)
+	}
+	if err := w.Close(); err == nil {
+		t.Error("ObjectWriter.Close should return error")
+	}
+	if fs.count("PutObject") != 0 || fs.object("hello.txt") != nil {
+		t.Error("ObjectWriter should not create the object")
+	}
+}
@@ -0,0 +1,159 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy 定义了请求失败后是否需要重试以及重试前需要等待多长时间。
+//
+// DefaultSender 只会重试可以重新发送 body 的请求（没有 body 或 body 实现了 io.Seeker），
+// 每次重试都会重新经过 http.Client 的 Transport，所以使用 AuthorizationTransport 时会重新计算签名。
+type RetryPolicy interface {
+	// attempt 是已经发送请求的次数（从 1 开始），resp 和 err 是最后一次发送请求的结果。
+	// 返回 true 表示需要在等待 delay 后重试
+	ShouldRetry(ctx context.Context, caller Caller, req *http.Request, resp *http.Response, err error, attempt int) (delay time.Duration, retry bool)
+}
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+	defaultRetryMaxDelay    = 5 * time.Second
+)
+
+// DefaultRetryableCodes 默认需要重试的错误码（ErrorResponse.Code）
+var DefaultRetryableCodes = []string{
+	"SlowDown",
+	"RequestTimeout",
+	"InternalError",
+	"ServiceUnavailable",
+}
+
+// 可以安全重试的 POST 请求
+var idempotentPostMethods = map[MethodName]bool{
+	MethodObjectDeleteMulti:             true,
+	MethodObjectCompleteMultipartUpload: true,
+	MethodObjectPostRestore:             true,
+}
+
+// DefaultRetryPolicy 是默认的 RetryPolicy 实现，使用带随机抖动的指数退避算法计算重试前的等待时间。
+//
+// 以下情况会进行重试：
+//
+// * 网络错误（比如连接被重置、超时等）；
+// * 服务端返回 5xx 状态码；
+// * 服务端返回的错误码在 RetryableCodes 中。
+//
+// 为了避免重复执行非幂等的操作，只会重试 GET、HEAD、PUT、DELETE、OPTIONS 请求以及
+// Object.DeleteMulti、Object.CompleteMultipartUpload 和 Object.PostRestore 的 POST 请求（Object.Append 之类的请求不会被重试）。
+type DefaultRetryPolicy struct {
+	// 最多发送请求的次数（包括第一次请求），默认值：3
+	MaxAttempts int
+	// 第一次重试前的等待时间，之后每次重试的等待时间翻倍，默认值：100ms
+	BaseDelay time.Duration
+	// 重试前的最长等待时间，默认值：5s
+	MaxDelay time.Duration
+	// 需要重试的错误码，默认值：DefaultRetryableCodes
+	RetryableCodes []string
+}
+
+// ShouldRetry 实现 RetryPolicy 接口
+func (p *DefaultRetryPolicy) ShouldRetry(ctx context.Context, caller Caller, req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	if attempt >= maxAttempts || ctx.Err() != nil || !isIdempotentRequest(caller, req) {
+		return 0, false
+	}
+	if err == nil && !p.isRetryableResponse(resp) {
+		return 0, false
+	}
+
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = defaultRetryMaxDelay
+	}
+	return backoffDelay(base, max, attempt), true
+}
+
+func (p *DefaultRetryPolicy) isRetryableResponse(resp *http.Response) bool {
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return false
+	}
+	codes := p.RetryableCodes
+	if codes == nil {
+		codes = DefaultRetryableCodes
+	}
+	e := peekErrorResponse(resp)
+	for _, code := range codes {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdempotentRequest(caller Caller, req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return idempotentPostMethods[caller.Method]
+	}
+	return false
+}
+
+// peekErrorResponse 解析 resp 中的错误信息，并且保证 resp.Body 仍然可以被读取
+func peekErrorResponse(resp *http.Response) *ErrorResponse {
+	e := &ErrorResponse{Response: resp}
+	if resp.Body == nil {
+		return e
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	if err == nil {
+		xml.Unmarshal(data, e)
+	}
+	return e
+}
+
+// backoffDelay 计算第 attempt 次重试前的等待时间: min(max, base * 2^(attempt-1))，并增加 50% 以内的随机抖动
+func backoffDelay(base, max time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// sleepContext 等待 d 时间，ctx 被取消时提前返回 ctx.Err()
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
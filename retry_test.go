@@ -0,0 +1,257 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setRetryPolicy() {
+	client.Sender.(*DefaultSender).RetryPolicy = &DefaultRetryPolicy{
+		BaseDelay: time.Millisecond,
+	}
+}
+
+func TestDefaultSender_retry_5xx(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "hello")
+	})
+
+	resp, err := client.Object.Get(context.Background(), "hello.txt", nil)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	if attempts != 3 || string(b) != "hello" {
+		t.Errorf("Object.Get sent %d requests and returned %q, want 3 requests", attempts, b)
+	}
+}
+
+func TestDefaultSender_retry_max_attempts(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.Object.Head(context.Background(), "hello.txt", nil)
+	if e, ok := err.(*ErrorResponse); !ok || e.Response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Object.Head returned error %v, want 500 ErrorResponse", err)
+	}
+	if attempts != defaultRetryMaxAttempts {
+		t.Errorf("Object.Head sent %d requests, want %d", attempts, defaultRetryMaxAttempts)
+	}
+}
+
+func TestDefaultSender_retry_error_code(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		code := "RequestTimeout"
+		if attempts > 1 {
+			code = "InvalidArgument"
+		}
+		fmt.Fprintf(w, `<Error><Code>%s</Code></Error>`, code)
+	})
+
+	_, _, err := client.Bucket.Get(context.Background(), nil)
+	// 第二次请求返回的错误码不需要重试，并且 ResponseParser 仍然可以解析出错误信息
+	if e, ok := err.(*ErrorResponse); !ok || e.Code != "InvalidArgument" {
+		t.Errorf("Bucket.Get returned error %v, want InvalidArgument", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Bucket.Get sent %d requests, want 2", attempts)
+	}
+}
+
+func TestDefaultSender_retry_rewind_body(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	var bodies []string
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	for _, r := range []io.Reader{
+		strings.NewReader("hello"),
+		io.NewSectionReader(strings.NewReader("--hello--"), 2, 5),
+	} {
+		bodies = nil
+		opt := &ObjectPutOptions{
+			ObjectPutHeaderOptions: &ObjectPutHeaderOptions{ContentLength: 5},
+		}
+		_, err := client.Object.Put(context.Background(), "hello.txt", r, opt)
+		if err != nil {
+			t.Fatalf("Object.Put returned error: %v", err)
+		}
+		if fmt.Sprint(bodies) != "[hello hello]" {
+			t.Errorf("Object.Put sent bodies %q, want body hello twice", bodies)
+		}
+	}
+}
+
+func TestDefaultSender_retry_file_body(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	var bodies []string
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	f, err := ioutil.TempFile("", "cos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	f.WriteString("--hello")
+	f.Seek(2, io.SeekStart)
+
+	// 没有指定 Content-Length，body 为 *os.File
+	_, err = client.Object.Put(context.Background(), "hello.txt", f, nil)
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if fmt.Sprint(bodies) != "[hello hello]" {
+		t.Errorf("Object.Put sent bodies %q, want body hello twice", bodies)
+	}
+	// 文件不会被关闭
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Errorf("file should not be closed: %v", err)
+	}
+}
+
+func TestDefaultSender_retry_rewind_error(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	r := &failSeeker{ReadSeeker: strings.NewReader("hello")}
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{ContentLength: 5},
+	}
+	resp, err := client.Object.Put(context.Background(), "hello.txt", r, opt)
+	if e, ok := err.(*ErrorResponse); !ok || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Object.Put returned %v, want the 503 error of the last request", e)
+	}
+	if attempts != 1 {
+		t.Errorf("sent %d requests, want 1", attempts)
+	}
+}
+
+// failSeeker 只能获取当前的位置，无法移动到其他位置
+type failSeeker struct {
+	io.ReadSeeker
+}
+
+func (r *failSeeker) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekCurrent {
+		return r.ReadSeeker.Seek(offset, whence)
+	}
+	return 0, fmt.Errorf("seek is not supported")
+}
+
+func TestDefaultSender_retry_not_rewindable(t *testing.T) {
+	setup()
+	defer teardown()
+	setRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// 无法重新读取的 body
+	r := struct{ io.Reader }{bytes.NewReader([]byte("hello"))}
+	_, err := client.Object.Put(context.Background(), "hello.txt", r, nil)
+	if err == nil {
+		t.Fatal("Object.Put should return error")
+	}
+	// 非幂等的请求
+	_, err = client.Object.Append(context.Background(), "hello.txt", 0, strings.NewReader("hello"), nil)
+	if err == nil {
+		t.Fatal("Object.Append should return error")
+	}
+	if attempts != 2 {
+		t.Errorf("sent %d requests, want 2", attempts)
+	}
+}
+
+func TestDefaultSender_retry_context_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Sender.(*DefaultSender).RetryPolicy = &DefaultRetryPolicy{
+		BaseDelay: time.Hour,
+		MaxDelay:  time.Hour,
+	}
+
+	mux.HandleFunc("/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Object.Head(ctx, "hello.txt", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Object.Head returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func Test_backoffDelay(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+	for attempt, want := range []time.Duration{0, 100, 200, 400, 800, 1000, 1000} {
+		if attempt == 0 {
+			continue
+		}
+		want *= time.Millisecond
+		got := backoffDelay(base, max, attempt)
+		if got < want/2 || got > want {
+			t.Errorf("backoffDelay(%d) is %v, want [%v, %v]", attempt, got, want/2, want)
+		}
+	}
+}